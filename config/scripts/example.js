@@ -0,0 +1,44 @@
+// Share Sniffer 脚本检查器示例
+// 复制到用户配置目录的 share-sniffer/scripts/（或环境变量 SHARE_SNIFFER_SCRIPTS 指定的目录）后生效
+
+var checker = {
+  name: "example",
+  prefixes: ["https://pan.example.com/s/"],
+  // 可选，提取分享ID的Go正则，用于结果缓存和重复检测
+  pattern: "^https://pan\\.example\\.com/s/(?P<id>[A-Za-z0-9_-]+)",
+  // 为 true 时替换内置的同前缀检查器
+  override: false,
+
+  check: function (url) {
+    var m = url.match(/\/s\/([A-Za-z0-9_-]+)/);
+    if (!m) {
+      return result.malformed("链接格式无效");
+    }
+
+    var resp = http.post("https://api.example.com/share/info", {
+      share_id: m[1],
+      passcode: password(url)
+    }, {headers: {"Referer": "https://pan.example.com/"}});
+    if (resp.status === 404) {
+      return result.invalid("分享不存在", "share_not_found");
+    }
+    if (resp.status !== 200) {
+      return result.fatal("状态码" + resp.status, "upstream_status");
+    }
+
+    var data = resp.json();
+    switch (data.code) {
+      case 0:
+        return result.valid(data.data.title, {
+          file_count: data.data.file_count,
+          total_size: data.data.total_size,
+          creator: json.select(data, "$.data.owner.nickname")
+        });
+      case 41008:
+        return result.needPassword(data.message, "wrong_passcode");
+      default:
+        // 按提示信息判断失效、违规、需要登录等状态
+        return result.fromMessage(data.message, "");
+    }
+  }
+};
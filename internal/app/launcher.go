@@ -1,7 +1,10 @@
 package app
 
 import (
+	"share-sniffer/internal/browser"
+	"share-sniffer/internal/cache"
 	"share-sniffer/internal/config"
+	"share-sniffer/internal/core"
 	"share-sniffer/internal/logger"
 )
 
@@ -21,8 +24,53 @@ func Launcher() {
 	// 记录应用启动信息，包括版本号
 	logger.Info("应用启动,名称: %s , 版本: %s", cfg.AppInfo.AppName, cfg.AppInfo.Version)
 
+	// 注册规则文件中声明的检查器
+	if count, err := core.RegisterRules(config.GetRulesPath()); err != nil {
+		logger.Warn("加载规则文件失败: %v", err)
+	} else if count > 0 {
+		logger.Info("已加载 %d 条检查器规则", count)
+	}
+
+	// 注册脚本目录中的检查器
+	if count, err := core.RegisterScripts(config.GetScriptsDir()); err != nil {
+		logger.Warn("加载脚本失败: %v", err)
+	} else if count > 0 {
+		logger.Info("已加载 %d 个脚本检查器", count)
+	}
+
+	// 注册外部检查器插件
+	if count, err := core.RegisterPlugins(config.GetPluginsPath()); err != nil {
+		logger.Warn("加载插件失败: %v", err)
+	} else if count > 0 {
+		logger.Info("已加载 %d 个检查器插件", count)
+	}
+
+	// 启用结果缓存，并在后台清理过期记录
+	// GUI运行期间一直持有缓存文件，缓存文件被其他进程占用时改为每次读写打开
+	var resultCache *cache.Cache
+	if config.CacheEnabled() {
+		var err error
+		if resultCache, err = cache.Open(config.GetCachePath()); err != nil {
+			logger.Warn("打开缓存文件失败，改为每次读写时打开: %v", err)
+			resultCache = cache.New(config.GetCachePath())
+		}
+		core.SetCache(resultCache)
+		go func() {
+			if err := resultCache.Purge(); err != nil {
+				logger.Warn("清理过期缓存失败: %v", err)
+			}
+		}()
+	}
+
 	// 启动应用 - 创建并运行ShareSniffer应用实例
 	app := NewShareSnifferApp()
 
 	app.Run()
+
+	// 应用退出后关闭常驻的插件进程、浏览器池和缓存文件
+	core.ClosePlugins()
+	browser.Shutdown()
+	if resultCache != nil {
+		resultCache.Close()
+	}
 }
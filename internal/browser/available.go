@@ -0,0 +1,122 @@
+// Package browser Copyright 2025 Share Sniffer
+//
+// available.go 检查运行环境中是否有可用的浏览器
+// 配置了远程浏览器时探测其DevTools接口，否则在常见位置查找本地Chrome
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/logger"
+)
+
+// probeTimeout 探测远程浏览器的超时时间
+const probeTimeout = 2 * time.Second
+
+var (
+	available     bool
+	availableOnce sync.Once
+)
+
+// Available 是否有可用的浏览器，结果在第一次调用后缓存
+// 没有可用浏览器时，依赖浏览器的检查器不会被注册
+func Available() bool {
+	availableOnce.Do(func() {
+		cfg := config.GetBrowserPool()
+		if cfg.RemoteURL != "" {
+			available = probeRemote(cfg.RemoteURL) == nil
+			return
+		}
+		available = findChrome() != ""
+	})
+	return available
+}
+
+// probeRemote 请求远程浏览器的 /json/version 接口，确认浏览器可以连接
+func probeRemote(remoteURL string) error {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+	u.Path = "/json/version"
+	u.RawQuery = ""
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		logger.Debug("Browser:无法连接远程浏览器%s,%v", remoteURL, err)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		logger.Debug("Browser:远程浏览器%s返回状态码%d", remoteURL, resp.StatusCode)
+		return fmt.Errorf("远程浏览器返回状态码%d", resp.StatusCode)
+	}
+	return nil
+}
+
+// findChrome 查找本地Chrome，查找顺序与 chromedp 启动浏览器时一致
+func findChrome() string {
+	var locations []string
+	switch runtime.GOOS {
+	case "android":
+		return ""
+	case "darwin":
+		locations = []string{
+			"/Applications/Chromium.app/Contents/MacOS/Chromium",
+			"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+		}
+	case "windows":
+		locations = []string{
+			"chrome",
+			"chrome.exe",
+			`C:\Program Files (x86)\Google\Chrome\Application\chrome.exe`,
+			`C:\Program Files\Google\Chrome\Application\chrome.exe`,
+			filepath.Join(os.Getenv("USERPROFILE"), `AppData\Local\Google\Chrome\Application\chrome.exe`),
+			filepath.Join(os.Getenv("USERPROFILE"), `AppData\Local\Chromium\Application\chrome.exe`),
+		}
+	default:
+		locations = []string{
+			"headless_shell",
+			"headless-shell",
+			"chromium",
+			"chromium-browser",
+			"google-chrome",
+			"google-chrome-stable",
+			"google-chrome-beta",
+			"google-chrome-unstable",
+			"/usr/bin/google-chrome",
+			"/usr/local/bin/chrome",
+			"/snap/bin/chromium",
+			"chrome",
+		}
+	}
+
+	for _, path := range locations {
+		if found, err := exec.LookPath(path); err == nil {
+			return found
+		}
+	}
+	return ""
+}
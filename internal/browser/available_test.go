@@ -0,0 +1,52 @@
+package browser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"share-sniffer/internal/config"
+)
+
+func TestProbeRemote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/json/version" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"Browser":"HeadlessChrome/143.0.0.0","webSocketDebuggerUrl":"ws://127.0.0.1:1/devtools/browser/x"}`))
+	}))
+	defer server.Close()
+	wsURL := "ws://" + strings.TrimPrefix(server.URL, "http://")
+
+	for _, remoteURL := range []string{wsURL + "/", server.URL, wsURL + "/devtools/browser/abc"} {
+		if err := probeRemote(remoteURL); err != nil {
+			t.Errorf("probeRemote(%q) error = %v", remoteURL, err)
+		}
+	}
+
+	server.Close()
+	if err := probeRemote(wsURL + "/"); err == nil {
+		t.Errorf("probeRemote() 预期连接失败")
+	}
+}
+
+func TestPoolRemoteError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	pool := NewPool(config.BrowserPool{Size: 1, Tabs: 1, RemoteURL: "ws://" + strings.TrimPrefix(server.URL, "http://") + "/"})
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, _, err := pool.NewTab(ctx); err == nil || ctx.Err() != nil {
+		t.Errorf("NewTab() error = %v, want connect error", err)
+	}
+	if browsers, tabs := pool.Stat(); browsers != 0 || tabs != 0 {
+		t.Errorf("Stat() = %d, %d, want 0, 0", browsers, tabs)
+	}
+}
@@ -0,0 +1,379 @@
+// Package browser Copyright 2025 Share Sniffer
+//
+// pool.go 实现了共享的无头浏览器池
+// 浏览器进程只启动一次，每次检测在独立的浏览器上下文（相当于无痕窗口）中打开新标签页，
+// 检测之间不共享Cookie和缓存。崩溃的浏览器会被丢弃，处理次数达到上限的浏览器在标签页全部关闭后重启
+package browser
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/logger"
+)
+
+// ErrPoolClosed 浏览器池已关闭
+var ErrPoolClosed = stderrors.New("浏览器池已关闭")
+
+// userAgent 浏览器使用的用户代理，远程浏览器无法通过启动参数设置，在每个标签页上单独设置
+const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/143.0.0.0 Safari/537.36"
+
+// DefaultOptions 启动浏览器的默认参数
+var DefaultOptions = append(chromedp.DefaultExecAllocatorOptions[:],
+	// 基本配置
+	chromedp.Flag("headless", true),
+	chromedp.Flag("disable-gpu", true),
+	chromedp.Flag("no-sandbox", true),
+	chromedp.Flag("disable-dev-shm-usage", true),
+
+	// 更新用户代理为现代Chrome版本
+	chromedp.UserAgent(userAgent),
+
+	// 核心性能优化：禁用不必要的资源加载
+	chromedp.Flag("blink-settings", "imagesEnabled=false,cssEnabled=false"),
+	chromedp.Flag("disable-plugins", true),
+	chromedp.Flag("disable-extensions", true),
+	chromedp.Flag("disable-component-extensions-with-background-pages", true),
+	chromedp.Flag("disable-preconnect", true),
+	chromedp.Flag("disable-prefetch", true),
+	chromedp.Flag("disable-predictive-networking", true),
+	chromedp.Flag("disable-ntp-other-sessions-suggestions", true),
+	chromedp.Flag("disable-background-networking", true),
+	chromedp.Flag("disable-background-timer-throttling", true),
+	chromedp.Flag("disable-backgrounding-occluded-windows", true),
+
+	// 禁用媒体自动播放
+	chromedp.Flag("autoplay-policy", "user-gesture-required"),
+	chromedp.Flag("disable-media-autoplay", true),
+
+	// JavaScript和渲染优化
+	chromedp.Flag("disable-javascript-timeouts", true),
+	chromedp.Flag("reduced-refresh-rate", true),
+	chromedp.Flag("disable-translate", true),
+
+	// 安全和隐私设置
+	chromedp.Flag("disable-blink-features", "AutomationControlled"),
+	chromedp.Flag("disable-web-security", true),
+	chromedp.Flag("allow-running-insecure-content", true),
+
+	// 网络限制和缓存控制
+	chromedp.Flag("disk-cache-size", "0"),
+	chromedp.Flag("media-cache-size", "0"),
+
+	// 窗口和渲染设置
+	chromedp.Flag("window-size", "1280,800"),
+	chromedp.Flag("enable-features", "NetworkService,NetworkServiceInProcess"),
+)
+
+// instance 池中的一个浏览器进程
+type instance struct {
+	id      int
+	ctx     context.Context // 浏览器的根上下文，浏览器断开连接时被取消
+	cancel  context.CancelFunc
+	active  int  // 正在使用的标签页数
+	uses    int  // 已打开的标签页总数
+	retired bool // 不再分配新标签页，标签页全部关闭后退出
+}
+
+// alive 浏览器是否仍在运行
+func (b *instance) alive() bool {
+	return b.ctx.Err() == nil
+}
+
+// Pool 无头浏览器池
+// 同时打开的标签页总数不超过 Size*Tabs，超出时 NewTab 等待空闲的标签页
+type Pool struct {
+	cfg  config.BrowserPool
+	opts []chromedp.ExecAllocatorOption
+
+	slots chan struct{}
+
+	mu        sync.Mutex
+	browsers  []*instance
+	nextID    int
+	closed    bool
+	launching int           // 正在启动的浏览器数，启动时不持有锁
+	launched  chan struct{} // 每次启动结束时关闭并替换，用于等待正在启动的浏览器
+}
+
+// NewPool 创建浏览器池，浏览器在第一次使用时才启动
+//
+// 参数:
+// - cfg: 浏览器池参数，小于1的值按1处理，MaxUses<=0 表示不重启；设置 RemoteURL 时连接远程浏览器
+// - opts: 启动本地浏览器的参数，为空时使用 DefaultOptions
+func NewPool(cfg config.BrowserPool, opts ...chromedp.ExecAllocatorOption) *Pool {
+	cfg.Size = max(cfg.Size, 1)
+	cfg.Tabs = max(cfg.Tabs, 1)
+	if len(opts) == 0 {
+		opts = DefaultOptions
+	}
+	return &Pool{
+		cfg:      cfg,
+		opts:     opts,
+		slots:    make(chan struct{}, cfg.Size*cfg.Tabs),
+		launched: make(chan struct{}),
+	}
+}
+
+// NewTab 在池中的浏览器上打开一个无痕标签页
+// 标签页在ctx结束或调用release时关闭，release必须调用且只能调用一次
+//
+// 返回值:
+// - context.Context: 标签页上下文，传给 chromedp.Run 使用
+// - func(): 释放标签页
+// - error: ctx结束、浏览器池已关闭或浏览器启动失败时返回错误
+func (p *Pool) NewTab(ctx context.Context) (context.Context, func(), error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+
+	b, err := p.acquire(ctx)
+	if err != nil {
+		<-p.slots
+		return nil, nil, err
+	}
+
+	tabCtx, tabCancel := chromedp.NewContext(b.ctx, chromedp.WithNewBrowserContext())
+	stop := context.AfterFunc(ctx, tabCancel)
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			stop()
+			tabCancel()
+			p.release(b)
+			<-p.slots
+		})
+	}
+
+	// 立即创建标签页，使标签页的生命周期与tabCtx一致，而不是与第一次Run传入的上下文一致
+	var actions []chromedp.Action
+	if p.cfg.RemoteURL != "" {
+		actions = append(actions, emulation.SetUserAgentOverride(userAgent))
+	}
+	if err := chromedp.Run(tabCtx, actions...); err != nil {
+		release()
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		return nil, nil, err
+	}
+	return tabCtx, release, nil
+}
+
+// acquire 选择标签页最少的浏览器，没有可用的浏览器时启动新的浏览器
+// 启动浏览器时不持有锁，避免阻塞其他标签页的分配和归还；启动数已达上限时等待正在启动的浏览器
+func (p *Pool) acquire(ctx context.Context) (*instance, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		if p.closed {
+			return nil, ErrPoolClosed
+		}
+
+		best, running := p.pick()
+		canLaunch := running+p.launching < p.cfg.Size
+		if best != nil && (best.active == 0 || !canLaunch) {
+			p.use(best)
+			return best, nil
+		}
+		if best == nil && !canLaunch && p.launching > 0 {
+			// 等待正在启动的浏览器，启动结束后重新选择
+			launched := p.launched
+			p.mu.Unlock()
+			select {
+			case <-launched:
+				p.mu.Lock()
+				continue
+			case <-ctx.Done():
+				p.mu.Lock()
+				return nil, ctx.Err()
+			}
+		}
+
+		p.launching++
+		p.mu.Unlock()
+		b, err := p.launch(ctx)
+		p.mu.Lock()
+		p.launching--
+		close(p.launched)
+		p.launched = make(chan struct{})
+
+		switch {
+		case err != nil:
+			// 启动失败时使用已有的浏览器
+			if best, _ = p.pick(); best == nil {
+				return nil, err
+			}
+			logger.Warn("BrowserPool:启动浏览器失败,%v", err)
+		case p.closed:
+			b.cancel()
+			return nil, ErrPoolClosed
+		default:
+			p.nextID++
+			b.id = p.nextID
+			p.browsers = append(p.browsers, b)
+			logger.Debug("BrowserPool:已启动浏览器%d，当前%d个", b.id, len(p.browsers))
+			best = b
+		}
+		p.use(best)
+		return best, nil
+	}
+}
+
+// pick 返回标签页最少且未满的浏览器和运行中的浏览器数，调用方需持有锁
+// 已退出的浏览器标记为退役，没有标签页的退役浏览器被关闭
+func (p *Pool) pick() (*instance, int) {
+	var best *instance
+	running := 0
+	for _, b := range p.browsers {
+		if !b.alive() {
+			// 浏览器崩溃或断开连接，不再分配标签页
+			if !b.retired {
+				logger.Warn("BrowserPool:浏览器%d已退出，将重新启动", b.id)
+				b.retired = true
+			}
+			continue
+		}
+		if b.retired {
+			continue
+		}
+		running++
+		if b.active < p.cfg.Tabs && (best == nil || b.active < best.active) {
+			best = b
+		}
+	}
+	p.removeIdle()
+	return best, running
+}
+
+// use 在浏览器上分配一个标签页，处理次数达到上限的浏览器退役，调用方需持有锁
+func (p *Pool) use(b *instance) {
+	b.active++
+	b.uses++
+	if p.cfg.MaxUses > 0 && b.uses >= p.cfg.MaxUses {
+		logger.Debug("BrowserPool:浏览器%d已处理%d次检测，标签页关闭后重启", b.id, b.uses)
+		b.retired = true
+	}
+}
+
+// launch 启动一个浏览器进程，由调用方在持有锁时加入池中
+// 使用远程浏览器时建立一个新的DevTools连接，远程浏览器本身不会被关闭
+func (p *Pool) launch(ctx context.Context) (*instance, error) {
+	var allocCtx context.Context
+	var allocCancel context.CancelFunc
+	if p.cfg.RemoteURL != "" {
+		allocCtx, allocCancel = chromedp.NewRemoteAllocator(context.Background(), p.cfg.RemoteURL)
+	} else {
+		allocCtx, allocCancel = chromedp.NewExecAllocator(context.Background(), p.opts...)
+	}
+	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
+	cancel := func() {
+		browserCancel()
+		allocCancel()
+	}
+
+	// 第一次Run时启动浏览器，调用方的ctx结束时放弃启动
+	stop := context.AfterFunc(ctx, cancel)
+	err := chromedp.Run(browserCtx)
+	stop()
+	if err != nil {
+		cancel()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return &instance{ctx: browserCtx, cancel: cancel}, nil
+}
+
+// release 归还标签页，退役的浏览器在标签页全部关闭后退出
+func (p *Pool) release(b *instance) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b.active--
+	if !b.alive() {
+		b.retired = true
+	}
+	p.removeIdle()
+}
+
+// removeIdle 关闭没有标签页的退役浏览器，调用方需持有锁
+func (p *Pool) removeIdle() {
+	kept := p.browsers[:0]
+	for _, b := range p.browsers {
+		if b.retired && b.active == 0 {
+			b.cancel()
+			logger.Debug("BrowserPool:已关闭浏览器%d", b.id)
+			continue
+		}
+		kept = append(kept, b)
+	}
+	clear(p.browsers[len(kept):])
+	p.browsers = kept
+}
+
+// Stat 返回运行中的浏览器数和正在使用的标签页数
+func (p *Pool) Stat() (browsers, tabs int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, b := range p.browsers {
+		if b.alive() {
+			browsers++
+		}
+		tabs += b.active
+	}
+	return browsers, tabs
+}
+
+// Close 关闭所有浏览器，正在进行的检测会被取消
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	for _, b := range p.browsers {
+		b.cancel()
+	}
+	p.browsers = nil
+}
+
+var (
+	defaultPool *Pool
+	defaultMu   sync.Mutex
+)
+
+// Default 返回按配置创建的全局浏览器池
+func Default() *Pool {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	if defaultPool == nil {
+		defaultPool = NewPool(config.GetBrowserPool())
+	}
+	return defaultPool
+}
+
+// NewTab 在全局浏览器池中打开一个无痕标签页，用法同 Pool.NewTab
+func NewTab(ctx context.Context) (context.Context, func(), error) {
+	return Default().NewTab(ctx)
+}
+
+// Shutdown 关闭全局浏览器池，程序退出前调用；没有使用过浏览器时不做任何事
+func Shutdown() {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	if defaultPool != nil {
+		defaultPool.Close()
+		defaultPool = nil
+	}
+}
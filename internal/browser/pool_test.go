@@ -0,0 +1,136 @@
+package browser
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"share-sniffer/internal/config"
+)
+
+func TestPoolLaunchError(t *testing.T) {
+	pool := NewPool(config.BrowserPool{Size: 1, Tabs: 1}, chromedp.ExecPath("/nonexistent/chrome"))
+	defer pool.Close()
+
+	// 启动失败时归还标签页名额，后续调用不会阻塞
+	for range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, _, err := pool.NewTab(ctx)
+		cancel()
+		if err == nil || stderrors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("NewTab() error = %v, want launch error", err)
+		}
+	}
+	if browsers, tabs := pool.Stat(); browsers != 0 || tabs != 0 {
+		t.Errorf("Stat() = %d, %d, want 0, 0", browsers, tabs)
+	}
+
+	pool.Close()
+	if _, _, err := pool.NewTab(context.Background()); !stderrors.Is(err, ErrPoolClosed) {
+		t.Errorf("NewTab() after Close error = %v, want ErrPoolClosed", err)
+	}
+}
+
+func TestPoolTabs(t *testing.T) {
+	if findChrome() == "" {
+		t.Skip("未安装Chrome")
+	}
+
+	pool := NewPool(config.BrowserPool{Size: 1, Tabs: 2, MaxUses: 3})
+	defer pool.Close()
+	ctx := context.Background()
+
+	tab1, release1, err := pool.NewTab(ctx)
+	if err != nil {
+		t.Fatalf("NewTab() error = %v", err)
+	}
+	_, release2, err := pool.NewTab(ctx)
+	if err != nil {
+		t.Fatalf("NewTab() error = %v", err)
+	}
+	if browsers, tabs := pool.Stat(); browsers != 1 || tabs != 2 {
+		t.Errorf("Stat() = %d, %d, want 1, 2", browsers, tabs)
+	}
+
+	// 标签页已满时等待
+	waitCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if _, _, err := pool.NewTab(waitCtx); !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("NewTab() on full pool error = %v, want deadline exceeded", err)
+	}
+
+	var title string
+	if err := chromedp.Run(tab1, chromedp.Navigate("about:blank"), chromedp.Title(&title)); err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+	release1()
+	release2()
+
+	// 第三次使用后浏览器退役，标签页关闭后重启
+	_, release3, err := pool.NewTab(ctx)
+	if err != nil {
+		t.Fatalf("NewTab() error = %v", err)
+	}
+	release3()
+	if browsers, tabs := pool.Stat(); browsers != 0 || tabs != 0 {
+		t.Errorf("Stat() after retire = %d, %d, want 0, 0", browsers, tabs)
+	}
+}
+
+func TestPoolSlowLaunch(t *testing.T) {
+	started := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-unblock
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	pool := NewPool(config.BrowserPool{Size: 2, Tabs: 1, RemoteURL: "ws://" + strings.TrimPrefix(server.URL, "http://") + "/"})
+	defer pool.Close()
+
+	// 已有的浏览器标签页已满，下一次分配需要启动新的浏览器
+	busyCtx, busyCancel := context.WithCancel(context.Background())
+	busy := &instance{id: 1, ctx: busyCtx, cancel: busyCancel, active: 1, uses: 1}
+	pool.browsers = append(pool.browsers, busy)
+	pool.nextID = 1
+	pool.slots <- struct{}{}
+
+	errCh := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _, err := pool.NewTab(ctx)
+		errCh <- err
+	}()
+	<-started
+
+	// 浏览器启动期间归还和统计标签页不被阻塞
+	released := make(chan struct{})
+	go func() {
+		pool.release(busy)
+		pool.Stat()
+		close(released)
+	}()
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("release() blocked by a slow browser launch")
+	}
+
+	busyCancel()
+	unblock <- struct{}{}
+	if err := <-errCh; err == nil {
+		t.Errorf("NewTab() error = nil, want connect error")
+	}
+}
@@ -0,0 +1,208 @@
+// Package cache Copyright 2025 Share Sniffer
+//
+// cache.go 实现了基于bbolt的检测结果缓存
+// Open 打开的缓存在关闭前一直持有缓存文件，适合GUI和批量检测等大量读写的场景；
+// New 创建的缓存在每次读写时打开并立即关闭，使多个短时运行的CLI进程可以共享同一个缓存文件
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"share-sniffer/internal/utils"
+)
+
+// lockTimeout 等待其他进程释放缓存文件锁的最长时间，超时视为未命中
+const lockTimeout = 500 * time.Millisecond
+
+// resultsBucket 存储检测结果的bucket名称
+var resultsBucket = []byte("results")
+
+// Cache 检测结果缓存
+type Cache struct {
+	path string
+	mu   sync.RWMutex // 常驻连接上的读写共享读锁；逐次打开缓存文件时持有写锁，同一进程内串行打开
+	db   *bolt.DB     // Open 打开的常驻连接，为nil时每次读写打开缓存文件
+}
+
+// entry 缓存中存储的记录
+type entry struct {
+	ExpiresAt int64        `json:"expires_at"` // 过期时间（毫秒时间戳）
+	Result    utils.Result `json:"result"`
+}
+
+// New 创建使用指定文件的缓存，文件不存在时在首次写入时创建
+// 每次读写都会打开并关闭缓存文件，不妨碍其他进程使用同一个文件
+func New(path string) *Cache {
+	return &Cache{path: path}
+}
+
+// Open 打开缓存文件并一直持有到调用Close，文件不存在时创建
+// 持有期间其他进程无法读写该缓存文件
+//
+// 返回值:
+// - *Cache: 缓存
+// - error: 目录无法创建或缓存文件被其他进程持有超过等待时间时返回错误
+func Open(path string) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{path: path, db: db}, nil
+}
+
+// Close 关闭Open打开的缓存文件，之后的读写改为每次打开缓存文件；New创建的缓存不做任何事
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.db == nil {
+		return nil
+	}
+	err := c.db.Close()
+	c.db = nil
+	return err
+}
+
+// Key 根据网盘标识、分享ID和提取码生成缓存键，提取码只保存摘要
+//
+// 返回值:
+// - string: 缓存键，网盘标识或分享ID为空时返回空字符串，表示不可缓存
+func Key(provider, shareID, password string) string {
+	if provider == "" || shareID == "" {
+		return ""
+	}
+	key := provider + "/" + shareID
+	if password != "" {
+		sum := sha256.Sum256([]byte(password))
+		key += "/" + hex.EncodeToString(sum[:8])
+	}
+	return key
+}
+
+// Get 读取未过期的缓存结果
+//
+// 返回值:
+// - utils.Result: 缓存的检测结果
+// - bool: 是否命中
+func (c *Cache) Get(key string) (utils.Result, bool) {
+	if key == "" {
+		return utils.Result{}, false
+	}
+	if _, err := os.Stat(c.path); err != nil {
+		return utils.Result{}, false
+	}
+
+	var e entry
+	found := false
+	err := c.withDB(true, func(db *bolt.DB) error {
+		return db.View(func(tx *bolt.Tx) error {
+			b := tx.Bucket(resultsBucket)
+			if b == nil {
+				return nil
+			}
+			data := b.Get([]byte(key))
+			if data == nil {
+				return nil
+			}
+			if err := json.Unmarshal(data, &e); err != nil {
+				return err
+			}
+			found = true
+			return nil
+		})
+	})
+	if err != nil || !found || time.Now().UnixMilli() >= e.ExpiresAt {
+		return utils.Result{}, false
+	}
+	return e.Result, true
+}
+
+// Put 写入检测结果，ttl<=0 时不写入
+func (c *Cache) Put(key string, result utils.Result, ttl time.Duration) error {
+	if key == "" || ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(entry{ExpiresAt: time.Now().Add(ttl).UnixMilli(), Result: result})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+
+	return c.withDB(false, func(db *bolt.DB) error {
+		return db.Update(func(tx *bolt.Tx) error {
+			b, err := tx.CreateBucketIfNotExists(resultsBucket)
+			if err != nil {
+				return err
+			}
+			return b.Put([]byte(key), data)
+		})
+	})
+}
+
+// Purge 删除所有已过期的缓存记录
+func (c *Cache) Purge() error {
+	if _, err := os.Stat(c.path); err != nil {
+		return nil
+	}
+	now := time.Now().UnixMilli()
+
+	return c.withDB(false, func(db *bolt.DB) error {
+		return db.Update(func(tx *bolt.Tx) error {
+			b := tx.Bucket(resultsBucket)
+			if b == nil {
+				return nil
+			}
+			// 先收集再删除，遍历过程中删除会跳过记录
+			var expired [][]byte
+			b.ForEach(func(k, v []byte) error {
+				var e entry
+				if json.Unmarshal(v, &e) != nil || now >= e.ExpiresAt {
+					expired = append(expired, append([]byte(nil), k...))
+				}
+				return nil
+			})
+			for _, k := range expired {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// withDB 在常驻连接上执行操作，没有常驻连接时打开缓存文件执行操作后立即关闭
+// 常驻连接上的操作可以并发，由bbolt的事务保证一致性
+func (c *Cache) withDB(readOnly bool, fn func(db *bolt.DB) error) error {
+	c.mu.RLock()
+	if c.db != nil {
+		defer c.mu.RUnlock()
+		return fn(c.db)
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.db != nil {
+		return fn(c.db)
+	}
+
+	db, err := bolt.Open(c.path, 0o600, &bolt.Options{Timeout: lockTimeout, ReadOnly: readOnly})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return fn(db)
+}
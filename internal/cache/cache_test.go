@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"share-sniffer/internal/utils"
+)
+
+func TestCache(t *testing.T) {
+	testCache(t, New(filepath.Join(t.TempDir(), "cache.db")))
+
+	opened, err := Open(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	testCache(t, opened)
+	if err := opened.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func testCache(t *testing.T, c *Cache) {
+	t.Helper()
+	key := Key("quark", "0592e1dbe475", "abcd")
+
+	if _, ok := c.Get(key); ok {
+		t.Fatalf("Get() 空缓存不应命中")
+	}
+
+	if err := c.Put(key, utils.ErrorValid("资料"), time.Hour); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, ok := c.Get(key)
+	if !ok || got.Data.Name != "资料" {
+		t.Fatalf("Get() = %+v, %v, want cached result", got, ok)
+	}
+
+	if _, ok := c.Get(Key("quark", "0592e1dbe475", "")); ok {
+		t.Errorf("Get() 不同提取码不应命中")
+	}
+
+	expiredKey := Key("quark", "expired", "")
+	if err := c.Put(expiredKey, utils.ErrorInvalid("失效"), time.Millisecond); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.Get(expiredKey); ok {
+		t.Errorf("Get() 过期记录不应命中")
+	}
+	if err := c.Purge(); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if _, ok := c.Get(key); !ok {
+		t.Errorf("Purge() 不应删除未过期记录")
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("", "abc", "") != "" || Key("quark", "", "") != "" {
+		t.Errorf("Key() 缺少网盘或分享ID时应返回空字符串")
+	}
+	if Key("quark", "abc", "") == Key("quark", "abc", "pwd1") {
+		t.Errorf("Key() 提取码不同时应生成不同的键")
+	}
+}
+
+func TestOpenConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	c, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	// 常驻连接上的并发读写
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := Key("quark", strconv.Itoa(i), "")
+			if err := c.Put(key, utils.ErrorValid("资料"), time.Hour); err != nil {
+				t.Errorf("Put() error = %v", err)
+			}
+			if _, ok := c.Get(key); !ok {
+				t.Errorf("Get(%s) 未命中", key)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 关闭后改为逐次打开缓存文件，已写入的记录仍可读取
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, ok := c.Get(Key("quark", "7", "")); !ok {
+		t.Errorf("Get() after Close 未命中")
+	}
+	if _, ok := New(path).Get(Key("quark", "8", "")); !ok {
+		t.Errorf("New().Get() 未读取到Open写入的记录")
+	}
+}
@@ -4,16 +4,24 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"share-sniffer/internal/browser"
+	"share-sniffer/internal/cache"
 	"share-sniffer/internal/config"
 	"share-sniffer/internal/core"
 	"share-sniffer/internal/logger"
+	"share-sniffer/internal/utils"
+	"share-sniffer/internal/workerpool"
 )
 
 var (
+	// noCache 跳过缓存读取，检测结果仍会写入缓存
+	noCache bool
+
 	rootCmd = &cobra.Command{
 		Use:   "share-sniffer-cli [URL]",
 		Short: "Share Sniffer CLI - A tool to detect and analyze shared links",
@@ -27,13 +35,15 @@ var (
 			}
 
 			// 直接传入URL进行检测
+			// 除网盘链接外，也接受 thunder://、ed2k:// 等下载链接和磁力链接
 			url := args[0]
-			if !strings.Contains(url, "https") || len(url) <= 20 {
+			lower := strings.ToLower(url)
+			if !(strings.Contains(lower, "http") || strings.Contains(lower, "://") || strings.Contains(lower, "magnet:")) || len(url) <= 20 {
 				cmd.Help()
 				return
 			}
 
-			response := core.Adapter(context.Background(), url)
+			response := core.AdapterWithOptions(context.Background(), url, core.AdapterOptions{NoCache: noCache})
 
 			// 输出JSON结果
 			//jsonBytes, _ := json.MarshalIndent(response, "", "  ")
@@ -63,6 +73,74 @@ var (
 		},
 	}
 
+	// list 命令的参数
+	listDepth  int
+	listMax    int
+	listFormat string
+	listCmd    = &cobra.Command{
+		Use:   "list [URL]",
+		Short: "List the directory tree of a share",
+		Long:  `List the files inside a share as JSON or an indented text tree, with depth and entry limits.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			result := core.List(context.Background(), args[0], core.ListOptions{
+				MaxDepth:   listDepth,
+				MaxEntries: listMax,
+			})
+
+			// 文本格式只在列举成功时输出目录树，失败时仍输出JSON便于调用方识别错误
+			if listFormat == "text" && result.Error == 0 {
+				fmt.Println(core.FormatTree(result.Entries))
+				return
+			}
+			jsonBytes, _ := json.Marshal(result)
+			fmt.Println(string(jsonBytes))
+		},
+	}
+
+	// batch 命令的参数
+	batchNoCache bool
+	batchCmd     = &cobra.Command{
+		Use:   "batch [FILE]",
+		Short: "Check all share links in a file",
+		Long: `Check every non-empty line of FILE, or of stdin when FILE is "-" or omitted.
+Each line is checked like a single link, so share text, short links, download links and unsupported links all get a result.
+The same share written with different domains or password styles is checked once and the repeats are marked with duplicate_of.
+Results are printed as JSON lines in input order, each carrying the line number of its input as index.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var reader io.Reader = os.Stdin
+			if len(args) == 1 && args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				reader = file
+			}
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				return err
+			}
+
+			// 批量检测期间一直持有缓存文件，避免每次读写都重新打开；
+			// 缓存文件被其他进程占用时保留每次读写打开的缓存
+			if config.CacheEnabled() {
+				if resultCache, err := cache.Open(config.GetCachePath()); err == nil {
+					core.SetCache(resultCache)
+					defer resultCache.Close()
+				}
+			}
+
+			lines, indexes := batchLines(string(content))
+			for _, result := range checkBatch(lines, indexes, core.AdapterOptions{NoCache: batchNoCache}) {
+				jsonBytes, _ := json.Marshal(result)
+				fmt.Println(string(jsonBytes))
+			}
+			return nil
+		},
+	}
+
 	homeCmd = &cobra.Command{
 		Use:   "home",
 		Short: "Show project homepage",
@@ -75,9 +153,88 @@ var (
 
 // init 初始化命令行
 func init() {
+	rootCmd.Flags().BoolVar(&noCache, "no-cache", false, "Bypass the result cache and check the link again")
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(supportCmd)
 	rootCmd.AddCommand(homeCmd)
+
+	listCmd.Flags().IntVar(&listDepth, "depth", core.DefaultListDepth, "Maximum directory depth (top level is 1)")
+	listCmd.Flags().IntVar(&listMax, "max", core.DefaultListEntries, "Maximum number of entries")
+	listCmd.Flags().StringVar(&listFormat, "format", "json", "Output format: json or text")
+	rootCmd.AddCommand(listCmd)
+
+	batchCmd.Flags().BoolVar(&batchNoCache, "no-cache", false, "Bypass the result cache and check the links again")
+	rootCmd.AddCommand(batchCmd)
+}
+
+// batchLines 按行拆分批量检测的输入，跳过空行
+//
+// 返回值:
+// - []string: 去掉首尾空白的非空行
+// - []int: 每行在输入中的行号（从1开始）
+func batchLines(content string) ([]string, []int) {
+	var lines []string
+	var indexes []int
+	for i, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+			indexes = append(indexes, i+1)
+		}
+	}
+	return lines, indexes
+}
+
+// checkBatch 使用工作池检测多行输入，每行输出一个结果，重复的链接只检测一次
+// 每行原样交给 core.AdapterWithOptions，由它提取链接、展开短链接和解码下载链接
+//
+// 参数:
+// - lines: 待检测的行
+// - indexes: 每行在输入中的行号，写入结果的Index
+// - opts: 检测选项
+//
+// 返回值:
+// - []utils.Result: 与输入顺序一致的检测结果，重复链接的结果复制自第一次出现的链接并标记DuplicateOf
+func checkBatch(lines []string, indexes []int, opts core.AdapterOptions) []utils.Result {
+	results := make([]utils.Result, len(lines))
+	dupOf := core.FindDuplicates(lines)
+
+	pool := workerpool.NewWorkerPool()
+	pool.Start()
+	for i, line := range lines {
+		if dupOf[i] >= 0 {
+			continue
+		}
+		index := i
+		submitted := pool.Submit(workerpool.Task{
+			URL: line,
+			Func: func(ctx context.Context) interface{} {
+				results[index] = core.AdapterWithOptions(ctx, line, opts)
+				return nil
+			},
+		})
+		if !submitted {
+			results[index] = utils.ErrorFatal("任务提交失败")
+			results[index].Schema = utils.SchemaVersion
+			results[index].Data.URL = line
+		}
+	}
+	// 结果已直接写入results，只需排空结果通道
+	go pool.Wait()
+	for range pool.Results() {
+	}
+
+	for i, j := range dupOf {
+		if j < 0 {
+			continue
+		}
+		results[i] = results[j]
+		results[i].Data.URL = lines[i]
+		results[i].Data.DuplicateOf = indexes[j]
+	}
+	for i := range results {
+		results[i].Data.Index = indexes[i]
+	}
+	return results
 }
 
 // Execute 执行命令行
@@ -86,7 +243,30 @@ func Execute() {
 	// 这样CLI模式下不会输出任何多余日志，只返回JSON结果
 	logger.SetLogLevel(logger.LevelFatal + 1)
 
-	if err := rootCmd.Execute(); err != nil {
+	// 注册规则文件中声明的检查器，规则错误不影响内置检查器
+	if _, err := core.RegisterRules(config.GetRulesPath()); err != nil {
+		fmt.Fprintln(os.Stderr, "load rules:", err)
+	}
+
+	// 注册脚本目录中的检查器
+	if _, err := core.RegisterScripts(config.GetScriptsDir()); err != nil {
+		fmt.Fprintln(os.Stderr, "load scripts:", err)
+	}
+
+	// 注册外部检查器插件，退出前关闭常驻的插件进程
+	if _, err := core.RegisterPlugins(config.GetPluginsPath()); err != nil {
+		fmt.Fprintln(os.Stderr, "load plugins:", err)
+	}
+
+	// 启用结果缓存，多个CLI进程共享同一个缓存文件
+	if config.CacheEnabled() {
+		core.SetCache(cache.New(config.GetCachePath()))
+	}
+
+	err := rootCmd.Execute()
+	core.ClosePlugins()
+	browser.Shutdown()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
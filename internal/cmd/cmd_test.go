@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"share-sniffer/internal/core"
+	"share-sniffer/internal/utils"
+)
+
+func TestBatchLines(t *testing.T) {
+	lines, indexes := batchLines("https://pan.quark.cn/s/abc\r\n\n  \n链接：https://pan.baidu.com/s/1abc 提取码: ab12\n")
+	wantLines := []string{"https://pan.quark.cn/s/abc", "链接：https://pan.baidu.com/s/1abc 提取码: ab12"}
+	wantIndexes := []int{1, 4}
+	if !reflect.DeepEqual(lines, wantLines) || !reflect.DeepEqual(indexes, wantIndexes) {
+		t.Errorf("batchLines() = %q, %v, want %q, %v", lines, indexes, wantLines, wantIndexes)
+	}
+}
+
+func TestCheckBatch(t *testing.T) {
+	lines, indexes := batchLines("https://example.com/readme\n\n说明 https://example.com/readme\nhttps://example.com/readme。\n")
+	results := checkBatch(lines, indexes, core.AdapterOptions{NoCache: true})
+	if len(results) != 3 {
+		t.Fatalf("checkBatch() returned %d results, want 3", len(results))
+	}
+	// 不受支持的链接和带说明文字的行都输出结果，重复的行指向第一次出现的行号
+	tests := []struct {
+		index       int
+		duplicateOf int
+	}{{1, 0}, {3, 0}, {4, 1}}
+	for i, tt := range tests {
+		got := results[i]
+		if got.Reason != utils.ReasonUnsupported || got.Data.URL != lines[i] || got.Data.Index != tt.index || got.Data.DuplicateOf != tt.duplicateOf {
+			t.Errorf("checkBatch()[%d] = %s %q index=%d duplicate_of=%d, want index=%d duplicate_of=%d",
+				i, got.Reason, got.Data.URL, got.Data.Index, got.Data.DuplicateOf, tt.index, tt.duplicateOf)
+		}
+	}
+}
+
+func TestCheckBatchDownloadLinks(t *testing.T) {
+	content := "thunder://QUFodHRwOi8vZXhhbXBsZS5jb20vYS56aXBaWg==\n" +
+		"快车 flashget://W0ZMQVNIR0VUXWh0dHA6Ly9leGFtcGxlLmNvbS9iLnppcFtGTEFTSEdFVF0=&abc\n" +
+		"qqdl://aHR0cDovL2V4YW1wbGUuY29tL2Muemlw\n" +
+		"magnet:?xt=urn:btih:c9e15763f722f23e98a29decdfae341b98d53056&dn=demo\n" +
+		"ed2k://|file|a.iso|1024|A2B4C6D8E0F2A4B6C8D0E2F4A6B8C0D2|/\n"
+	lines, indexes := batchLines(content)
+	results := checkBatch(lines, indexes, core.AdapterOptions{NoCache: true})
+
+	// 下载工具链接解码后检测，磁力链接和电驴链接识别为非分享链接
+	tests := []struct {
+		reason   string
+		resolved string
+		provider string
+	}{
+		{utils.ReasonUnsupported, "http://example.com/a.zip", ""},
+		{utils.ReasonUnsupported, "http://example.com/b.zip", ""},
+		{utils.ReasonUnsupported, "http://example.com/c.zip", ""},
+		{utils.ReasonNotShareLink, "", "magnet"},
+		{utils.ReasonNotShareLink, "", "ed2k"},
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("checkBatch() returned %d results, want %d", len(results), len(tests))
+	}
+	for i, tt := range tests {
+		got := results[i]
+		if got.Reason != tt.reason || got.Data.ResolvedURL != tt.resolved || got.Data.Provider != tt.provider || got.Data.Index != i+1 {
+			t.Errorf("checkBatch(%q) = %s resolved=%q provider=%q index=%d, want %s %q %q %d",
+				lines[i], got.Reason, got.Data.ResolvedURL, got.Data.Provider, got.Data.Index, tt.reason, tt.resolved, tt.provider, i+1)
+		}
+	}
+}
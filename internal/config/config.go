@@ -2,12 +2,39 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
 	"share-sniffer/internal/utils"
 )
 
+// RateLimit 限速参数
+type RateLimit struct {
+	RPS    float64       // 每秒请求数，<=0 表示不限速
+	Burst  int           // 突发请求数
+	Jitter time.Duration // 每次请求前额外等待的随机时长上限
+}
+
+// CircuitBreaker 熔断参数
+type CircuitBreaker struct {
+	Window           time.Duration // 统计窗口，窗口结束后清零计数
+	MinRequests      int           // 窗口内至少有这么多次请求才判断是否熔断
+	FailureRatio     float64       // 窗口内失败比例达到该值时熔断
+	OpenDuration     time.Duration // 熔断后等待多久进入半开状态
+	HalfOpenRequests int           // 半开状态允许的试探请求数，全部成功后恢复
+}
+
+// BrowserPool 无头浏览器池参数
+type BrowserPool struct {
+	Size      int    // 浏览器进程数
+	Tabs      int    // 每个浏览器同时打开的标签页数
+	MaxUses   int    // 每个浏览器处理多少次检测后重启，避免页面泄漏的内存持续增长
+	RemoteURL string // 远程Chrome的DevTools地址，如 ws://127.0.0.1:9222/，设置后不再启动本地浏览器
+}
+
 // Config 应用配置结构
 type Config struct {
 	// HTTP客户端配置
@@ -25,8 +52,57 @@ type Config struct {
 		DefaultTimeout     time.Duration
 		RetryInterval      time.Duration
 		// 长耗时任务配置
-		LongTimeout       time.Duration
-		LongMaxConcurrent int
+		LongTimeout time.Duration
+	}
+
+	// 限速配置，按请求主机的主域名（如 baidu.com）分别限速
+	RateLimitConfig struct {
+		Enabled bool
+		Default RateLimit
+		// 按主域名覆盖默认值
+		Hosts map[string]RateLimit
+	}
+
+	// 熔断配置，按网盘分别统计，接口连续出错时暂停请求
+	CircuitBreakerConfig struct {
+		Enabled bool
+		CircuitBreaker
+	}
+
+	// 无头浏览器池配置，迅雷和139云盘共用同一组浏览器
+	BrowserPoolConfig BrowserPool
+
+	// 短链接展开配置，输入不是受支持的链接时先展开短链接和跳转链接再匹配检查器
+	ResolverConfig struct {
+		Enabled bool
+		MaxHops int
+		// 跟随HTTP跳转的短链接主机，其他主机不发送请求
+		ShortHosts []string
+		// 跳转链接的主机（去掉www.）及其携带目标地址的查询参数名，其他主机的链接不拆解
+		RedirectParams map[string]string
+	}
+
+	// 结果缓存配置
+	CacheConfig struct {
+		Enabled bool
+		Path    string
+		// 各状态的缓存时长，未列出或为0的状态不缓存
+		TTLs map[utils.ErrorType]time.Duration
+	}
+
+	// 声明式检查器规则文件，文件不存在时忽略
+	RulesConfig struct {
+		Path string
+	}
+
+	// 外部检查器插件列表文件，文件不存在时忽略
+	PluginsConfig struct {
+		Path string
+	}
+
+	// 脚本检查器目录，目录下的每个 .js 文件定义一个检查器，目录不存在时忽略
+	ScriptsConfig struct {
+		Dir string
 	}
 
 	// 应用信息
@@ -79,7 +155,68 @@ func (q *Config) initDefault() {
 	q.CheckConfig.RetryInterval = 1 * time.Second // 减少重试间隔
 	// 长耗时任务配置
 	q.CheckConfig.LongTimeout = 10 * time.Second // 长耗时检测需要更长时间
-	q.CheckConfig.LongMaxConcurrent = 2          // 限制长耗时任务并发数，避免资源消耗过高
+
+	// 限速默认配置，百度和115对频繁请求较敏感
+	q.RateLimitConfig.Enabled = true
+	q.RateLimitConfig.Default = RateLimit{RPS: 4, Burst: 4, Jitter: 200 * time.Millisecond}
+	q.RateLimitConfig.Hosts = map[string]RateLimit{
+		"baidu.com":       {RPS: 1, Burst: 2, Jitter: 500 * time.Millisecond},
+		"115cdn.com":      {RPS: 2, Burst: 2, Jitter: 300 * time.Millisecond},
+		"aliyundrive.com": {RPS: 2, Burst: 3, Jitter: 200 * time.Millisecond},
+	}
+
+	// 熔断默认配置，30秒内至少5次请求且60%失败时熔断30秒
+	q.CircuitBreakerConfig.Enabled = true
+	q.CircuitBreakerConfig.CircuitBreaker = CircuitBreaker{
+		Window:           30 * time.Second,
+		MinRequests:      5,
+		FailureRatio:     0.6,
+		OpenDuration:     30 * time.Second,
+		HalfOpenRequests: 1,
+	}
+
+	// 浏览器池默认配置，2个浏览器各4个标签页，每个浏览器处理50次检测后重启
+	q.BrowserPoolConfig = BrowserPool{Size: 2, Tabs: 4, MaxUses: 50}
+
+	// 短链接展开默认配置，最多跟随5次跳转
+	q.ResolverConfig.Enabled = true
+	q.ResolverConfig.MaxHops = 5
+	q.ResolverConfig.ShortHosts = []string{
+		"t.cn", "url.cn", "bit.ly", "dwz.cn", "suo.im", "tinyurl.com", "is.gd", "goo.gl", "ow.ly", "t.co", "w.url.cn",
+	}
+	q.ResolverConfig.RedirectParams = map[string]string{
+		"link.zhihu.com": "target",
+		"link.juejin.cn": "target",
+		"link.csdn.net":  "target",
+		"gitee.com":      "target",
+		"weibo.cn":       "u",
+		"c.pc.qq.com":    "pfurl",
+		"jianshu.com":    "url",
+		"douban.com":     "url",
+		"jump.bdimg.com": "url",
+		"oschina.net":    "url",
+		"link.ld246.com": "goto",
+	}
+
+	// 结果缓存默认配置，超时、请求失败等临时状态不缓存
+	q.CacheConfig.Enabled = true
+	q.CacheConfig.Path = defaultCachePath()
+	q.CacheConfig.TTLs = map[utils.ErrorType]time.Duration{
+		utils.Valid:         6 * time.Hour,
+		utils.Invalid:       7 * 24 * time.Hour,
+		utils.Banned:        7 * 24 * time.Hour,
+		utils.NeedPassword:  24 * time.Hour,
+		utils.LoginRequired: 24 * time.Hour,
+	}
+
+	// 规则文件默认位于用户配置目录下
+	q.RulesConfig.Path = defaultConfigPath("rules.toml")
+
+	// 插件列表文件默认位于用户配置目录下
+	q.PluginsConfig.Path = defaultConfigPath("plugins.toml")
+
+	// 脚本目录默认位于用户配置目录下
+	q.ScriptsConfig.Dir = defaultConfigPath("scripts")
 
 	// 应用信息默认配置
 	q.AppInfo.Version = "0.3.0"
@@ -102,6 +239,67 @@ func (q *Config) initDefault() {
 	p["uc"] = []string{"https://drive.uc.cn/s/"}
 	p["xunlei"] = []string{"https://pan.xunlei.com/s/"}
 	p["yd"] = []string{"https://yun.139.com/shareweb/"}
+	p["weiyun"] = []string{"https://share.weiyun.com/"}
+	p["pikpak"] = []string{"https://mypikpak.com/s/"}
+	p["onedrive"] = []string{"https://1drv.ms/", "https://onedrive.live.com/"}
+	p["gdrive"] = []string{
+		"https://drive.google.com/file/d/",
+		"https://drive.google.com/drive/",
+		"https://drive.google.com/open?",
+		"https://drive.google.com/uc?",
+		"https://docs.google.com/",
+	}
+	p["dropbox"] = []string{
+		"https://www.dropbox.com/s/",
+		"https://www.dropbox.com/sh/",
+		"https://www.dropbox.com/scl/",
+		"https://db.tt/",
+	}
+	// Mega 旧版链接形如 https://mega.nz/#!句柄!密钥，路径为根路径
+	p["mega"] = []string{
+		"https://mega.nz/",
+		"https://mega.co.nz/",
+	}
+	// 城通网盘有多个分享域名，且 url 后的数字各不相同，使用 *. 匹配所有子域名
+	p["ctfile"] = []string{
+		"https://*.ctfile.com/f/",
+		"https://*.ctfile.com/d/",
+		"https://*.ctfile.com/file/",
+		"https://*.ctfile.com/dir/",
+		"https://*.474b.com/f/",
+		"https://*.474b.com/d/",
+		"https://*.474b.com/file/",
+		"https://*.474b.com/dir/",
+		"https://*.545c.com/f/",
+		"https://*.545c.com/d/",
+		"https://*.545c.com/file/",
+		"https://*.545c.com/dir/",
+		"https://*.u062.com/f/",
+		"https://*.u062.com/d/",
+		"https://*.u062.com/file/",
+		"https://*.u062.com/dir/",
+	}
+	// 蓝奏云域名较多且支持用户自定义子域名，使用 *. 匹配所有子域名
+	p["lanzou"] = []string{
+		"https://*.lanzou.com/",
+		"https://*.lanzoux.com/",
+		"https://*.lanzoui.com/",
+		"https://*.lanzouw.com/",
+		"https://*.lanzoub.com/",
+		"https://*.lanzouf.com/",
+		"https://*.lanzouh.com/",
+		"https://*.lanzouj.com/",
+		"https://*.lanzouk.com/",
+		"https://*.lanzoul.com/",
+		"https://*.lanzouo.com/",
+		"https://*.lanzoup.com/",
+		"https://*.lanzouq.com/",
+		"https://*.lanzout.com/",
+		"https://*.lanzouu.com/",
+		"https://*.lanzouv.com/",
+		"https://*.lanzouy.com/",
+		"https://*.lanzn.com/",
+	}
 
 	q.refreshAllPrefixes()
 }
@@ -109,12 +307,7 @@ func (q *Config) initDefault() {
 // refreshAllPrefixes 刷新所有支持的前缀列表
 func (q *Config) refreshAllPrefixes() {
 	all := []string{}
-	desktopOnly := map[string]bool{"xunlei": true, "yd": true}
-
-	for name, prefixes := range q.SupportedLinkTypes.Providers {
-		if desktopOnly[name] && !utils.IsDesktop() {
-			continue
-		}
+	for _, prefixes := range q.SupportedLinkTypes.Providers {
 		all = append(all, prefixes...)
 	}
 	q.SupportedLinkTypes.AllPrefixes = all
@@ -127,6 +320,54 @@ func (q *Config) loadFromEnv() {
 		// 这里可以添加字符串到int的转换逻辑
 	}
 
+	// SHARE_SNIFFER_RATE_LIMIT=0 关闭限速
+	if rateLimit := os.Getenv("SHARE_SNIFFER_RATE_LIMIT"); rateLimit == "0" || rateLimit == "false" {
+		q.RateLimitConfig.Enabled = false
+	}
+
+	// SHARE_SNIFFER_CIRCUIT_BREAKER=0 关闭熔断
+	if breaker := os.Getenv("SHARE_SNIFFER_CIRCUIT_BREAKER"); breaker == "0" || breaker == "false" {
+		q.CircuitBreakerConfig.Enabled = false
+	}
+
+	// SHARE_SNIFFER_CACHE=0 关闭结果缓存，SHARE_SNIFFER_CACHE_PATH 指定缓存文件
+	if cache := os.Getenv("SHARE_SNIFFER_CACHE"); cache == "0" || cache == "false" {
+		q.CacheConfig.Enabled = false
+	}
+	if cachePath := os.Getenv("SHARE_SNIFFER_CACHE_PATH"); cachePath != "" {
+		q.CacheConfig.Path = cachePath
+	}
+
+	// SHARE_SNIFFER_BROWSERS 指定浏览器池的浏览器进程数，依赖浏览器的检测并发数为 Size*Tabs
+	if browsers, err := strconv.Atoi(os.Getenv("SHARE_SNIFFER_BROWSERS")); err == nil && browsers > 0 {
+		q.BrowserPoolConfig.Size = browsers
+	}
+
+	// SHARE_SNIFFER_CHROME_URL 指定远程Chrome的DevTools地址，用于没有本地浏览器的服务器和容器
+	if chromeURL := os.Getenv("SHARE_SNIFFER_CHROME_URL"); chromeURL != "" {
+		q.BrowserPoolConfig.RemoteURL = chromeURL
+	}
+
+	// SHARE_SNIFFER_RESOLVE=0 关闭短链接展开
+	if resolve := os.Getenv("SHARE_SNIFFER_RESOLVE"); resolve == "0" || resolve == "false" {
+		q.ResolverConfig.Enabled = false
+	}
+
+	// SHARE_SNIFFER_RULES 指定声明式检查器规则文件
+	if rulesPath := os.Getenv("SHARE_SNIFFER_RULES"); rulesPath != "" {
+		q.RulesConfig.Path = rulesPath
+	}
+
+	// SHARE_SNIFFER_PLUGINS 指定外部检查器插件列表文件
+	if pluginsPath := os.Getenv("SHARE_SNIFFER_PLUGINS"); pluginsPath != "" {
+		q.PluginsConfig.Path = pluginsPath
+	}
+
+	// SHARE_SNIFFER_SCRIPTS 指定脚本检查器目录
+	if scriptsDir := os.Getenv("SHARE_SNIFFER_SCRIPTS"); scriptsDir != "" {
+		q.ScriptsConfig.Dir = scriptsDir
+	}
+
 	// 其他环境变量加载逻辑...
 }
 
@@ -194,28 +435,154 @@ func GetLongTimeout() time.Duration {
 	return GetConfig().CheckConfig.LongTimeout
 }
 
-// GetLongMaxConcurrent 获取长耗时任务最大并发数
-func GetLongMaxConcurrent() int {
-	return GetConfig().CheckConfig.LongMaxConcurrent
-}
-
 // GetSupported 获取指定网盘的支持前缀
 func GetSupported(provider string) []string {
 	return GetConfig().SupportedLinkTypes.Providers[provider]
 }
 
+// GetProvider 根据链接前缀获取网盘标识，未找到时返回空字符串
+func GetProvider(prefix string) string {
+	for name, prefixes := range GetConfig().SupportedLinkTypes.Providers {
+		for _, p := range prefixes {
+			if p == prefix {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
 // GetSupportedLinks 获取所有支持的链接前缀列表
 func GetSupportedLinks() []string {
 	return GetConfig().SupportedLinkTypes.AllPrefixes
 }
 
 // 以下是兼容旧代码的快捷方法，内部调用 GetSupported
-func GetSupportedQuark() []string   { return GetSupported("quark") }
-func GetSupportedTelecom() []string { return GetSupported("telecom") }
-func GetSupportedBaidu() []string   { return GetSupported("baidu") }
-func GetSupportedAliPan() []string  { return GetSupported("alipan") }
-func GetSupportedYyw() []string     { return GetSupported("yyw") }
-func GetSupportedYes() []string     { return GetSupported("yes") }
-func GetSupportedUc() []string      { return GetSupported("uc") }
-func GetSupportedXunlei() []string  { return GetSupported("xunlei") }
-func GetSupportedYd() []string      { return GetSupported("yd") }
+func GetSupportedQuark() []string    { return GetSupported("quark") }
+func GetSupportedTelecom() []string  { return GetSupported("telecom") }
+func GetSupportedBaidu() []string    { return GetSupported("baidu") }
+func GetSupportedAliPan() []string   { return GetSupported("alipan") }
+func GetSupportedYyw() []string      { return GetSupported("yyw") }
+func GetSupportedYes() []string      { return GetSupported("yes") }
+func GetSupportedUc() []string       { return GetSupported("uc") }
+func GetSupportedXunlei() []string   { return GetSupported("xunlei") }
+func GetSupportedYd() []string       { return GetSupported("yd") }
+func GetSupportedLanzou() []string   { return GetSupported("lanzou") }
+func GetSupportedWeiyun() []string   { return GetSupported("weiyun") }
+func GetSupportedPikPak() []string   { return GetSupported("pikpak") }
+func GetSupportedCtfile() []string   { return GetSupported("ctfile") }
+func GetSupportedOneDrive() []string { return GetSupported("onedrive") }
+func GetSupportedGDrive() []string   { return GetSupported("gdrive") }
+func GetSupportedDropbox() []string  { return GetSupported("dropbox") }
+func GetSupportedMega() []string     { return GetSupported("mega") }
+
+// defaultCachePath 返回默认的缓存文件路径，位于用户缓存目录下
+func defaultCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "share-sniffer", "cache.db")
+}
+
+// defaultConfigPath 返回用户配置目录下的配置文件或目录路径
+func defaultConfigPath(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "share-sniffer", name)
+}
+
+// GetRulesPath 获取声明式检查器规则文件路径
+func GetRulesPath() string {
+	return GetConfig().RulesConfig.Path
+}
+
+// GetPluginsPath 获取外部检查器插件列表文件路径
+func GetPluginsPath() string {
+	return GetConfig().PluginsConfig.Path
+}
+
+// GetScriptsDir 获取脚本检查器目录
+func GetScriptsDir() string {
+	return GetConfig().ScriptsConfig.Dir
+}
+
+// RegisterProvider 添加网盘及其链接前缀，网盘已存在时追加新的前缀
+// 用于在启动时注册由规则文件声明的网盘，需在检测开始前调用
+func RegisterProvider(name string, prefixes []string) {
+	cfg := GetConfig()
+	existing := cfg.SupportedLinkTypes.Providers[name]
+	for _, prefix := range prefixes {
+		if !slices.Contains(existing, prefix) {
+			existing = append(existing, prefix)
+		}
+	}
+	cfg.SupportedLinkTypes.Providers[name] = existing
+	cfg.refreshAllPrefixes()
+}
+
+// CacheEnabled 是否启用结果缓存
+func CacheEnabled() bool {
+	return GetConfig().CacheConfig.Enabled
+}
+
+// GetCachePath 获取缓存文件路径
+func GetCachePath() string {
+	return GetConfig().CacheConfig.Path
+}
+
+// GetCacheTTL 获取指定状态的缓存时长，返回0表示不缓存
+func GetCacheTTL(status utils.ErrorType) time.Duration {
+	return GetConfig().CacheConfig.TTLs[status]
+}
+
+// RateLimitEnabled 是否启用限速
+func RateLimitEnabled() bool {
+	return GetConfig().RateLimitConfig.Enabled
+}
+
+// GetRateLimit 获取指定主域名的限速参数，未单独配置时返回默认值
+func GetRateLimit(domain string) RateLimit {
+	cfg := GetConfig().RateLimitConfig
+	if limit, ok := cfg.Hosts[domain]; ok {
+		return limit
+	}
+	return cfg.Default
+}
+
+// GetBrowserPool 获取无头浏览器池参数
+func GetBrowserPool() BrowserPool {
+	return GetConfig().BrowserPoolConfig
+}
+
+// ResolverEnabled 是否启用短链接展开
+func ResolverEnabled() bool {
+	return GetConfig().ResolverConfig.Enabled
+}
+
+// GetResolverMaxHops 获取展开短链接时最多跟随的跳转次数
+func GetResolverMaxHops() int {
+	return GetConfig().ResolverConfig.MaxHops
+}
+
+// IsShortLinkHost 判断主机是否为需要跟随HTTP跳转的短链接主机
+func IsShortLinkHost(host string) bool {
+	return slices.Contains(GetConfig().ResolverConfig.ShortHosts, host)
+}
+
+// GetRedirectParam 获取跳转链接主机携带目标地址的查询参数名，不是跳转链接主机时为空
+func GetRedirectParam(host string) string {
+	return GetConfig().ResolverConfig.RedirectParams[host]
+}
+
+// CircuitBreakerEnabled 是否启用熔断
+func CircuitBreakerEnabled() bool {
+	return GetConfig().CircuitBreakerConfig.Enabled
+}
+
+// GetCircuitBreaker 获取熔断参数
+func GetCircuitBreaker() CircuitBreaker {
+	return GetConfig().CircuitBreakerConfig.CircuitBreaker
+}
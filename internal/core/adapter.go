@@ -5,35 +5,155 @@ import (
 	"strings"
 	"time"
 
+	"share-sniffer/internal/cache"
+	"share-sniffer/internal/config"
+	apphttp "share-sniffer/internal/http"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/parser"
 	"share-sniffer/internal/utils"
 )
 
+// resultCache 检测结果缓存，为nil时不使用缓存
+var resultCache *cache.Cache
+
+// SetCache 设置Adapter使用的检测结果缓存，传入nil关闭缓存
+func SetCache(c *cache.Cache) {
+	resultCache = c
+}
+
+// AdapterOptions 检测选项
+type AdapterOptions struct {
+	// NoCache 跳过缓存读取，检测结果仍会写入缓存
+	NoCache bool
+}
+
 // Adapter 适配器函数，根据URL前缀调用对应的检查器
 // 提供统一的链接检查入口，隐藏了具体检查器的实现细节
 //
 // 参数:
 // - ctx: 上下文，用于控制超时和取消
-// - urlStr: 用户输入的链接字符串
+// - urlStr: 用户输入的链接字符串，也可以是包含链接和提取码的分享文本
 //
 // 返回值:
 // - Result: 包含检查结果的结构体
 func Adapter(ctx context.Context, urlStr string) utils.Result {
+	return AdapterWithOptions(ctx, urlStr, AdapterOptions{})
+}
+
+// AdapterWithOptions 与Adapter相同，可以通过选项控制缓存
+func AdapterWithOptions(ctx context.Context, urlStr string, opts AdapterOptions) utils.Result {
 	// 输入验证
 	if "" == urlStr {
-		return utils.ErrorMalformed(urlStr, "链接不能为空")
+		result := utils.ErrorMalformed(urlStr, "链接不能为空")
+		result.Schema = utils.SchemaVersion
+		result.Data.CheckedAt = time.Now().UnixMilli()
+		return result
+	}
+
+	// 迅雷、快车、旋风链接解码后按原始链接检测，结果中保留用户输入的链接
+	if decoded, ok := decodeDownloadLink(urlStr); ok {
+		result := AdapterWithOptions(ctx, decoded, opts)
+		result.Data.URL = urlStr
+		if result.Data.ResolvedURL == "" {
+			result.Data.ResolvedURL = decoded
+		}
+		return result
+	}
+
+	// 从分享文本中提取链接和提取码，解析失败时按原样处理
+	if link, ok := parser.ParseLine(urlStr); ok {
+		urlStr = link.URL
+	}
+
+	// 获取对应的检查器，检查器使用规范化后的链接
+	r, normalizedURL := routes.match(urlStr)
+
+	// 磁力链接和电驴链接不是网盘分享链接，直接返回解析出的信息
+	if nil == r {
+		if result, ok := p2pLinkResult(urlStr); ok {
+			result.Schema = utils.SchemaVersion
+			result.Data.URL = urlStr
+			result.Data.CheckedAt = time.Now().UnixMilli()
+			return result
+		}
+	}
+
+	// 不受支持的链接可能是短链接或跳转链接，展开后重新匹配
+	var resolvedURL string
+	if nil == r && config.ResolverEnabled() {
+		var err error
+		if r, normalizedURL, resolvedURL, err = resolveInput(ctx, urlStr); err != nil {
+			logger.Info("Adapter:展开短链接失败,%s,%v", urlStr, err)
+			result := errorResult(err)
+			result.Schema = utils.SchemaVersion
+			result.Data.URL = urlStr
+			result.Data.CheckedAt = time.Now().UnixMilli()
+			return result
+		}
+	}
+	if nil == r {
+		result := utils.ErrorMalformed(urlStr, "链接尚未支持").WithReason(utils.ReasonUnsupported)
+		result.Schema = utils.SchemaVersion
+		result.Data.CheckedAt = time.Now().UnixMilli()
+		return result
+	}
+
+	// 按网盘、分享ID和提取码读取缓存
+	var shareID string
+	if identifier, ok := r.checker.(ShareIdentifier); ok {
+		shareID = identifier.ShareID(normalizedURL)
+	}
+	canonical, _ := canonicalOf(r, normalizedURL)
+	cacheKey := cache.Key(r.provider, shareID, sharePassword(r.checker, normalizedURL))
+	if resultCache != nil && !opts.NoCache {
+		if cached, ok := resultCache.Get(cacheKey); ok {
+			cached.Data.URL = urlStr
+			cached.Data.CanonicalURL = canonical.URL
+			cached.Data.ResolvedURL = resolvedURL
+			cached.Data.Cached = true
+			return cached
+		}
 	}
 
-	// 获取对应的检查器
-	checker := GetChecker(urlStr)
-	if nil == checker {
-		return utils.ErrorMalformed(urlStr, "链接尚未支持")
+	// 网盘已被熔断时直接返回，不再发送请求
+	if apphttp.ProviderUnavailable(r.provider) {
+		result := utils.ErrorUnavailable("网盘暂不可用")
+		result.Schema = utils.SchemaVersion
+		result.Data.URL = urlStr
+		result.Data.Provider = r.provider
+		result.Data.ShareID = shareID
+		result.Data.CanonicalURL = canonical.URL
+		result.Data.ResolvedURL = resolvedURL
+		result.Data.CheckedAt = time.Now().UnixMilli()
+		return result
 	}
 
 	startTime := time.Now()
-	result := checker.Check(ctx, urlStr)
+	result := r.checker.Check(apphttp.WithProvider(ctx, r.provider), normalizedURL)
+	result.Schema = utils.SchemaVersion
 	result.Data.URL = urlStr
 	result.Data.Elapsed = time.Since(startTime).Milliseconds()
 	result.Data.Name = strings.TrimSpace(result.Data.Name)
+	result.Data.CheckedAt = time.Now().UnixMilli()
+
+	// 补全原因码、网盘标识和分享ID
+	if result.Reason == "" {
+		result.Reason = utils.DefaultReason(result.Error)
+	}
+	if result.Data.Provider == "" {
+		result.Data.Provider = r.provider
+	}
+	if result.Data.ShareID == "" {
+		result.Data.ShareID = shareID
+	}
+	result.Data.CanonicalURL = canonical.URL
+	result.Data.ResolvedURL = resolvedURL
+
+	if resultCache != nil {
+		if err := resultCache.Put(cacheKey, result, config.GetCacheTTL(result.Error)); err != nil {
+			logger.Warn("Adapter:写入缓存失败,%s,%v", urlStr, err)
+		}
+	}
 
 	return result
 }
@@ -29,6 +29,12 @@ func (q *AliPanChecker) GetPrefix() []string {
 	return config.GetSupportedAliPan()
 }
 
+// ShareID 实现ShareIdentifier接口
+func (q *AliPanChecker) ShareID(urlStr string) string {
+	shareID, _ := extractParamsAliPan(urlStr)
+	return shareID
+}
+
 func (q *AliPanChecker) checkAliPan(ctx context.Context, urlStr string) utils.Result {
 	logger.Debug("AliPanChecker:开始检测阿里云盘链接: %s", urlStr)
 
@@ -40,16 +46,21 @@ func (q *AliPanChecker) checkAliPan(ctx context.Context, urlStr string) utils.Re
 
 	response, err := aliPanRequest(ctx, shareID)
 	if err != nil {
-		if errors.IsTimeoutError(err) {
-			return utils.ErrorTimeout()
-		}
 		if errors.IsStatusCodeError(err) {
 			return utils.ErrorInvalid("分享链接失效")
 		}
-		return utils.ErrorFatal("检测失败")
+		return errorResult(err)
 	}
 
-	return utils.ErrorValid(response.ShareTitle)
+	// 失效链接返回业务错误码，被屏蔽的分享错误码为 ShareLink.Forbidden
+	if response.Code != "" {
+		if response.Code == aliPanCodeForbidden {
+			return utils.ErrorBanned("分享已被屏蔽")
+		}
+		return messageResult(response.Message, "分享链接失效")
+	}
+
+	return utils.ErrorValid(response.ShareTitle).WithMeta(response.meta())
 }
 
 func extractParamsAliPan(urlStr string) (string, error) {
@@ -64,8 +75,36 @@ func extractParamsAliPan(urlStr string) (string, error) {
 	return parts[len(parts)-1], nil
 }
 
+// aliPanCodeForbidden 分享因违规被屏蔽时返回的错误码
+const aliPanCodeForbidden = "ShareLink.Forbidden"
+
 type aliPanResp struct {
-	ShareTitle string `json:"share_title"`
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	ShareTitle  string `json:"share_title"`
+	FileCount   int    `json:"file_count"`
+	Expiration  string `json:"expiration"`
+	CreatorName string `json:"creator_name"`
+	FileInfos   []struct {
+		FileName string `json:"file_name"`
+		Type     string `json:"type"`
+	} `json:"file_infos"`
+}
+
+// meta 根据分享信息生成分享内容元数据，匿名接口不返回文件大小
+func (r *aliPanResp) meta() *utils.Metadata {
+	meta := &utils.Metadata{
+		FileCount: r.FileCount,
+		ExpiresAt: parseExpiryTime(r.Expiration),
+		Creator:   r.CreatorName,
+	}
+	if meta.FileCount == 0 {
+		meta.FileCount = len(r.FileInfos)
+	}
+	for _, f := range r.FileInfos {
+		meta.AddEntry(f.FileName, 0, f.Type == "folder")
+	}
+	return meta
 }
 
 func aliPanRequest(ctx context.Context, shareID string) (*aliPanResp, error) {
@@ -93,7 +132,12 @@ func aliPanRequest(ctx context.Context, shareID string) (*aliPanResp, error) {
 		return nil, err
 	}
 
-	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound {
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		// 失效链接的响应体中带有业务错误码
+		var response aliPanResp
+		if json.Unmarshal(body, &response) == nil && response.Code != "" {
+			return &response, nil
+		}
 		return nil, errors.NewStatusCodeError("链接已失效")
 	}
 
@@ -4,15 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
 	"share-sniffer/internal/config"
-	"share-sniffer/internal/errors"
 	apphttp "share-sniffer/internal/http"
 	"share-sniffer/internal/logger"
 	"share-sniffer/internal/utils"
@@ -34,15 +35,73 @@ func (q *BaiduChecker) GetPrefix() []string {
 	return config.GetSupportedBaidu()
 }
 
+// ShareID 实现ShareIdentifier接口
+func (q *BaiduChecker) ShareID(urlStr string) string {
+	return lastPathSegment(urlStr)
+}
+
 // checkBaidu 检查百度网盘链接
 func (q *BaiduChecker) checkBaidu(ctx context.Context, urlStr string) utils.Result {
 	logger.Debug("BaiduChecker:开始检测百度网盘链接: %s", urlStr)
 
+	step1Result, step2Result, err := q.verify(ctx, urlStr)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	// === 第三步：获取文件列表 ===
+	logger.Debug("\n3. 执行第三步文件列表请求...")
+	step3Result, err := step3Request(ctx, step1Result, step2Result, "", 1)
+	if err != nil {
+		logger.Info("BaiduChecker:step3Request,%s,错误: %v\n", urlStr, err)
+		return errorResult(err)
+	}
+
+	logger.Debug("\n=== 流程完成 ===")
+	if step3Result.JSONResponse == nil || step3Result.JSONResponse.Errno != 0 {
+		return utils.ErrorInvalid("获取分享内容失败")
+	}
+
+	return utils.ErrorValid(step3Result.JSONResponse.Title).WithMeta(step3Result.JSONResponse.meta())
+}
+
+// List 实现Lister接口，通过share/list的dir参数逐层列举
+func (q *BaiduChecker) List(ctx context.Context, urlStr string, opts ListOptions) ([]*ShareNode, bool, error) {
+	step1Result, step2Result, err := q.verify(ctx, urlStr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return walkTree(ctx, func(ctx context.Context, dir string, page int) ([]listEntry, bool, error) {
+		step3Result, err := step3Request(ctx, step1Result, step2Result, dir, page)
+		if err != nil {
+			return nil, false, err
+		}
+		if step3Result.JSONResponse == nil || step3Result.JSONResponse.Errno != 0 {
+			return nil, false, resultError(utils.ErrorInvalid("获取分享内容失败"))
+		}
+
+		list := step3Result.JSONResponse.List
+		entries := make([]listEntry, 0, len(list))
+		for _, f := range list {
+			if f == nil {
+				continue
+			}
+			entries = append(entries, listEntry{id: f.Path, name: f.ServerFilename, size: int64(f.Size), isDir: f.Isdir == 1})
+		}
+		// 顶层目录一次返回全部条目，子目录按页返回
+		return entries, dir != "" && len(list) >= baiduListPageSize, nil
+	}, opts)
+}
+
+// verify 执行第一步和第二步请求，完成提取码验证
+// 分享失效、需要提取码等情况返回 *ResultError
+func (q *BaiduChecker) verify(ctx context.Context, urlStr string) (*Step1Response, *Step2Response, error) {
 	// 解析URL字符串
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
 		logger.Info("BaiduChecker:Parse,%s,错误: %v\n", urlStr, err)
-		return utils.ErrorMalformed(urlStr, "链接格式无效")
+		return nil, nil, resultError(utils.ErrorMalformed(urlStr, "链接格式无效"))
 	}
 
 	password := parsedURL.Query().Get("pwd")
@@ -54,20 +113,21 @@ func (q *BaiduChecker) checkBaidu(ctx context.Context, urlStr string) utils.Resu
 	step1Result, err := step1Request(ctx, urlStr)
 	if err != nil {
 		logger.Info("BaiduChecker:step1Request,%s,错误: %v\n", urlStr, err)
-		if errors.IsTimeoutError(err) {
-			return utils.ErrorTimeout()
-		}
-		return utils.ErrorFatal("第一步请求失败")
+		return nil, nil, resultError(errorResult(err))
 	}
 
-	//过期 200 (百度通常在过期时返回200而不是跳转)
+	//过期 200 (百度通常在过期时返回200而不是跳转)，违规的分享同样返回200
 	if step1Result.StatusCode == http.StatusOK && step1Result.FullRedirectURL == "" {
-		return utils.ErrorInvalid("分享文件已过期")
+		// 只看错误提示元素中的文字，页面脚本和页脚中同样可能出现违规相关的词
+		if containsAny(baiduErrorMessage(step1Result.PageContent), bannedKeywords) {
+			return nil, nil, resultError(utils.ErrorBanned("分享内容涉及违规信息"))
+		}
+		return nil, nil, resultError(utils.ErrorInvalid("分享文件已过期").WithReason(utils.ReasonShareExpired))
 	}
 
 	//正常 302
 	if step1Result.StatusCode != http.StatusFound || step1Result.FullRedirectURL == "" || step1Result.SURL == "" {
-		return utils.ErrorFatal("第一步302失败")
+		return nil, nil, resultError(utils.ErrorFatal("第一步302失败").WithReason(utils.ReasonUpstreamStatus))
 	}
 
 	// === 第二步：验证请求 ===
@@ -75,48 +135,62 @@ func (q *BaiduChecker) checkBaidu(ctx context.Context, urlStr string) utils.Resu
 	step2Result, err := step2Request(ctx, step1Result, password)
 	if err != nil {
 		logger.Info("BaiduChecker:step2Request,%s,错误: %v\n", urlStr, err)
-		if errors.IsTimeoutError(err) {
-			return utils.ErrorTimeout()
-		}
-		return utils.ErrorFatal("验证请求异常")
+		return nil, nil, resultError(errorResult(err))
 	}
 
 	if step2Result.BDCLND == "" {
 		// 检查业务错误码
 		if errno, ok := step2Result.JSONResponse["errno"].(float64); ok && errno != 0 {
-			if errno == -9 {
-				return utils.ErrorInvalid("提取码错误")
+			switch errno {
+			case baiduErrnoWrongPassword:
+				if password == "" {
+					return nil, nil, resultError(utils.ErrorNeedPassword("需要提取码"))
+				}
+				return nil, nil, resultError(utils.ErrorNeedPassword("提取码错误").WithReason(utils.ReasonWrongPasscode))
+			case baiduErrnoCaptcha:
+				return nil, nil, resultError(utils.ErrorRateLimited("需要输入验证码"))
 			}
-			return utils.ErrorInvalid(fmt.Sprintf("验证失败(errno:%v)", errno))
+			return nil, nil, resultError(utils.ErrorInvalid(fmt.Sprintf("验证失败(errno:%v)", errno)))
 		}
-		return utils.ErrorFatal("验证未通过")
+		return nil, nil, resultError(utils.ErrorFatal("验证未通过").WithReason(utils.ReasonUpstreamStatus))
 	}
 
-	// === 第三步：获取文件列表 ===
-	logger.Debug("\n3. 执行第三步文件列表请求...")
-	step3Result, err := step3Request(ctx, step1Result, step2Result)
-	if err != nil {
-		logger.Info("BaiduChecker:step3Request,%s,错误: %v\n", urlStr, err)
-		if errors.IsTimeoutError(err) {
-			return utils.ErrorTimeout()
-		}
-		return utils.ErrorFatal("获取列表失败")
-	}
+	return step1Result, step2Result, nil
+}
 
-	logger.Debug("\n=== 流程完成 ===")
-	if step3Result.JSONResponse == nil || step3Result.JSONResponse.Errno != 0 {
-		return utils.ErrorInvalid("获取分享内容失败")
-	}
+// 百度验证提取码接口的业务错误码
+const (
+	// baiduErrnoWrongPassword 提取码为空或错误
+	baiduErrnoWrongPassword = -9
+	// baiduErrnoCaptcha 请求过于频繁，需要输入验证码
+	baiduErrnoCaptcha = -62
+)
 
-	return utils.ErrorValid(step3Result.JSONResponse.Title)
+// baiduErrorRegex 分享失效页面中的错误提示元素，如
+// <div class="error-reason" id="share_nofound_des">此链接分享内容可能因为涉及侵权、色情、反动、低俗等信息，无法访问！</div>
+var baiduErrorRegex = regexp.MustCompile(`(?s)id="share_nofound_des"[^>]*>(.*?)</`)
+
+// baiduErrorMessage 返回分享失效页面中错误提示元素的文字，没有时为空
+func baiduErrorMessage(page string) string {
+	if m := baiduErrorRegex.FindStringSubmatch(page); m != nil {
+		return strings.TrimSpace(html.UnescapeString(m[1]))
+	}
+	return ""
 }
 
+// baiduPageMaxLen 第一步读取页面内容的最大字节数
+const baiduPageMaxLen = 512 * 1024
+
+// baiduListPageSize 列举子目录时每页的条目数
+const baiduListPageSize = 100
+
 // Step1Response 第一步响应结构体
 type Step1Response struct {
 	StatusCode      int
 	FullRedirectURL string
 	SetCookies      []*http.Cookie
 	SURL            string
+	PageContent     string
 }
 
 // Step2Response 第二步响应结构体
@@ -142,9 +216,23 @@ type ShareListResponse struct {
 
 // FileInfo 文件信息结构体
 type FileInfo struct {
-	ServerFilename string `json:"server_filename"`
-	Size           string `json:"size"`
-	Isdir          string `json:"isdir"`
+	ServerFilename string    `json:"server_filename"`
+	Path           string    `json:"path"`
+	Size           flexInt64 `json:"size"`
+	Isdir          flexInt64 `json:"isdir"`
+}
+
+// meta 根据文件列表生成分享内容元数据
+func (r *ShareListResponse) meta() *utils.Metadata {
+	meta := &utils.Metadata{FileCount: len(r.List)}
+	for _, f := range r.List {
+		if f == nil {
+			continue
+		}
+		meta.TotalSize += int64(f.Size)
+		meta.AddEntry(f.ServerFilename, int64(f.Size), f.Isdir == 1)
+	}
+	return meta
 }
 
 // 第一步请求：获取重定向信息和Cookie
@@ -174,6 +262,10 @@ func step1Request(ctx context.Context, targetURL string) (*Step1Response, error)
 		fullURL, _ := buildFullRedirectURL(targetURL, location)
 		result.FullRedirectURL = fullURL
 		result.SURL, _ = extractSURLFromLocation(location)
+	} else if resp.StatusCode == http.StatusOK {
+		// 未跳转时页面中带有失效或违规的提示信息
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, baiduPageMaxLen))
+		result.PageContent = string(body)
 	}
 
 	return result, nil
@@ -204,7 +296,7 @@ func step2Request(ctx context.Context, step1Result *Step1Response, password stri
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
 	req.Header.Set("Referer", step1Result.FullRedirectURL)
 
-	resp, err := client.Do(req)
+	resp, err := apphttp.DoWithClient(ctx, client, req, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -232,8 +324,8 @@ func step2Request(ctx context.Context, step1Result *Step1Response, password stri
 	return result, nil
 }
 
-// 第三步请求：获取内容
-func step3Request(ctx context.Context, step1Result *Step1Response, step2Result *Step2Response) (*Step3Response, error) {
+// 第三步请求：获取内容，dir为空时获取顶层目录，否则按页获取指定目录
+func step3Request(ctx context.Context, step1Result *Step1Response, step2Result *Step2Response, dir string, page int) (*Step3Response, error) {
 	jar, _ := cookiejar.New(nil)
 	u, _ := url.Parse("https://pan.baidu.com")
 
@@ -245,6 +337,10 @@ func step3Request(ctx context.Context, step1Result *Step1Response, step2Result *
 
 	apiURL := fmt.Sprintf("https://pan.baidu.com/share/list?web=1&app_id=250528&shorturl=%s&root=1&channel=chunlei&clienttype=0",
 		step1Result.SURL)
+	if dir != "" {
+		apiURL = fmt.Sprintf("https://pan.baidu.com/share/list?web=1&app_id=250528&shorturl=%s&dir=%s&page=%d&num=%d&order=name&channel=chunlei&clienttype=0",
+			step1Result.SURL, url.QueryEscape(dir), page, baiduListPageSize)
+	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
@@ -254,7 +350,7 @@ func step3Request(ctx context.Context, step1Result *Step1Response, step2Result *
 	apphttp.SetDefaultHeaders(req)
 	req.Header.Set("Referer", step1Result.FullRedirectURL)
 
-	resp, err := client.Do(req)
+	resp, err := apphttp.DoWithClient(ctx, client, req, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,20 @@
+package core
+
+import "testing"
+
+func TestBaiduErrorMessage(t *testing.T) {
+	tests := []struct {
+		page       string
+		wantBanned bool
+	}{
+		{`<div class="error-reason" id="share_nofound_des">此链接分享内容可能因为涉及侵权、色情、反动、低俗等信息，无法访问！</div>`, true},
+		{`<div id="share_nofound_des">啊哦，你来晚了，分享的文件已经被删除了，下次要早点哟。</div><div class="footer">禁止上传违反法律法规的内容</div>`, false},
+		{`<script>var tip = "涉嫌违规的内容将被屏蔽";</script><div id="share_nofound_des">分享的文件已经过期</div>`, false},
+		{`<html><body>网盘页面，被屏蔽的文件 blocked</body></html>`, false},
+	}
+	for _, tt := range tests {
+		if got := containsAny(baiduErrorMessage(tt.page), bannedKeywords); got != tt.wantBanned {
+			t.Errorf("baiduErrorMessage(%q) banned = %v, want %v", tt.page, got, tt.wantBanned)
+		}
+	}
+}
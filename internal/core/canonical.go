@@ -0,0 +1,111 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// canonical.go 实现了分享链接的规范化和重复检测
+// 同一分享可能以不同域名、不同路径或不同的提取码写法出现，规范化后按网盘、分享ID和提取码识别重复链接
+package core
+
+import (
+	"strings"
+
+	"share-sniffer/internal/cache"
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/parser"
+)
+
+// Canonical 分享链接的规范形式
+type Canonical struct {
+	URL      string `json:"url"`      // 规范链接（已附带提取码）
+	Provider string `json:"provider"` // 网盘标识
+	ShareID  string `json:"share_id"` // 分享ID
+	Password string `json:"password"` // 提取码或Mega链接的密钥，没有时为空
+}
+
+// Key 返回识别重复链接的键，与结果缓存的键一致
+func (c Canonical) Key() string {
+	return cache.Key(c.Provider, c.ShareID, c.Password)
+}
+
+// Canonicalize 把链接转换为规范形式
+// 检查器实现了Normalizer接口时使用检查器的规则，否则使用网盘的第一个前缀拼接分享ID
+//
+// 参数:
+// - urlStr: 用户输入的链接字符串，也可以是包含链接和提取码的分享文本或迅雷等专用链接
+//
+// 返回值:
+// - Canonical: 规范形式
+// - bool: 链接是否受支持且能提取分享ID
+func Canonicalize(urlStr string) (Canonical, bool) {
+	if decoded, ok := decodeDownloadLink(urlStr); ok {
+		urlStr = decoded
+	}
+	if link, ok := parser.ParseLine(urlStr); ok {
+		urlStr = link.URL
+	}
+
+	r, normalizedURL := routes.match(urlStr)
+	if r == nil {
+		return Canonical{}, false
+	}
+	return canonicalOf(r, normalizedURL)
+}
+
+// canonicalOf 按路由对应的检查器规范化链接
+func canonicalOf(r *route, normalizedURL string) (Canonical, bool) {
+	c := Canonical{Provider: r.provider, Password: sharePassword(r.checker, normalizedURL)}
+	var base string
+	if normalizer, ok := r.checker.(Normalizer); ok {
+		base, c.ShareID = normalizer.Normalize(normalizedURL)
+	} else if identifier, ok := r.checker.(ShareIdentifier); ok {
+		if c.ShareID = identifier.ShareID(normalizedURL); c.ShareID != "" {
+			if prefixes := config.GetSupported(r.provider); len(prefixes) > 0 {
+				base = prefixes[0] + c.ShareID
+			}
+		}
+	}
+	if c.ShareID == "" || base == "" {
+		return Canonical{}, false
+	}
+	c.URL = parser.WithPassword(base, r.provider, c.Password)
+	return c, true
+}
+
+// sharePassword 返回链接中的提取码，检查器实现了PasswordReader接口时使用检查器读取的密钥
+func sharePassword(checker LinkChecker, urlStr string) string {
+	if reader, ok := checker.(PasswordReader); ok {
+		return reader.SharePassword(urlStr)
+	}
+	return parser.PasswordFromURL(urlStr)
+}
+
+// FindDuplicates 找出重复的链接，重复的链接只需检测第一次出现的那一个
+// 能规范化的链接按规范形式比较，其余输入按合并空白、去掉末尾标点后的原文比较
+//
+// 返回值:
+// - []int: 与输入一一对应，重复的链接为第一次出现的下标，否则为-1
+func FindDuplicates(urls []string) []int {
+	dupOf := make([]int, len(urls))
+	first := make(map[string]int, len(urls))
+	for i, u := range urls {
+		dupOf[i] = -1
+
+		key := duplicateKey(u)
+		if c, ok := Canonicalize(u); ok {
+			key = c.Key()
+		}
+		if key == "" {
+			continue
+		}
+		if j, ok := first[key]; ok {
+			dupOf[i] = j
+			continue
+		}
+		first[key] = i
+	}
+	return dupOf
+}
+
+// duplicateKey 无法规范化的输入用于比较的原文
+// 输入可能是带说明文字的整行文本，不能只取第一段，否则说明文字相同的不同链接会被当作重复
+func duplicateKey(text string) string {
+	return strings.TrimRight(strings.Join(strings.Fields(text), " "), linkTrailingJunk)
+}
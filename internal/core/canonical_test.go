@@ -0,0 +1,91 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantURL string
+		wantID  string
+	}{
+		{
+			name:    "123 second domain",
+			url:     "https://www.123865.com/s/abcd-EFgh",
+			wantURL: "https://www.123684.com/s/abcd-EFgh",
+			wantID:  "abcd-EFgh",
+		},
+		{
+			name:    "123 with password",
+			url:     "http://123684.com/s/abcd-EFgh?pwd=Ab12",
+			wantURL: "https://www.123684.com/s/abcd-EFgh?pwd=Ab12",
+			wantID:  "abcd-EFgh",
+		},
+		{
+			name:    "telecom web share",
+			url:     "https://cloud.189.cn/web/share?code=AbCdEf123456",
+			wantURL: "https://cloud.189.cn/t/AbCdEf123456",
+			wantID:  "AbCdEf123456",
+		},
+		{
+			name:    "telecom short link",
+			url:     "https://cloud.189.cn/t/AbCdEf123456（访问码：ab12）",
+			wantURL: "https://cloud.189.cn/t/AbCdEf123456?pwd=ab12",
+			wantID:  "AbCdEf123456",
+		},
+		{
+			name:    "115 fragment password",
+			url:     "https://115cdn.com/s/swabc123#?password=x1y2",
+			wantURL: "https://115cdn.com/s/swabc123?password=x1y2",
+			wantID:  "swabc123",
+		},
+		{
+			name:    "quark share text",
+			url:     "链接：https://pan.quark.cn/s/0592e1dbe475 提取码：Ab12",
+			wantURL: "https://pan.quark.cn/s/0592e1dbe475?pwd=Ab12",
+			wantID:  "0592e1dbe475",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Canonicalize(tt.url)
+			if !ok {
+				t.Fatalf("Canonicalize(%q) ok = false", tt.url)
+			}
+			if got.URL != tt.wantURL || got.ShareID != tt.wantID {
+				t.Errorf("Canonicalize(%q) = %s %s, want %s %s", tt.url, got.URL, got.ShareID, tt.wantURL, tt.wantID)
+			}
+		})
+	}
+
+	if _, ok := Canonicalize("https://example.com/s/abc"); ok {
+		t.Errorf("Canonicalize() 不支持的链接应返回false")
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	urls := []string{
+		"https://www.123684.com/s/abcd-EFgh",
+		"https://cloud.189.cn/t/AbCdEf123456",
+		"https://www.123865.com/s/abcd-EFgh",
+		"https://cloud.189.cn/web/share?code=AbCdEf123456",
+		"https://115cdn.com/s/swabc123?password=x1y2",
+		"https://115cdn.com/s/swabc123#?password=x1y2",
+		"https://115cdn.com/s/swabc123",
+		"https://example.com/s/abc",
+		"https://example.com/s/abc。",
+		"thunder://QUFodHRwczovL3d3dy4xMjM2ODQuY29tL3MvYWJjZC1FRmdoWlo=",
+		"下载 thunder://QUFodHRwOi8vZXhhbXBsZS5jb20vYS56aXBaWg==",
+		"下载 thunder://QUFodHRwOi8vZXhhbXBsZS5jb20vYi56aXBaWg==",
+		"链接：https://www.123865.com/s/abcd-EFgh  说明",
+	}
+	want := []int{-1, -1, 0, 1, -1, 4, -1, -1, 7, 0, -1, -1, 0}
+
+	if got := FindDuplicates(urls); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDuplicates() = %v, want %v", got, want)
+	}
+}
@@ -6,7 +6,6 @@ package core
 
 import (
 	"context"
-	"strings"
 	"sync"
 
 	"share-sniffer/internal/logger"
@@ -15,9 +14,9 @@ import (
 
 // 链接检查器注册器
 var (
-	// checkers 存储所有已注册的链接检查器
-	// 键为URL前缀，值为对应的检查器实例
-	checkers = make(map[string]LinkChecker)
+	// routes 存储所有已注册的链接检查器路由表
+	// 按路径前缀长度降序排列，保证最长前缀优先匹配
+	routes = newRouteTable()
 
 	// once 确保初始化只执行一次
 	// 用于保证registerCheckers函数在并发环境下的线程安全
@@ -46,21 +45,89 @@ type LinkChecker interface {
 	GetPrefix() []string
 }
 
+// ShareIdentifier 可选的分享ID提取接口
+// 检查器实现该接口后，Adapter会在检测结果中填充规范化的分享ID
+type ShareIdentifier interface {
+	// ShareID 从链接中提取分享ID，无法提取时返回空字符串
+	ShareID(urlStr string) string
+}
+
+// Normalizer 可选的链接规范化接口
+// 同一分享存在多种链接写法且无法按"前缀+分享ID"拼出规范链接时实现，见 canonical.go
+type Normalizer interface {
+	// Normalize 返回规范链接（不含提取码）和分享ID，无法识别时返回空字符串
+	Normalize(urlStr string) (string, string)
+}
+
+// PasswordReader 可选的提取码读取接口
+// 访问分享所需的密钥不在提取码参数中时实现，如Mega写在片段中的密钥，
+// 读取到的值与提取码一样参与结果缓存和重复检测的键
+type PasswordReader interface {
+	// SharePassword 返回链接中的密钥，没有时返回空字符串
+	SharePassword(urlStr string) string
+}
+
 // RegisterChecker 注册链接检查器
 // 实现了工厂模式，允许动态添加新的检查器
 //
 // 参数:
 // - checker: 实现了LinkChecker接口的检查器实例
-func RegisterChecker(checker LinkChecker) {
-	prefixes := checker.GetPrefix()
+//
+// 返回值:
+// - error: 存在无法解析的前缀，或前缀已被其他检查器注册时返回错误（其余前缀仍会注册）
+func RegisterChecker(checker LinkChecker) error {
+	_, err := registerPrefixes(checker, checker.GetPrefix(), "", false)
+	return err
+}
+
+// ReplaceChecker 注册链接检查器，前缀已被其他检查器注册时替换为该检查器
+// 用于由规则文件修补内置的检查器
+//
+// 参数:
+// - checker: 实现了LinkChecker接口的检查器实例
+//
+// 返回值:
+// - error: 存在无法解析的前缀时返回错误（其余前缀仍会注册）
+func ReplaceChecker(checker LinkChecker) error {
+	_, err := registerPrefixes(checker, checker.GetPrefix(), "", true)
+	return err
+}
+
+// registerPrefixes 逐条注册检查器的前缀
+//
+// 参数:
+// - checker: 实现了LinkChecker接口的检查器实例
+// - prefixes: 要注册的前缀
+// - provider: 网盘标识，为空时按前缀从配置中查找
+// - override: 前缀已被其他检查器注册时是否替换
+//
+// 返回值:
+// - []string: 注册成功的前缀
+// - error: 第一个注册失败的前缀的错误，其余前缀仍会注册
+func registerPrefixes(checker LinkChecker, prefixes []string, provider string, override bool) ([]string, error) {
+	action := "注册"
+	if override {
+		action = "替换"
+	}
+
+	var registered []string
+	var firstErr error
 	for _, prefix := range prefixes {
-		checkers[prefix] = checker
-		logger.Debug("LinkChecker:注册检查器,%s", prefix)
+		if err := routes.insert(prefix, provider, checker, override); err != nil {
+			logger.Warn("LinkChecker:%s检查器失败,%s,%v", action, prefix, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		logger.Debug("LinkChecker:%s检查器,%s", action, prefix)
+		registered = append(registered, prefix)
 	}
+	return registered, firstErr
 }
 
 // GetChecker 根据URL获取对应的检查器
-// 使用策略模式，根据URL特征选择合适的检查器
+// 使用策略模式，根据URL的主机和路径选择最长匹配前缀的检查器
 //
 // 参数:
 // - urlStr: 需要检查的URL字符串
@@ -68,10 +135,24 @@ func RegisterChecker(checker LinkChecker) {
 // 返回值:
 // - LinkChecker: 匹配的检查器实例，如果没有找到则返回nil
 func GetChecker(urlStr string) LinkChecker {
-	for prefix, checker := range checkers {
-		if strings.HasPrefix(urlStr, prefix) {
-			return checker
-		}
+	checker, _ := ResolveChecker(urlStr)
+	return checker
+}
+
+// ResolveChecker 根据URL获取对应的检查器及规范化后的URL
+// 规范化URL使用注册前缀的协议和主机，保留输入的路径、查询参数和片段，
+// 使 http://、大写主机、缺少 www. 等变体都能交给检查器按标准格式处理
+//
+// 参数:
+// - urlStr: 需要检查的URL字符串
+//
+// 返回值:
+// - LinkChecker: 匹配的检查器实例，如果没有找到则返回nil
+// - string: 规范化后的URL，未匹配时为空字符串
+func ResolveChecker(urlStr string) (LinkChecker, string) {
+	r, normalizedURL := routes.match(urlStr)
+	if r == nil {
+		return nil, ""
 	}
-	return nil
+	return r.checker, normalizedURL
 }
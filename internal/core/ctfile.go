@@ -0,0 +1,230 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// ctfile.go 实现了城通网盘链接检查器，作为策略模式的具体策略实现
+// 提供了CtfileChecker结构体，实现了LinkChecker接口的Check和GetPrefix方法
+// 通过网页版使用的 getfile.php / getdir.php 接口检测文件和文件夹分享
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/errors"
+	apphttp "share-sniffer/internal/http"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/parser"
+	"share-sniffer/internal/utils"
+)
+
+// CtfileChecker 城通网盘链接检查器
+// 实现了LinkChecker接口，是策略模式的具体策略之一
+// 城通网盘的分享域名较多(url*.ctfile.com、474b.com等)，前缀使用 *. 匹配所有子域名
+type CtfileChecker struct{}
+
+// Check 实现LinkChecker接口的Check方法
+// 调用内部的checkCtfile方法执行具体的检查逻辑
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的城通网盘分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体
+func (c *CtfileChecker) Check(ctx context.Context, urlStr string) utils.Result {
+	return c.checkCtfile(ctx, urlStr)
+}
+
+// GetPrefix 实现LinkChecker接口的GetPrefix方法
+// 返回城通网盘链接的前缀，用于在注册时识别
+//
+// 返回值:
+// - []string: 城通网盘链接的前缀数组，从配置中获取
+func (c *CtfileChecker) GetPrefix() []string {
+	return config.GetSupportedCtfile()
+}
+
+// ShareID 实现ShareIdentifier接口
+// 文件和文件夹的分享ID可能相同，分享ID带上类型，如 f/1001-2001-aaa
+func (c *CtfileChecker) ShareID(urlStr string) string {
+	kind, shareID, _, err := extractParamsCtfile(urlStr)
+	if err != nil {
+		return ""
+	}
+	return kind + "/" + shareID
+}
+
+// Normalize 实现Normalizer接口，各域名下的分享ID通用，统一为同一个域名
+func (c *CtfileChecker) Normalize(urlStr string) (string, string) {
+	shareID := c.ShareID(urlStr)
+	if shareID == "" {
+		return "", ""
+	}
+	return "https://url.ctfile.com/" + shareID, shareID
+}
+
+// checkCtfile 检测城通网盘链接是否有效
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的城通网盘分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体，包括URL、资源名称、错误码和耗时
+func (c *CtfileChecker) checkCtfile(ctx context.Context, urlStr string) utils.Result {
+	logger.Debug("CtfileChecker:开始检测城通网盘链接: %s", urlStr)
+
+	kind, shareID, passCode, err := extractParamsCtfile(urlStr)
+	if err != nil {
+		logger.Info("CtfileChecker:extractParamsCtfile, %s, 错误: %v\n", urlStr, err)
+		return utils.ErrorMalformed(urlStr, "链接格式无效")
+	}
+
+	requestStart := time.Now()
+	response, err := ctfileRequest(ctx, kind, shareID, passCode)
+	logger.Debug("CtfileChecker:接口请求完成，请求耗时: %dms", time.Since(requestStart).Milliseconds())
+	if err != nil {
+		logger.Info("CtfileChecker:接口检测失败: %s, 错误: %v", urlStr, err)
+		return errorResult(err)
+	}
+	return response.result(passCode)
+}
+
+// ctfileAPIBase 网页版接口地址
+var ctfileAPIBase = "https://webapi.ctfile.com"
+
+// 链接中的分享类型
+const (
+	ctfileKindFile   = "f"
+	ctfileKindFolder = "d"
+)
+
+// ctfileResp 文件和文件夹接口响应结构
+type ctfileResp struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	File    struct {
+		// 文件分享
+		FileName string `json:"file_name"`
+		FileSize string `json:"file_size"`
+		// 文件夹分享
+		FolderName string `json:"folder_name"`
+		// 分享者
+		Username string `json:"username"`
+	} `json:"file"`
+}
+
+// result 根据接口响应创建检测结果
+// 接口的code与HTTP状态码含义一致：401需要密码，404文件已删除，403分享者限制了访问
+func (r *ctfileResp) result(passCode string) utils.Result {
+	switch r.Code {
+	case http.StatusOK:
+		if r.File.FolderName != "" {
+			meta := &utils.Metadata{Creator: r.File.Username}
+			meta.AddEntry(r.File.FolderName, 0, true)
+			return utils.ErrorValid(r.File.FolderName).WithMeta(meta)
+		}
+		size := parseHumanSize(r.File.FileSize)
+		meta := &utils.Metadata{FileCount: 1, TotalSize: size, Creator: r.File.Username}
+		meta.AddEntry(r.File.FileName, size, false)
+		return utils.ErrorValid(r.File.FileName).WithMeta(meta)
+	case http.StatusUnauthorized:
+		if passCode == "" {
+			return utils.ErrorNeedPassword(cmp.Or(r.Message, "请输入访问密码"))
+		}
+		return utils.ErrorNeedPassword(cmp.Or(r.Message, "访问密码错误")).WithReason(utils.ReasonWrongPasscode)
+	case http.StatusNotFound:
+		return utils.ErrorInvalid(cmp.Or(r.Message, "文件不存在或已被删除")).WithReason(utils.ReasonShareNotFound)
+	case http.StatusForbidden:
+		return utils.ErrorLoginRequired(cmp.Or(r.Message, "分享者限制了访问"))
+	}
+	logger.Debug("CtfileChecker:接口返回业务错误: code=%d, message=%s", r.Code, r.Message)
+	return messageResult(r.Message, "分享无法访问")
+}
+
+// ctfileRequest 请求文件或文件夹接口
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - kind: 分享类型，f为文件，d为文件夹
+// - shareID: 分享ID
+// - passCode: 访问密码，没有时为空
+func ctfileRequest(ctx context.Context, kind, shareID, passCode string) (*ctfileResp, error) {
+	params := url.Values{}
+	params.Set("path", kind)
+	params.Set(kind, shareID)
+	params.Set("passcode", passCode)
+	params.Set("token", "false")
+	params.Set("r", strconv.FormatInt(time.Now().UnixNano(), 10))
+	params.Set("ref", "")
+	endpoint := "/getfile.php?"
+	if kind == ctfileKindFolder {
+		params.Set("folder_id", "")
+		endpoint = "/getdir.php?"
+	}
+
+	req, err := apphttp.NewRequestWithContext(ctx, "GET", ctfileAPIBase+endpoint+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("origin", "https://url.ctfile.com")
+	req.Header.Set("referer", "https://url.ctfile.com/")
+
+	resp, err := apphttp.DoWithRetry(ctx, req, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.NewStatusCodeError(fmt.Sprintf("HTTP状态码: %d", resp.StatusCode))
+	}
+
+	var response ctfileResp
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, errors.NewParseError("解析JSON失败", err)
+	}
+	if response.Code == 0 {
+		return nil, errors.NewParseError("接口响应缺少状态码", nil)
+	}
+
+	return &response, nil
+}
+
+// extractParamsCtfile 从城通网盘链接中提取分享类型、分享ID和访问密码
+// 文件链接形如 /f/12345678-987654321-abcdef，文件夹链接形如 /d/12345678-987654321-abcdef，
+// 旧版链接使用 /file/ 和 /dir/，访问密码通过 p 参数携带
+func extractParamsCtfile(rawURL string) (kind, shareID, passCode string, err error) {
+	parsedURL, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	segments := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	if len(segments) != 2 || segments[1] == "" {
+		return "", "", "", fmt.Errorf("无法寻找分享ID")
+	}
+	switch segments[0] {
+	case "f", "file":
+		kind = ctfileKindFile
+	case "d", "dir":
+		kind = ctfileKindFolder
+	default:
+		return "", "", "", fmt.Errorf("不是城通网盘分享链接")
+	}
+
+	return kind, segments[1], parser.PasswordFromURL(rawURL), nil
+}
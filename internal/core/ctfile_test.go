@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"share-sniffer/internal/utils"
+)
+
+// 文件和文件夹接口的响应样例
+var ctfileResponses = map[string]string{
+	"f/1001-2001-aaa": `{"code":200,"file":{"file_name":"安装包.zip","file_size":"12.5 MB","username":"ct_user"}}`,
+	"d/1001-2002-bbb": `{"code":200,"file":{"folder_name":"资料合集","username":"ct_user","url":"/iajax_guest.php?item=file_act"}}`,
+	"f/1001-2003-ccc": `{"code":404,"message":"文件不存在或已被删除"}`,
+	"f/1001-2004-ddd": `{"code":403,"message":"该文件被分享者设置为仅限会员访问"}`,
+	"d/1001-2005-eee": `{"code":404,"message":""}`,
+	"f/1001-2006-fff": `{"code":423,"message":"文件涉嫌违规，已被屏蔽"}`,
+}
+
+func TestCtfileChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		kind := q.Get("path")
+		if (kind == "f" && r.URL.Path != "/getfile.php") || (kind == "d" && r.URL.Path != "/getdir.php") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		shareID := q.Get(kind)
+		// 带密码的分享
+		if shareID == "1001-2007-ggg" {
+			switch q.Get("passcode") {
+			case "":
+				w.Write([]byte(`{"code":401,"message":"请输入访问密码"}`))
+			case "1234":
+				w.Write([]byte(`{"code":200,"file":{"file_name":"私密.pdf","file_size":"356 KB"}}`))
+			default:
+				w.Write([]byte(`{"code":401,"message":"访问密码错误"}`))
+			}
+			return
+		}
+		resp, ok := ctfileResponses[kind+"/"+shareID]
+		if !ok {
+			w.Write([]byte(`<html>error</html>`))
+			return
+		}
+		w.Write([]byte(resp))
+	}))
+	defer server.Close()
+
+	apiBase := ctfileAPIBase
+	ctfileAPIBase = server.URL
+	defer func() { ctfileAPIBase = apiBase }()
+
+	checker := &CtfileChecker{}
+	tests := []struct {
+		url        string
+		wantError  utils.ErrorType
+		wantReason string
+		wantName   string
+	}{
+		{"https://url65.ctfile.com/f/1001-2001-aaa", utils.Valid, utils.ReasonOK, "安装包.zip"},
+		{"https://474b.com/file/1001-2001-aaa", utils.Valid, utils.ReasonOK, "安装包.zip"},
+		{"https://url65.ctfile.com/d/1001-2002-bbb", utils.Valid, utils.ReasonOK, "资料合集"},
+		{"https://url65.ctfile.com/f/1001-2003-ccc", utils.Invalid, utils.ReasonShareNotFound, ""},
+		{"https://url65.ctfile.com/f/1001-2004-ddd", utils.LoginRequired, utils.ReasonLoginRequired, ""},
+		{"https://url65.ctfile.com/d/1001-2005-eee", utils.Invalid, utils.ReasonShareNotFound, ""},
+		{"https://url65.ctfile.com/f/1001-2006-fff", utils.Banned, utils.ReasonBanned, ""},
+		{"https://url65.ctfile.com/f/1001-2007-ggg", utils.NeedPassword, utils.ReasonPasscodeRequired, ""},
+		{"https://url65.ctfile.com/f/1001-2007-ggg?p=0000", utils.NeedPassword, utils.ReasonWrongPasscode, ""},
+		{"https://url65.ctfile.com/f/1001-2007-ggg?p=1234", utils.Valid, utils.ReasonOK, "私密.pdf"},
+		{"https://url65.ctfile.com/f/", utils.Malformed, utils.ReasonMalformedURL, ""},
+	}
+	for _, tt := range tests {
+		got := checker.Check(context.Background(), tt.url)
+		if got.Error != tt.wantError || got.Reason != tt.wantReason || got.Data.Name != tt.wantName {
+			t.Errorf("Check(%q) = %d %s %q %q, want %d %s %q", tt.url, got.Error, got.Reason, got.Msg, got.Data.Name, tt.wantError, tt.wantReason, tt.wantName)
+		}
+	}
+
+	got := checker.Check(context.Background(), "https://url65.ctfile.com/f/1001-2001-aaa")
+	if meta := got.Data.Meta; meta == nil || meta.FileCount != 1 || meta.TotalSize != 12.5*(1<<20) || meta.Creator != "ct_user" {
+		t.Errorf("Check(1001-2001-aaa) meta = %+v", meta)
+	}
+
+	if _, err := ctfileRequest(context.Background(), "f", "1001-9999-zzz", ""); err == nil {
+		t.Errorf("ctfileRequest(1001-9999-zzz) 预期解析错误")
+	}
+}
+
+func TestExtractParamsCtfile(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantKind string
+		wantID   string
+		wantCode string
+		wantErr  bool
+	}{
+		{"https://url65.ctfile.com/f/1001-2001-aaa", "f", "1001-2001-aaa", "", false},
+		{"https://url65.ctfile.com/d/1001-2002-bbb?p=1234", "d", "1001-2002-bbb", "1234", false},
+		{"https://474b.com/file/1001-2001-aaa/", "f", "1001-2001-aaa", "", false},
+		{"https://545c.com/dir/1001-2002-bbb", "d", "1001-2002-bbb", "", false},
+		{"https://url65.ctfile.com/f/", "", "", "", true},
+		{"https://url65.ctfile.com/u/1001", "", "", "", true},
+	}
+	for _, tt := range tests {
+		kind, id, code, err := extractParamsCtfile(tt.url)
+		if (err != nil) != tt.wantErr || kind != tt.wantKind || id != tt.wantID || code != tt.wantCode {
+			t.Errorf("extractParamsCtfile(%q) = %q, %q, %q, %v, want %q, %q, %q", tt.url, kind, id, code, err, tt.wantKind, tt.wantID, tt.wantCode)
+		}
+	}
+
+	if got, id := (&CtfileChecker{}).Normalize("https://474b.com/file/1001-2001-aaa?p=1234"); got != "https://url.ctfile.com/f/1001-2001-aaa" || id != "f/1001-2001-aaa" {
+		t.Errorf("Normalize() = %q, %q", got, id)
+	}
+	// 同一分享ID的文件和文件夹不是重复链接
+	if got := FindDuplicates([]string{"https://url.ctfile.com/f/1001-2001-aaa", "https://url.ctfile.com/d/1001-2001-aaa"}); got[1] != -1 {
+		t.Errorf("FindDuplicates(file, dir) = %v, want no duplicates", got)
+	}
+}
@@ -0,0 +1,187 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// download.go 处理下载工具的专用链接
+// 迅雷(thunder://)、快车(flashget://)和旋风(qqdl://)链接是对原始链接的base64封装，解码后按原始链接检测；
+// 磁力链接和电驴链接不是网盘分享链接，解析出特征码和名称后直接返回
+package core
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"share-sniffer/internal/utils"
+)
+
+var (
+	// downloadLinkRegex 迅雷、快车和旋风链接，快车链接的 & 之后为附加信息
+	downloadLinkRegex = regexp.MustCompile(`(?i)(thunder|flashget|qqdl)://([A-Za-z0-9+/=_-]+)`)
+	// magnetRegex 磁力链接
+	magnetRegex = regexp.MustCompile(`(?i)magnet:\?[^\s"'<>]+`)
+	// ed2kRegex 电驴链接
+	ed2kRegex = regexp.MustCompile(`(?i)ed2k://\|[^\s"'<>]+`)
+	// ed2kHashRegex 电驴链接中的文件哈希
+	ed2kHashRegex = regexp.MustCompile(`^[0-9A-Fa-f]{32}$`)
+)
+
+// decodeDownloadLink 解码输入中的迅雷、快车或旋风链接
+//
+// 参数:
+// - text: 用户输入的链接字符串，也可以是包含链接的文本
+//
+// 返回值:
+// - string: 解码后的原始链接
+// - bool: 输入中是否有可以解码的链接
+func decodeDownloadLink(text string) (string, bool) {
+	m := downloadLinkRegex.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+	data, err := decodeBase64Loose(m[2])
+	if err != nil {
+		return "", false
+	}
+
+	decoded := string(data)
+	switch strings.ToLower(m[1]) {
+	case "thunder":
+		// 迅雷链接为 base64("AA" + 原始链接 + "ZZ")
+		if !strings.HasPrefix(decoded, "AA") || !strings.HasSuffix(decoded, "ZZ") {
+			return "", false
+		}
+		decoded = decoded[len("AA") : len(decoded)-len("ZZ")]
+	case "flashget":
+		// 快车链接为 base64("[FLASHGET]" + 原始链接 + "[FLASHGET]")
+		if !strings.HasPrefix(decoded, "[FLASHGET]") || !strings.HasSuffix(decoded, "[FLASHGET]") {
+			return "", false
+		}
+		decoded = decoded[len("[FLASHGET]") : len(decoded)-len("[FLASHGET]")]
+	}
+	decoded = strings.TrimSpace(decoded)
+	if decoded == "" {
+		return "", false
+	}
+	return decoded, true
+}
+
+// decodeBase64Loose 解码base64，兼容缺少填充和URL安全字符的写法
+func decodeBase64Loose(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+// p2pLink 从磁力链接或电驴链接中解析出的信息
+type p2pLink struct {
+	provider string // magnet 或 ed2k
+	hash     string // 磁力链接的 info-hash 或电驴链接的文件哈希，小写十六进制
+	name     string // 名称，链接中没有时为空
+	size     int64  // 大小（字节），链接中没有时为0
+}
+
+// p2pLinkResult 磁力链接和电驴链接不是网盘分享链接，返回带有特征码和名称的检测结果
+//
+// 参数:
+// - text: 用户输入的链接字符串，也可以是包含链接的文本
+//
+// 返回值:
+// - Result: 检测结果，原因码为 not_share_link，链接无法解析时为格式错误
+// - bool: 输入是否为磁力链接或电驴链接
+func p2pLinkResult(text string) (utils.Result, bool) {
+	if link := magnetRegex.FindString(text); link != "" {
+		info, err := parseMagnet(link)
+		if err != nil {
+			return utils.ErrorMalformed(link, "磁力链接格式无效"), true
+		}
+		return info.result("磁力链接不是网盘分享链接"), true
+	}
+	if link := ed2kRegex.FindString(text); link != "" {
+		info, err := parseEd2k(link)
+		if err != nil {
+			return utils.ErrorMalformed(link, "电驴链接格式无效"), true
+		}
+		return info.result("电驴链接不是网盘分享链接"), true
+	}
+	return utils.Result{}, false
+}
+
+// result 创建不是网盘分享链接的检测结果，特征码作为分享ID
+func (l *p2pLink) result(msg string) utils.Result {
+	result := utils.ErrorMalformed("", msg).WithReason(utils.ReasonNotShareLink)
+	result.Data.Name = l.name
+	result.Data.Provider = l.provider
+	result.Data.ShareID = l.hash
+	// 电驴链接对应单个文件，磁力链接的文件数要在获取种子信息后才知道
+	if l.provider == "ed2k" && l.size > 0 {
+		meta := &utils.Metadata{FileCount: 1, TotalSize: l.size}
+		meta.AddEntry(l.name, l.size, false)
+		result.Data.Meta = meta
+	}
+	return result
+}
+
+// parseMagnet 解析磁力链接
+// 支持 BitTorrent v1 的 urn:btih:（40位十六进制或32位base32）和 v2 的 urn:btmh:，
+// 同时读取 dn(名称) 和 xl(大小) 参数
+func parseMagnet(link string) (*p2pLink, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+
+	info := &p2pLink{provider: "magnet", name: strings.TrimSpace(query.Get("dn"))}
+	info.size, _ = strconv.ParseInt(query.Get("xl"), 10, 64)
+	for _, xt := range query["xt"] {
+		lower := strings.ToLower(xt)
+		switch {
+		case strings.HasPrefix(lower, "urn:btih:"):
+			hash := xt[len("urn:btih:"):]
+			if len(hash) == 32 {
+				// base32 形式的 info-hash 转换为十六进制
+				raw, err := base32.StdEncoding.DecodeString(strings.ToUpper(hash))
+				if err != nil {
+					return nil, err
+				}
+				hash = hex.EncodeToString(raw)
+			}
+			if _, err := hex.DecodeString(hash); err != nil || len(hash) != 40 {
+				return nil, fmt.Errorf("info-hash格式无效: %s", hash)
+			}
+			info.hash = strings.ToLower(hash)
+		case strings.HasPrefix(lower, "urn:btmh:") && info.hash == "":
+			info.hash = lower[len("urn:btmh:"):]
+		}
+	}
+	if info.hash == "" {
+		return nil, fmt.Errorf("缺少info-hash")
+	}
+	return info, nil
+}
+
+// parseEd2k 解析电驴文件链接，格式为 ed2k://|file|名称|大小|哈希|/
+func parseEd2k(link string) (*p2pLink, error) {
+	parts := strings.Split(link, "|")
+	if len(parts) < 5 || !strings.EqualFold(parts[1], "file") {
+		return nil, fmt.Errorf("不是电驴文件链接")
+	}
+	if !ed2kHashRegex.MatchString(parts[4]) {
+		return nil, fmt.Errorf("文件哈希格式无效: %s", parts[4])
+	}
+	size, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("文件大小无效: %w", err)
+	}
+	name := parts[2]
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return &p2pLink{provider: "ed2k", hash: strings.ToLower(parts[4]), name: strings.TrimSpace(name), size: size}, nil
+}
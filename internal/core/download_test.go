@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"share-sniffer/internal/utils"
+)
+
+func TestDecodeDownloadLink(t *testing.T) {
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{"thunder://" + encode("AAhttps://pan.xunlei.com/s/VNabcdEFGH?pwd=ab12ZZ"), "https://pan.xunlei.com/s/VNabcdEFGH?pwd=ab12", true},
+		{"下载地址：thunder://" + encode("AAmagnet:?xt=urn:btih:c9e15763f722f23e98a29decdfae341b98d53056ZZ") + " 复制到迅雷", "magnet:?xt=urn:btih:c9e15763f722f23e98a29decdfae341b98d53056", true},
+		{"flashget://" + encode("[FLASHGET]http://example.com/a.zip[FLASHGET]") + "&abc", "http://example.com/a.zip", true},
+		{"qqdl://" + base64.RawStdEncoding.EncodeToString([]byte("http://example.com/b.rar")), "http://example.com/b.rar", true},
+		{"thunder://" + encode("http://example.com/no-wrapper"), "", false},
+		{"thunder://!!!", "", false},
+		{"https://pan.xunlei.com/s/VNabcdEFGH", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := decodeDownloadLink(tt.text)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("decodeDownloadLink(%q) = %q, %v, want %q, %v", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestP2PLinkResult(t *testing.T) {
+	tests := []struct {
+		text       string
+		wantOK     bool
+		wantReason string
+		wantHash   string
+		wantName   string
+	}{
+		{"magnet:?xt=urn:btih:C9E15763F722F23E98A29DECDFAE341B98D53056&dn=Big+Buck+Bunny&tr=udp%3A%2F%2Ftracker.example.com%3A80", true, utils.ReasonNotShareLink, "c9e15763f722f23e98a29decdfae341b98d53056", "Big Buck Bunny"},
+		{"磁力：magnet:?xt=urn:btih:ZHQVOY7XELZD5GFCTXWN7LRUDOMNKMCW", true, utils.ReasonNotShareLink, "c9e15763f722f23e98a29decdfae341b98d53056", ""},
+		{"magnet:?dn=abc&xt=urn:btmh:1220caf1e1c30e81cb361b9ee167c4aa64228a7fa4fa9f6105232b28ad099f3a302e", true, utils.ReasonNotShareLink, "1220caf1e1c30e81cb361b9ee167c4aa64228a7fa4fa9f6105232b28ad099f3a302e", "abc"},
+		{"ed2k://|file|%E6%B5%8B%E8%AF%95.mkv|734003200|A2B4C6D8E0F2A4B6C8D0E2F4A6B8C0D2|/", true, utils.ReasonNotShareLink, "a2b4c6d8e0f2a4b6c8d0e2f4a6b8c0d2", "测试.mkv"},
+		{"magnet:?xt=urn:btih:xyz", true, utils.ReasonMalformedURL, "", ""},
+		{"ed2k://|server|1.2.3.4|4661|/", true, utils.ReasonMalformedURL, "", ""},
+		{"https://pan.quark.cn/s/abc", false, "", "", ""},
+	}
+	for _, tt := range tests {
+		got, ok := p2pLinkResult(tt.text)
+		if ok != tt.wantOK || got.Reason != tt.wantReason || got.Data.ShareID != tt.wantHash || got.Data.Name != tt.wantName {
+			t.Errorf("p2pLinkResult(%q) = %v %s %q %q, want %v %s %q %q", tt.text, ok, got.Reason, got.Data.ShareID, got.Data.Name, tt.wantOK, tt.wantReason, tt.wantHash, tt.wantName)
+		}
+	}
+
+	got, _ := p2pLinkResult("ed2k://|file|a.iso|1024|A2B4C6D8E0F2A4B6C8D0E2F4A6B8C0D2|/")
+	if meta := got.Data.Meta; got.Data.Provider != "ed2k" || meta == nil || meta.FileCount != 1 || meta.TotalSize != 1024 {
+		t.Errorf("p2pLinkResult(ed2k) = %+v", got.Data)
+	}
+}
+
+func TestAdapterDownloadLink(t *testing.T) {
+	magnet := "magnet:?xt=urn:btih:c9e15763f722f23e98a29decdfae341b98d53056&dn=demo"
+	thunder := "thunder://" + base64.StdEncoding.EncodeToString([]byte("AA"+magnet+"ZZ"))
+	got := Adapter(context.Background(), thunder)
+	if got.Error != utils.Malformed || got.Reason != utils.ReasonNotShareLink || got.Data.URL != thunder || got.Data.ResolvedURL != magnet || got.Data.Name != "demo" {
+		t.Errorf("Adapter(thunder magnet) = %d %s %+v", got.Error, got.Reason, got.Data)
+	}
+
+	// 解码后不是受支持的链接时按尚未支持处理，仍记录解码后的链接
+	plain := "thunder://" + base64.StdEncoding.EncodeToString([]byte("AAhttp://example.com/file.zipZZ"))
+	got = Adapter(context.Background(), plain)
+	if got.Reason != utils.ReasonUnsupported || got.Data.URL != plain || got.Data.ResolvedURL != "http://example.com/file.zip" {
+		t.Errorf("Adapter(thunder http) = %d %s %+v", got.Error, got.Reason, got.Data)
+	}
+}
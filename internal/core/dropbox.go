@@ -0,0 +1,193 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// dropbox.go 实现了Dropbox链接检查器，作为策略模式的具体策略实现
+// 提供了DropboxChecker结构体，实现了LinkChecker接口的Check和GetPrefix方法
+// 请求分享网页，根据状态码、跳转地址和网页内容判断分享状态，db.tt 短链接和旧版链接的跳转在Dropbox域名内跟随
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/errors"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/utils"
+)
+
+// DropboxChecker Dropbox链接检查器
+// 实现了LinkChecker接口，是策略模式的具体策略之一
+// 支持 /s/、/sh/、/scl/fi/、/scl/fo/ 分享链接和 db.tt 短链接
+type DropboxChecker struct{}
+
+// Check 实现LinkChecker接口的Check方法
+// 调用内部的checkDropbox方法执行具体的检查逻辑
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的Dropbox分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体
+func (d *DropboxChecker) Check(ctx context.Context, urlStr string) utils.Result {
+	return d.checkDropbox(ctx, urlStr)
+}
+
+// GetPrefix 实现LinkChecker接口的GetPrefix方法
+// 返回Dropbox链接的前缀，用于在注册时识别
+//
+// 返回值:
+// - []string: Dropbox链接的前缀数组，从配置中获取
+func (d *DropboxChecker) GetPrefix() []string {
+	return config.GetSupportedDropbox()
+}
+
+// ShareID 实现ShareIdentifier接口
+func (d *DropboxChecker) ShareID(urlStr string) string {
+	_, shareID, _ := extractParamsDropbox(urlStr)
+	return shareID
+}
+
+// Normalize 实现Normalizer接口，去掉 dl 等无关参数，新版链接保留访问所需的 rlkey
+func (d *DropboxChecker) Normalize(urlStr string) (string, string) {
+	pageURL, shareID, err := extractParamsDropbox(urlStr)
+	if err != nil || strings.HasPrefix(pageURL, dropboxShortBase+"/") {
+		return "", ""
+	}
+	return pageURL, shareID
+}
+
+// checkDropbox 检测Dropbox链接是否有效
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的Dropbox分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体，包括URL、资源名称、错误码和耗时
+func (d *DropboxChecker) checkDropbox(ctx context.Context, urlStr string) utils.Result {
+	logger.Debug("DropboxChecker:开始检测Dropbox链接: %s", urlStr)
+
+	pageURL, _, err := extractParamsDropbox(urlStr)
+	if err != nil {
+		logger.Info("DropboxChecker:extractParamsDropbox, %s, 错误: %v\n", urlStr, err)
+		return utils.ErrorMalformed(urlStr, "链接格式无效")
+	}
+
+	requestStart := time.Now()
+	page, err := fetchDropboxPage(ctx, pageURL)
+	logger.Debug("DropboxChecker:网页请求完成，请求耗时: %dms", time.Since(requestStart).Milliseconds())
+	if err != nil {
+		logger.Info("DropboxChecker:网页请求失败: %s, 错误: %v", urlStr, err)
+		// 链接流量超限时Dropbox返回429
+		if errors.IsRateLimitError(err) {
+			return utils.ErrorRateLimited("分享链接流量超限，已被暂时禁用")
+		}
+		return errorResult(err)
+	}
+	return dropboxPageResult(page, strings.Contains(pageURL, "/sh/") || strings.Contains(pageURL, "/scl/fo/"))
+}
+
+// dropboxMaxHops 在Dropbox域名内最多跟随的跳转次数
+const dropboxMaxHops = 3
+
+var (
+	// dropboxBase Dropbox网页地址
+	dropboxBase = "https://www.dropbox.com"
+	// dropboxShortBase Dropbox短链接地址
+	dropboxShortBase = "https://db.tt"
+	// dropboxTrafficKeywords 链接流量超限时网页中的提示
+	dropboxTrafficKeywords = []string{"generating too much traffic", "links have been temporarily disabled"}
+	// dropboxDeletedKeywords 文件已删除时网页中的提示
+	dropboxDeletedKeywords = []string{"this item was deleted", "that file isn’t here anymore", "that file isn't here anymore", "this file is no longer available"}
+)
+
+// fetchDropboxPage 请求分享网页，短链接和旧版链接在Dropbox域名内的跳转继续跟随，跳转到登录页面时停止
+func fetchDropboxPage(ctx context.Context, pageURL string) (*sharePage, error) {
+	var page *sharePage
+	var err error
+	for hop := 0; hop <= dropboxMaxHops; hop++ {
+		if page, err = fetchSharePage(ctx, pageURL); err != nil {
+			return nil, err
+		}
+		host := page.redirectHost()
+		sameSite := host == "dropbox.com" || strings.HasSuffix(host, ".dropbox.com") || strings.HasPrefix(page.Location, dropboxBase+"/")
+		if !sameSite || strings.Contains(page.Location, "/login") {
+			return page, nil
+		}
+		pageURL = page.Location
+	}
+	return page, nil
+}
+
+// dropboxPageResult 根据网页创建检测结果
+func dropboxPageResult(page *sharePage, folder bool) utils.Result {
+	switch {
+	case page.StatusCode == http.StatusNotFound || page.StatusCode == http.StatusGone,
+		page.containsAnyFold(dropboxDeletedKeywords...):
+		return utils.ErrorInvalid("文件已被删除或链接已失效").WithReason(utils.ReasonShareNotFound)
+	case page.containsAnyFold(dropboxTrafficKeywords...):
+		return utils.ErrorRateLimited("分享链接流量超限，已被暂时禁用")
+	case page.StatusCode == 460:
+		// Dropbox对因版权或违规被限制的内容返回460
+		return utils.ErrorBanned("内容因违规被限制访问")
+	case page.Location != "", page.StatusCode == http.StatusUnauthorized, page.StatusCode == http.StatusForbidden:
+		// 仅限团队成员或指定成员访问的链接跳转到登录页面
+		return utils.ErrorLoginRequired("没有访问权限，需要登录或分享者授权")
+	case page.StatusCode != http.StatusOK:
+		return utils.ErrorInvalid(fmt.Sprintf("网页返回状态码%d", page.StatusCode)).WithReason(utils.ReasonUpstreamStatus)
+	}
+
+	name := strings.TrimPrefix(page.title("- Dropbox", "- Simplify your life"), "Dropbox - ")
+	if name == "" || name == "Dropbox" {
+		return utils.ErrorInvalid("无法获取分享信息").WithReason(utils.ReasonPageError)
+	}
+	meta := &utils.Metadata{}
+	if !folder {
+		meta.FileCount = 1
+	}
+	meta.AddEntry(name, 0, folder)
+	return utils.ErrorValid(name).WithMeta(meta)
+}
+
+// extractParamsDropbox 从Dropbox链接中提取分享网页地址和分享ID
+// 网页地址去掉了 dl、raw 等参数，新版链接(/scl/)保留 rlkey 参数
+func extractParamsDropbox(rawURL string) (pageURL, shareID string, err error) {
+	parsedURL, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	segments := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	switch host := strings.TrimPrefix(strings.ToLower(parsedURL.Hostname()), "www."); {
+	case host == "db.tt":
+		if segments[0] == "" {
+			return "", "", fmt.Errorf("无法寻找分享ID")
+		}
+		return dropboxShortBase + "/" + segments[0], segments[0], nil
+	case host != "dropbox.com":
+		return "", "", fmt.Errorf("不是Dropbox分享链接")
+	}
+
+	switch {
+	case len(segments) >= 2 && (segments[0] == "s" || segments[0] == "sh"):
+		shareID = segments[1]
+	case len(segments) >= 3 && segments[0] == "scl" && (segments[1] == "fi" || segments[1] == "fo"):
+		shareID = segments[2]
+	default:
+		return "", "", fmt.Errorf("无法寻找分享ID")
+	}
+	if shareID == "" {
+		return "", "", fmt.Errorf("无法寻找分享ID")
+	}
+
+	pageURL = dropboxBase + strings.TrimRight(parsedURL.EscapedPath(), "/")
+	if rlkey := parsedURL.Query().Get("rlkey"); rlkey != "" {
+		pageURL += "?rlkey=" + url.QueryEscape(rlkey)
+	}
+	return pageURL, shareID, nil
+}
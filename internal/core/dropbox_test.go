@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"share-sniffer/internal/utils"
+)
+
+func TestDropboxChecker(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/short01":
+			// 短链接跳转到旧版链接，旧版链接再跳转到新版链接
+			http.Redirect(w, r, server.URL+"/s/abc123/photo.jpg?dl=0", http.StatusMovedPermanently)
+		case "/s/abc123/photo.jpg":
+			http.Redirect(w, r, server.URL+"/scl/fi/abc123/photo.jpg?rlkey=k1&dl=0", http.StatusFound)
+		case "/scl/fi/abc123/photo.jpg":
+			if r.URL.Query().Get("rlkey") != "k1" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`<html><head><meta content="photo.jpg" property="og:title"><title>Dropbox - photo.jpg - Simplify your life</title></head></html>`))
+		case "/scl/fo/dir456/key456":
+			w.Write([]byte(`<html><head><title>Project Files - Dropbox</title></head></html>`))
+		case "/scl/fi/gone789/old.zip":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<html><body>That file isn't here anymore</body></html>`))
+		case "/scl/fi/team000/plan.docx":
+			http.Redirect(w, r, server.URL+"/login?cont="+r.URL.Path, http.StatusFound)
+		case "/scl/fi/busy111/video.mp4":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/s/traffic222/video.mp4":
+			w.Write([]byte(`<html><body>This account's links are generating too much traffic and have been temporarily disabled!</body></html>`))
+		case "/scl/fi/dmca333/movie.mkv":
+			w.WriteHeader(460)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	base, shortBase := dropboxBase, dropboxShortBase
+	dropboxBase, dropboxShortBase = server.URL, server.URL
+	defer func() { dropboxBase, dropboxShortBase = base, shortBase }()
+
+	checker := &DropboxChecker{}
+	tests := []struct {
+		url        string
+		wantError  utils.ErrorType
+		wantReason string
+		wantName   string
+	}{
+		{"https://www.dropbox.com/scl/fi/abc123/photo.jpg?rlkey=k1&dl=0", utils.Valid, utils.ReasonOK, "photo.jpg"},
+		{"https://www.dropbox.com/s/abc123/photo.jpg?dl=1", utils.Valid, utils.ReasonOK, "photo.jpg"},
+		{"https://db.tt/short01", utils.Valid, utils.ReasonOK, "photo.jpg"},
+		{"https://www.dropbox.com/scl/fo/dir456/key456?rlkey=k2", utils.Valid, utils.ReasonOK, "Project Files"},
+		{"https://www.dropbox.com/scl/fi/gone789/old.zip?rlkey=k3", utils.Invalid, utils.ReasonShareNotFound, ""},
+		{"https://www.dropbox.com/scl/fi/team000/plan.docx?rlkey=k4", utils.LoginRequired, utils.ReasonLoginRequired, ""},
+		{"https://www.dropbox.com/scl/fi/busy111/video.mp4?rlkey=k5", utils.RateLimited, utils.ReasonRateLimited, ""},
+		{"https://www.dropbox.com/s/traffic222/video.mp4", utils.RateLimited, utils.ReasonRateLimited, ""},
+		{"https://www.dropbox.com/scl/fi/dmca333/movie.mkv?rlkey=k6", utils.Banned, utils.ReasonBanned, ""},
+		{"https://www.dropbox.com/home", utils.Malformed, utils.ReasonMalformedURL, ""},
+	}
+	for _, tt := range tests {
+		got := checker.Check(context.Background(), tt.url)
+		if got.Error != tt.wantError || got.Reason != tt.wantReason || got.Data.Name != tt.wantName {
+			t.Errorf("Check(%q) = %d %s %q %q, want %d %s %q", tt.url, got.Error, got.Reason, got.Msg, got.Data.Name, tt.wantError, tt.wantReason, tt.wantName)
+		}
+	}
+}
+
+func TestExtractParamsDropbox(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantPage string
+		wantID   string
+		wantErr  bool
+	}{
+		{"https://www.dropbox.com/s/abc123/photo.jpg?dl=0", "https://www.dropbox.com/s/abc123/photo.jpg", "abc123", false},
+		{"https://dropbox.com/scl/fi/abc123/photo%20a.jpg?rlkey=k1&st=x&dl=1", "https://www.dropbox.com/scl/fi/abc123/photo%20a.jpg?rlkey=k1", "abc123", false},
+		{"https://www.dropbox.com/sh/dir456/key456/", "https://www.dropbox.com/sh/dir456/key456", "dir456", false},
+		{"https://db.tt/short01", "https://db.tt/short01", "short01", false},
+		{"https://www.dropbox.com/scl/fi/", "", "", true},
+		{"https://www.dropbox.com/home", "", "", true},
+	}
+	for _, tt := range tests {
+		page, id, err := extractParamsDropbox(tt.url)
+		if (err != nil) != tt.wantErr || page != tt.wantPage || id != tt.wantID {
+			t.Errorf("extractParamsDropbox(%q) = %q, %q, %v, want %q, %q", tt.url, page, id, err, tt.wantPage, tt.wantID)
+		}
+	}
+}
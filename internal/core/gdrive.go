@@ -0,0 +1,209 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// gdrive.go 实现了Google Drive链接检查器，作为策略模式的具体策略实现
+// 提供了GDriveChecker结构体，实现了LinkChecker接口的Check和GetPrefix方法
+// 匿名访问没有可用的接口，请求文件或文件夹的网页，根据状态码、跳转地址和网页内容判断分享状态
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/utils"
+)
+
+// GDriveChecker Google Drive链接检查器
+// 实现了LinkChecker接口，是策略模式的具体策略之一
+// 支持 drive.google.com 的文件和文件夹链接，以及 docs.google.com 的文档、表格和演示文稿链接
+type GDriveChecker struct{}
+
+// Check 实现LinkChecker接口的Check方法
+// 调用内部的checkGDrive方法执行具体的检查逻辑
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的Google Drive分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体
+func (g *GDriveChecker) Check(ctx context.Context, urlStr string) utils.Result {
+	return g.checkGDrive(ctx, urlStr)
+}
+
+// GetPrefix 实现LinkChecker接口的GetPrefix方法
+// 返回Google Drive链接的前缀，用于在注册时识别
+//
+// 返回值:
+// - []string: Google Drive链接的前缀数组，从配置中获取
+func (g *GDriveChecker) GetPrefix() []string {
+	return config.GetSupportedGDrive()
+}
+
+// ShareID 实现ShareIdentifier接口
+func (g *GDriveChecker) ShareID(urlStr string) string {
+	link, err := extractParamsGDrive(urlStr)
+	if err != nil {
+		return ""
+	}
+	return link.id
+}
+
+// Normalize 实现Normalizer接口，同一文件的 /file/d/、/open?id= 等写法统一为网页地址
+func (g *GDriveChecker) Normalize(urlStr string) (string, string) {
+	link, err := extractParamsGDrive(urlStr)
+	if err != nil {
+		return "", ""
+	}
+	return link.pageURL(), link.id
+}
+
+// checkGDrive 检测Google Drive链接是否有效
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的Google Drive分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体，包括URL、资源名称、错误码和耗时
+func (g *GDriveChecker) checkGDrive(ctx context.Context, urlStr string) utils.Result {
+	logger.Debug("GDriveChecker:开始检测Google Drive链接: %s", urlStr)
+
+	link, err := extractParamsGDrive(urlStr)
+	if err != nil {
+		logger.Info("GDriveChecker:extractParamsGDrive, %s, 错误: %v\n", urlStr, err)
+		return utils.ErrorMalformed(urlStr, "链接格式无效")
+	}
+
+	requestStart := time.Now()
+	page, err := fetchSharePage(ctx, link.pageURL())
+	logger.Debug("GDriveChecker:网页请求完成，请求耗时: %dms", time.Since(requestStart).Milliseconds())
+	if err != nil {
+		logger.Info("GDriveChecker:网页请求失败: %s, 错误: %v", urlStr, err)
+		return errorResult(err)
+	}
+
+	result := gdrivePageResult(page, link.kind == gdriveKindFolder)
+	if result.Error != utils.Valid || link.kind != gdriveKindFile {
+		return result
+	}
+
+	// 文件可以正常查看时，再确认下载配额是否已用尽
+	if quota, err := fetchSharePage(ctx, gdriveBase+"/uc?export=download&id="+url.QueryEscape(link.id)); err != nil {
+		logger.Debug("GDriveChecker:下载配额检测失败: %s, 错误: %v", urlStr, err)
+	} else if quota.StatusCode == http.StatusOK && quota.containsAnyFold(gdriveQuotaKeywords...) {
+		return utils.ErrorRateLimited("下载配额已用尽，暂时无法下载")
+	}
+	return result
+}
+
+var (
+	// gdriveBase Google Drive网页地址
+	gdriveBase = "https://drive.google.com"
+	// gdocsBase Google文档网页地址
+	gdocsBase = "https://docs.google.com"
+)
+
+// 链接指向的内容类型
+const (
+	gdriveKindFile   = "file"
+	gdriveKindFolder = "folder"
+	gdriveKindDocs   = "docs"
+)
+
+var (
+	// gdriveIDRegex 文件和文件夹ID
+	gdriveIDRegex = regexp.MustCompile(`^[\w-]{10,}$`)
+	// gdriveTitleSuffixes 网页标题末尾的网站名称
+	gdriveTitleSuffixes = []string{"- Google Drive", "– Google Drive", "- Google Docs", "- Google Sheets", "- Google Slides", "- Google Forms"}
+	// gdriveAccessKeywords 没有访问权限时网页中的提示
+	gdriveAccessKeywords = []string{"you need access", "request access", "you need permission"}
+	// gdriveQuotaKeywords 下载配额用尽时网页中的提示
+	gdriveQuotaKeywords = []string{"too many users have viewed or downloaded this file", "quota exceeded", "download quota"}
+)
+
+// gdriveLink 从链接中解析出的内容
+type gdriveLink struct {
+	kind string
+	id   string
+	// docType 文档类型，如 document、spreadsheets、presentation，仅 docs 类型有效
+	docType string
+}
+
+// pageURL 返回内容的网页地址
+func (l gdriveLink) pageURL() string {
+	switch l.kind {
+	case gdriveKindFolder:
+		return gdriveBase + "/drive/folders/" + l.id
+	case gdriveKindDocs:
+		return gdocsBase + "/" + l.docType + "/d/" + l.id + "/edit"
+	}
+	return gdriveBase + "/file/d/" + l.id + "/view"
+}
+
+// gdrivePageResult 根据网页创建检测结果
+// 文件不存在时返回404，没有访问权限时跳转到登录页面或显示申请访问权限的提示
+func gdrivePageResult(page *sharePage, folder bool) utils.Result {
+	switch {
+	case page.StatusCode == http.StatusNotFound || page.StatusCode == http.StatusGone:
+		return utils.ErrorInvalid("文件不存在或已被删除").WithReason(utils.ReasonShareNotFound)
+	case page.redirectHost() == "accounts.google.com",
+		page.StatusCode == http.StatusUnauthorized,
+		page.StatusCode == http.StatusForbidden,
+		page.StatusCode == http.StatusOK && page.containsAnyFold(gdriveAccessKeywords...):
+		return utils.ErrorLoginRequired("没有访问权限，需要分享者授权")
+	case page.StatusCode != http.StatusOK:
+		return utils.ErrorInvalid(fmt.Sprintf("网页返回状态码%d", page.StatusCode)).WithReason(utils.ReasonUpstreamStatus)
+	}
+
+	name := page.title(gdriveTitleSuffixes...)
+	if name == "" || name == "Google Drive" {
+		return utils.ErrorInvalid("无法获取分享信息").WithReason(utils.ReasonPageError)
+	}
+	meta := &utils.Metadata{}
+	if !folder {
+		meta.FileCount = 1
+	}
+	meta.AddEntry(name, 0, folder)
+	return utils.ErrorValid(name).WithMeta(meta)
+}
+
+// extractParamsGDrive 从Google Drive链接中解析内容类型和ID
+// 支持 /file/d/ID、/drive/folders/ID、/drive/u/0/folders/ID、/open?id=ID、/uc?id=ID 和 docs.google.com/TYPE/d/ID
+func extractParamsGDrive(rawURL string) (gdriveLink, error) {
+	parsedURL, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return gdriveLink{}, err
+	}
+
+	var link gdriveLink
+	segments := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	switch host := strings.ToLower(parsedURL.Hostname()); {
+	case host == "docs.google.com":
+		if len(segments) >= 3 && segments[1] == "d" {
+			link = gdriveLink{kind: gdriveKindDocs, docType: segments[0], id: segments[2]}
+		}
+	case host == "drive.google.com":
+		switch {
+		case len(segments) >= 3 && segments[0] == "file" && segments[1] == "d":
+			link = gdriveLink{kind: gdriveKindFile, id: segments[2]}
+		case len(segments) >= 3 && segments[0] == "drive" && segments[len(segments)-2] == "folders":
+			link = gdriveLink{kind: gdriveKindFolder, id: segments[len(segments)-1]}
+		case len(segments) == 1 && (segments[0] == "open" || segments[0] == "uc"):
+			link = gdriveLink{kind: gdriveKindFile, id: parsedURL.Query().Get("id")}
+		}
+	default:
+		return gdriveLink{}, fmt.Errorf("不是Google Drive分享链接")
+	}
+
+	if !gdriveIDRegex.MatchString(link.id) {
+		return gdriveLink{}, fmt.Errorf("无法寻找文件ID")
+	}
+	return link, nil
+}
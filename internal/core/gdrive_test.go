@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"share-sniffer/internal/utils"
+)
+
+func TestGDriveChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/file/d/1FileOk_abcdefgh/view", "/file/d/1FileQuota_abcdef/view":
+			w.Write([]byte(`<html><head><meta property="og:title" content="report &amp; data.pdf"><title>report &amp; data.pdf - Google Drive</title></head></html>`))
+		case "/drive/folders/1FolderOk_abcdef":
+			w.Write([]byte(`<html><head><title>Team Photos – Google Drive</title></head></html>`))
+		case "/document/d/1DocOk_abcdefghi/edit":
+			w.Write([]byte(`<html><head><title>Meeting notes - Google Docs</title></head></html>`))
+		case "/file/d/1FilePrivate_abcd/view":
+			http.Redirect(w, r, "https://accounts.google.com/ServiceLogin?continue="+r.URL.String(), http.StatusFound)
+		case "/file/d/1FileAsk_abcdefgh/view":
+			w.Write([]byte(`<html><head><title>Google Drive</title></head><body>You need access. Request access, or switch to an account with access.</body></html>`))
+		case "/uc":
+			if r.URL.Query().Get("id") == "1FileQuota_abcdef" {
+				w.Write([]byte(`<html><body>Sorry, you can't view or download this file at this time. Too many users have viewed or downloaded this file recently.</body></html>`))
+				return
+			}
+			http.Redirect(w, r, "https://drive.usercontent.google.com/download?id="+r.URL.Query().Get("id"), http.StatusSeeOther)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	driveBase, docsBase := gdriveBase, gdocsBase
+	gdriveBase, gdocsBase = server.URL, server.URL
+	defer func() { gdriveBase, gdocsBase = driveBase, docsBase }()
+
+	checker := &GDriveChecker{}
+	tests := []struct {
+		url        string
+		wantError  utils.ErrorType
+		wantReason string
+		wantName   string
+	}{
+		{"https://drive.google.com/file/d/1FileOk_abcdefgh/view?usp=sharing", utils.Valid, utils.ReasonOK, "report & data.pdf"},
+		{"https://drive.google.com/open?id=1FileOk_abcdefgh", utils.Valid, utils.ReasonOK, "report & data.pdf"},
+		{"https://drive.google.com/drive/folders/1FolderOk_abcdef?usp=sharing", utils.Valid, utils.ReasonOK, "Team Photos"},
+		{"https://drive.google.com/drive/u/0/folders/1FolderOk_abcdef", utils.Valid, utils.ReasonOK, "Team Photos"},
+		{"https://docs.google.com/document/d/1DocOk_abcdefghi/edit?usp=sharing", utils.Valid, utils.ReasonOK, "Meeting notes"},
+		{"https://drive.google.com/file/d/1FileGone_abcdefg/view", utils.Invalid, utils.ReasonShareNotFound, ""},
+		{"https://drive.google.com/file/d/1FilePrivate_abcd/view", utils.LoginRequired, utils.ReasonLoginRequired, ""},
+		{"https://drive.google.com/file/d/1FileAsk_abcdefgh/view", utils.LoginRequired, utils.ReasonLoginRequired, ""},
+		{"https://drive.google.com/file/d/1FileQuota_abcdef/view", utils.RateLimited, utils.ReasonRateLimited, ""},
+		{"https://drive.google.com/drive/my-drive", utils.Malformed, utils.ReasonMalformedURL, ""},
+	}
+	for _, tt := range tests {
+		got := checker.Check(context.Background(), tt.url)
+		if got.Error != tt.wantError || got.Reason != tt.wantReason || got.Data.Name != tt.wantName {
+			t.Errorf("Check(%q) = %d %s %q %q, want %d %s %q", tt.url, got.Error, got.Reason, got.Msg, got.Data.Name, tt.wantError, tt.wantReason, tt.wantName)
+		}
+	}
+
+	got := checker.Check(context.Background(), "https://drive.google.com/drive/folders/1FolderOk_abcdef")
+	if meta := got.Data.Meta; meta == nil || len(meta.Entries) != 1 || !meta.Entries[0].IsDir {
+		t.Errorf("Check(folder) meta = %+v", meta)
+	}
+}
+
+func TestExtractParamsGDrive(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    gdriveLink
+		wantErr bool
+	}{
+		{"https://drive.google.com/file/d/1AbCdEfGhIjK/view", gdriveLink{kind: gdriveKindFile, id: "1AbCdEfGhIjK"}, false},
+		{"https://drive.google.com/uc?id=1AbCdEfGhIjK&export=download", gdriveLink{kind: gdriveKindFile, id: "1AbCdEfGhIjK"}, false},
+		{"https://drive.google.com/drive/folders/1AbCdEfGhIjK", gdriveLink{kind: gdriveKindFolder, id: "1AbCdEfGhIjK"}, false},
+		{"https://docs.google.com/spreadsheets/d/1AbCdEfGhIjK/edit#gid=0", gdriveLink{kind: gdriveKindDocs, docType: "spreadsheets", id: "1AbCdEfGhIjK"}, false},
+		{"https://drive.google.com/open?id=", gdriveLink{}, true},
+		{"https://docs.google.com/forms/u/0/", gdriveLink{}, true},
+	}
+	for _, tt := range tests {
+		got, err := extractParamsGDrive(tt.url)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("extractParamsGDrive(%q) = %+v, %v, want %+v", tt.url, got, err, tt.want)
+		}
+	}
+
+	if got, id := (&GDriveChecker{}).Normalize("https://drive.google.com/open?id=1AbCdEfGhIjK"); got != "https://drive.google.com/file/d/1AbCdEfGhIjK/view" || id != "1AbCdEfGhIjK" {
+		t.Errorf("Normalize() = %q, %q", got, id)
+	}
+}
@@ -0,0 +1,346 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// lanzou.go 实现了蓝奏云链接检查器
+// 蓝奏云没有公开的分享接口，先请求分享页面判断文件已删除、单文件、带密码的文件和文件夹，
+// 带密码的文件和文件夹再通过页面中的ajax接口校验密码并获取文件信息
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/errors"
+	apphttp "share-sniffer/internal/http"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/parser"
+	"share-sniffer/internal/utils"
+)
+
+// LanzouChecker 蓝奏云链接检查器
+// 蓝奏云有大量域名和用户自定义的子域名，前缀使用 *.lanzoux.com 形式匹配所有子域名
+type LanzouChecker struct{}
+
+// Check 实现LinkChecker接口
+func (l *LanzouChecker) Check(ctx context.Context, urlStr string) utils.Result {
+	return l.checkLanzou(ctx, urlStr)
+}
+
+// GetPrefix 实现LinkChecker接口
+func (l *LanzouChecker) GetPrefix() []string {
+	return config.GetSupportedLanzou()
+}
+
+// ShareID 实现ShareIdentifier接口
+func (l *LanzouChecker) ShareID(urlStr string) string {
+	shareID, _, _ := extractParamsLanzou(urlStr)
+	return shareID
+}
+
+// Normalize 实现Normalizer接口，各域名下的分享ID通用，统一为同一个域名
+func (l *LanzouChecker) Normalize(urlStr string) (string, string) {
+	shareID := l.ShareID(urlStr)
+	if shareID == "" {
+		return "", ""
+	}
+	return lanzouCanonicalPrefix + shareID, shareID
+}
+
+// lanzouCanonicalPrefix 蓝奏云规范链接前缀
+const lanzouCanonicalPrefix = "https://www.lanzoux.com/"
+
+var (
+	// lanzouTitleRegex 页面标题，文件和文件夹页面的标题为 "名称 - 蓝奏云"
+	lanzouTitleRegex = regexp.MustCompile(`<title>\s*([^<]*?)\s*(?:-\s*蓝奏云)?\s*</title>`)
+	// lanzouFileNameRegex 单文件页面中的文件名
+	lanzouFileNameRegex = regexp.MustCompile(`(?:class="n_box_3fn"[^>]*>|<div style="font-size: 30px;[^"]*">)\s*([^<]+?)\s*<`)
+	// lanzouSizeRegex 文件页面中的文件大小
+	lanzouSizeRegex = regexp.MustCompile(`(?:class="n_filesize"[^>]*>\s*大小：|文件大小：</span>)\s*([^<]+?)\s*<`)
+	// lanzouFileAjaxRegex 带密码的文件校验密码的接口
+	lanzouFileAjaxRegex = regexp.MustCompile(`/ajaxm\.php\?file=(\d+)`)
+	// lanzouSignRegex 带密码的文件校验密码时使用的签名，可能直接写在请求参数中，也可能通过变量传入
+	lanzouSignRegex = regexp.MustCompile(`(?:var\s+skdklds\s*=\s*|'sign'\s*:\s*)'([^']+)'`)
+	// lanzouFolderAjaxRegex 文件夹列表接口
+	lanzouFolderAjaxRegex = regexp.MustCompile(`/filemoreajax\.php(?:\?file=(\d+))?`)
+	// lanzouFolderParamRegex 文件夹列表接口的参数，值可能是字面量，也可能是页面中的变量名
+	lanzouFolderParamRegex = regexp.MustCompile(`'(fid|uid|t|k)'\s*:\s*'?([\w]+)'?`)
+)
+
+// lanzouFolderResp 文件夹列表接口响应结构
+type lanzouFolderResp struct {
+	Zt   int             `json:"zt"`
+	Info string          `json:"info"`
+	Text json.RawMessage `json:"text"`
+}
+
+// lanzouFileResp 带密码的文件校验密码接口响应结构
+type lanzouFileResp struct {
+	Zt  int    `json:"zt"`
+	Inf string `json:"inf"`
+}
+
+// lanzouFolderFile 文件夹中的一个文件
+type lanzouFolderFile struct {
+	NameAll string `json:"name_all"`
+	Size    string `json:"size"`
+}
+
+func (l *LanzouChecker) checkLanzou(ctx context.Context, urlStr string) utils.Result {
+	logger.Debug("LanzouChecker:开始检测蓝奏云链接: %s", urlStr)
+
+	shareID, pageURL, err := extractParamsLanzou(urlStr)
+	if err != nil {
+		logger.Info("LanzouChecker:extractParamsLanzou,%s,错误: %v\n", urlStr, err)
+		return utils.ErrorMalformed(urlStr, "链接格式无效")
+	}
+
+	requestStart := time.Now()
+	result, err := checkLanzouPage(ctx, pageURL, parser.PasswordFromURL(urlStr))
+	logger.Debug("LanzouChecker:检测完成: %s, 分享ID: %s, 耗时: %dms", urlStr, shareID, time.Since(requestStart).Milliseconds())
+	if err != nil {
+		if errors.IsStatusCodeError(err) {
+			return utils.ErrorInvalid("分享链接失效").WithReason(utils.ReasonShareNotFound)
+		}
+		return errorResult(err)
+	}
+	return result
+}
+
+// checkLanzouPage 请求分享页面，根据页面类型判断分享状态
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - pageURL: 分享页面地址，不带查询参数
+// - password: 分享密码，没有时为空
+func checkLanzouPage(ctx context.Context, pageURL, password string) (utils.Result, error) {
+	page, err := lanzouRequest(ctx, "GET", pageURL, pageURL, nil)
+	if err != nil {
+		return utils.Result{}, err
+	}
+
+	switch {
+	case strings.Contains(page, "acw_sc__v2"):
+		// 触发了网站的反爬验证，需要执行页面脚本才能继续访问
+		return utils.ErrorRateLimited("触发了网盘的访问验证"), nil
+	case strings.Contains(page, "文件取消分享了"):
+		return utils.ErrorInvalid("文件取消分享了").WithReason(utils.ReasonShareCancelled), nil
+	case strings.Contains(page, "文件不存在") || strings.Contains(page, "访问地址错误"):
+		return utils.ErrorInvalid("文件不存在或已删除").WithReason(utils.ReasonShareNotFound), nil
+	case strings.Contains(page, "文件违规") || strings.Contains(page, "涉嫌违规"):
+		return utils.ErrorBanned("文件涉嫌违规，无法访问"), nil
+	case lanzouFolderAjaxRegex.MatchString(page):
+		return checkLanzouFolder(ctx, pageURL, page, password)
+	case lanzouFileAjaxRegex.MatchString(page) && strings.Contains(page, `id="pwd"`):
+		return checkLanzouPasswordFile(ctx, pageURL, page, password)
+	}
+
+	name := lanzouMatch(lanzouFileNameRegex, page)
+	if name == "" {
+		name = lanzouMatch(lanzouTitleRegex, page)
+	}
+	if name == "" || name == "蓝奏云" {
+		return utils.ErrorInvalid("无法获取分享信息").WithReason(utils.ReasonPageError), nil
+	}
+	size := parseHumanSize(lanzouMatch(lanzouSizeRegex, page))
+	return utils.ErrorValid(name).WithMeta(lanzouFileMeta(name, size)), nil
+}
+
+// checkLanzouPasswordFile 通过页面中的ajax接口校验带密码的文件
+func checkLanzouPasswordFile(ctx context.Context, pageURL, page, password string) (utils.Result, error) {
+	if password == "" {
+		return utils.ErrorNeedPassword("请输入密码"), nil
+	}
+
+	fileID := lanzouMatch(lanzouFileAjaxRegex, page)
+	sign := lanzouMatch(lanzouSignRegex, page)
+	if sign == "" {
+		return utils.Result{}, errors.NewParseError("未找到文件签名", nil)
+	}
+
+	form := url.Values{}
+	form.Set("action", "downprocess")
+	form.Set("sign", sign)
+	form.Set("p", password)
+	body, err := lanzouRequest(ctx, "POST", lanzouOrigin(pageURL)+"/ajaxm.php?file="+fileID, pageURL, form)
+	if err != nil {
+		return utils.Result{}, err
+	}
+
+	var response lanzouFileResp
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		return utils.Result{}, errors.NewParseError("解析JSON失败", err)
+	}
+	if response.Zt != 1 {
+		return utils.ErrorNeedPassword(cmp.Or(response.Inf, "密码不正确")).WithReason(utils.ReasonWrongPasscode), nil
+	}
+
+	size := parseHumanSize(lanzouMatch(lanzouSizeRegex, page))
+	return utils.ErrorValid(response.Inf).WithMeta(lanzouFileMeta(response.Inf, size)), nil
+}
+
+// checkLanzouFolder 通过页面中的列表接口获取文件夹的第一页文件
+func checkLanzouFolder(ctx context.Context, pageURL, page, password string) (utils.Result, error) {
+	if password == "" && (strings.Contains(page, `id="pwdload"`) || strings.Contains(page, `id="pwd"`)) {
+		return utils.ErrorNeedPassword("请输入密码"), nil
+	}
+
+	form := url.Values{}
+	form.Set("lx", "2")
+	form.Set("pg", "1")
+	form.Set("rep", "0")
+	form.Set("up", "1")
+	form.Set("ls", "1")
+	form.Set("pwd", password)
+	for _, m := range lanzouFolderParamRegex.FindAllStringSubmatch(page, -1) {
+		if form.Has(m[1]) {
+			continue
+		}
+		value := m[2]
+		// 值为页面中的变量名时读取变量的值
+		if varRegex, err := regexp.Compile(`var\s+` + regexp.QuoteMeta(value) + `\s*=\s*'([^']*)'`); err == nil {
+			if v := lanzouMatch(varRegex, page); v != "" {
+				value = v
+			}
+		}
+		form.Set(m[1], value)
+	}
+	if form.Get("fid") == "" {
+		return utils.Result{}, errors.NewParseError("未找到文件夹ID", nil)
+	}
+
+	apiURL := lanzouOrigin(pageURL) + "/filemoreajax.php"
+	if fileID := lanzouMatch(lanzouFolderAjaxRegex, page); fileID != "" {
+		apiURL += "?file=" + fileID
+	}
+	body, err := lanzouRequest(ctx, "POST", apiURL, pageURL, form)
+	if err != nil {
+		return utils.Result{}, err
+	}
+
+	var response lanzouFolderResp
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		return utils.Result{}, errors.NewParseError("解析JSON失败", err)
+	}
+
+	name := lanzouMatch(lanzouTitleRegex, page)
+	switch response.Zt {
+	case 1:
+		var files []lanzouFolderFile
+		if err := json.Unmarshal(response.Text, &files); err != nil {
+			return utils.Result{}, errors.NewParseError("解析文件列表失败", err)
+		}
+		meta := &utils.Metadata{FileCount: len(files)}
+		for _, f := range files {
+			size := parseHumanSize(f.Size)
+			meta.TotalSize += size
+			meta.AddEntry(f.NameAll, size, false)
+		}
+		if name == "" && len(files) > 0 {
+			name = files[0].NameAll
+		}
+		return utils.ErrorValid(name).WithMeta(meta), nil
+	case 2:
+		// 第一页就没有文件，文件夹为空
+		return utils.ErrorInvalid("文件夹中没有文件").WithReason(utils.ReasonEmptyShare), nil
+	case 3:
+		return utils.ErrorNeedPassword(cmp.Or(response.Info, "密码不正确")).WithReason(utils.ReasonWrongPasscode), nil
+	}
+	return messageResult(response.Info, "文件夹无法访问"), nil
+}
+
+// lanzouRequest 请求蓝奏云页面或接口，form不为空时以表单方式POST
+func lanzouRequest(ctx context.Context, method, apiURL, referer string, form url.Values) (string, error) {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := apphttp.NewRequestWithContext(ctx, method, apiURL, body)
+	if err != nil {
+		return "", err
+	}
+	if form != nil {
+		req.Header.Set("content-type", "application/x-www-form-urlencoded")
+		req.Header.Set("accept", "application/json, text/javascript, */*")
+	} else {
+		req.Header.Set("accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	}
+	req.Header.Set("referer", referer)
+
+	resp, err := apphttp.DoWithRetry(ctx, req, 0)
+	if err != nil {
+		return "", err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errors.NewStatusCodeError("链接已失效")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("状态码: %d", resp.StatusCode)
+	}
+	return string(data), nil
+}
+
+// lanzouFileMeta 生成单文件分享的元数据
+func lanzouFileMeta(name string, size int64) *utils.Metadata {
+	meta := &utils.Metadata{FileCount: 1, TotalSize: size}
+	meta.AddEntry(name, size, false)
+	return meta
+}
+
+// lanzouMatch 返回正则表达式第一个非空分组，HTML实体已解码
+func lanzouMatch(re *regexp.Regexp, s string) string {
+	m := re.FindStringSubmatch(s)
+	for _, group := range m[min(1, len(m)):] {
+		if group != "" {
+			return strings.TrimSpace(html.UnescapeString(group))
+		}
+	}
+	return ""
+}
+
+// lanzouOrigin 返回页面地址的协议和主机部分
+func lanzouOrigin(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// extractParamsLanzou 从蓝奏云链接中提取分享ID和不带查询参数的分享页面地址
+// 文件链接形如 /iAbc123，文件夹链接形如 /b0abc123 或 /b123456，手机版页面带有 /tp/ 前缀
+func extractParamsLanzou(rawURL string) (shareID, pageURL string, err error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+	if parsedURL.Host == "" {
+		return "", "", fmt.Errorf("缺少主机")
+	}
+
+	shareID = lastPathSegment(rawURL)
+	if shareID == "" || strings.ContainsAny(shareID, ".") {
+		return "", "", fmt.Errorf("无法寻找分享ID")
+	}
+
+	scheme := parsedURL.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	return shareID, scheme + "://" + parsedURL.Host + "/" + shareID, nil
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"share-sniffer/internal/utils"
+)
+
+// 分享页面的样例，只保留检测时用到的部分
+var lanzouPages = map[string]string{
+	"/iFile01": `<html><head><title>安装包.apk - 蓝奏云</title></head><body>
+		<div class="n_box_3fn" id="filenajax">安装包.apk</div>
+		<div class="n_filesize">大小：12.5 M</div></body></html>`,
+	"/iFile02": `<html><head><title>说明.zip - 蓝奏云</title></head><body>
+		<div style="font-size: 30px;text-align: center;padding: 56px 0px 20px 0px;">说明.zip</div>
+		<table><tr><td><span class="p7">文件大小：</span>356 K<br></td></tr></table></body></html>`,
+	"/iLock01": `<html><head><title>文件</title></head><body>
+		<input type="text" name="pwd" class="passwdinput" id="pwd" value="" placeholder="输入密码">
+		<span class="n_filesize">大小：1.2 G</span>
+		<script>var skdklds = 'sg_lock01';
+		$.ajax({type : 'post', url : '/ajaxm.php?file=1001', data : { 'action':'downprocess','sign':skdklds,'p':pwd }});</script>
+		</body></html>`,
+	"/b0Dir01": `<html><head><title>素材合集</title></head><body>
+		<script>var ib4l8s = '1700000000'; var _h0k4hu = 'k_dir01';
+		$.ajax({type : 'post', url : '/filemoreajax.php?file=2001',
+			data : { 'lx':2, 'fid':2001, 'uid':'3001', 'pg':pgs, 'rep':'0', 't':ib4l8s, 'k':_h0k4hu, 'up':1, 'vip':'0', 'webfoldersign':'' }});</script>
+		</body></html>`,
+	"/b0Dir02": `<html><head><title>私密文件夹</title></head><body>
+		<input type="text" name="pwd" id="pwd" value="">
+		<script>$.ajax({type : 'post', url : '/filemoreajax.php?file=2002',
+			data : { 'lx':2, 'fid':2002, 'uid':'3002', 'pg':pgs, 'rep':'0', 't':'1700000000', 'k':'k_dir02', 'up':1, 'ls':1, 'pwd':pwd }});</script>
+		</body></html>`,
+	"/b0Dir03": `<html><head><title>空文件夹</title></head><body>
+		<script>$.ajax({type : 'post', url : '/filemoreajax.php?file=2003',
+			data : { 'lx':2, 'fid':2003, 'uid':'3003', 'pg':pgs, 'rep':'0', 't':'1700000000', 'k':'k_dir03', 'up':1 }});</script>
+		</body></html>`,
+	"/iGone01":   `<html><head><title>文件</title></head><body><div class="off"><div class="off0"><div class="off1"></div></div>文件取消分享了</div></body></html>`,
+	"/iGone02":   `<html><head><title>文件</title></head><body><div class="off">来晚啦...文件不存在或已删除</div></body></html>`,
+	"/iVerify01": `<html><script>var arg1='3E40CB1B';function setCookie(name,value){}; document.cookie="acw_sc__v2="+x;</script></html>`,
+}
+
+func TestLanzouChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ajaxm.php":
+			r.ParseForm()
+			if r.URL.Query().Get("file") != "1001" || r.PostForm.Get("sign") != "sg_lock01" {
+				w.Write([]byte(`{"zt":0,"inf":"已超时，请刷新"}`))
+				return
+			}
+			if r.PostForm.Get("p") != "ab12" {
+				w.Write([]byte(`{"zt":0,"inf":"密码不正确"}`))
+				return
+			}
+			w.Write([]byte(`{"zt":1,"dom":"https://developer.lanzoug.com","url":"?abc","inf":"大文件.iso"}`))
+		case "/filemoreajax.php":
+			r.ParseForm()
+			switch r.PostForm.Get("fid") {
+			case "2001":
+				if r.PostForm.Get("t") != "1700000000" || r.PostForm.Get("k") != "k_dir01" {
+					w.Write([]byte(`{"zt":4,"info":"请刷新，重试","text":0}`))
+					return
+				}
+				w.Write([]byte(`{"zt":1,"info":"sucess","text":[{"icon":"zip","name_all":"图片.zip","size":"1.5 M","time":"昨天"},{"icon":"txt","name_all":"说明.txt","size":"2.0 K","time":"昨天"}]}`))
+			case "2002":
+				if r.PostForm.Get("pwd") != "ab12" {
+					w.Write([]byte(`{"zt":3,"info":"密码不正确","text":null}`))
+					return
+				}
+				w.Write([]byte(`{"zt":1,"info":"sucess","text":[{"name_all":"私密.pdf","size":"100 K"}]}`))
+			default:
+				w.Write([]byte(`{"zt":2,"info":"没有了","text":null}`))
+			}
+		default:
+			page, ok := lanzouPages[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(page))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path       string
+		password   string
+		wantError  utils.ErrorType
+		wantReason string
+		wantName   string
+	}{
+		{"/iFile01", "", utils.Valid, utils.ReasonOK, "安装包.apk"},
+		{"/iFile02", "", utils.Valid, utils.ReasonOK, "说明.zip"},
+		{"/iLock01", "", utils.NeedPassword, utils.ReasonPasscodeRequired, ""},
+		{"/iLock01", "0000", utils.NeedPassword, utils.ReasonWrongPasscode, ""},
+		{"/iLock01", "ab12", utils.Valid, utils.ReasonOK, "大文件.iso"},
+		{"/b0Dir01", "", utils.Valid, utils.ReasonOK, "素材合集"},
+		{"/b0Dir02", "", utils.NeedPassword, utils.ReasonPasscodeRequired, ""},
+		{"/b0Dir02", "0000", utils.NeedPassword, utils.ReasonWrongPasscode, ""},
+		{"/b0Dir02", "ab12", utils.Valid, utils.ReasonOK, "私密文件夹"},
+		{"/b0Dir03", "", utils.Invalid, utils.ReasonEmptyShare, ""},
+		{"/iGone01", "", utils.Invalid, utils.ReasonShareCancelled, ""},
+		{"/iGone02", "", utils.Invalid, utils.ReasonShareNotFound, ""},
+		{"/iVerify01", "", utils.RateLimited, utils.ReasonRateLimited, ""},
+	}
+	for _, tt := range tests {
+		got, err := checkLanzouPage(context.Background(), server.URL+tt.path, tt.password)
+		if err != nil {
+			t.Errorf("checkLanzouPage(%s, %q) error = %v", tt.path, tt.password, err)
+			continue
+		}
+		if got.Error != tt.wantError || got.Reason != tt.wantReason || got.Data.Name != tt.wantName {
+			t.Errorf("checkLanzouPage(%s, %q) = %d %s %q, want %d %s %q", tt.path, tt.password, got.Error, got.Reason, got.Data.Name, tt.wantError, tt.wantReason, tt.wantName)
+		}
+	}
+
+	got, _ := checkLanzouPage(context.Background(), server.URL+"/iFile01", "")
+	if meta := got.Data.Meta; meta == nil || meta.FileCount != 1 || meta.TotalSize != 12.5*(1<<20) {
+		t.Errorf("checkLanzouPage(iFile01) meta = %+v", meta)
+	}
+	got, _ = checkLanzouPage(context.Background(), server.URL+"/b0Dir01", "")
+	if meta := got.Data.Meta; meta == nil || meta.FileCount != 2 || meta.TotalSize != 1.5*(1<<20)+2*(1<<10) || len(meta.Entries) != 2 {
+		t.Errorf("checkLanzouPage(b0Dir01) meta = %+v", meta)
+	}
+
+	// 页面不存在时返回状态码错误，由检查器视为失效
+	if _, err := checkLanzouPage(context.Background(), server.URL+"/iMissing", ""); err == nil {
+		t.Errorf("checkLanzouPage(iMissing) 预期状态码错误")
+	}
+}
+
+func TestExtractParamsLanzou(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantID   string
+		wantPage string
+		wantErr  bool
+	}{
+		{"https://wwi.lanzoux.com/iAbC123", "iAbC123", "https://wwi.lanzoux.com/iAbC123", false},
+		{"https://user1.lanzouw.com/b0abc123?pwd=ab12", "b0abc123", "https://user1.lanzouw.com/b0abc123", false},
+		{"https://www.lanzoui.com/tp/iAbC123/", "iAbC123", "https://www.lanzoui.com/iAbC123", false},
+		{"https://www.lanzoux.com/", "", "", true},
+		{"https://www.lanzoux.com/index.html", "", "", true},
+	}
+	for _, tt := range tests {
+		id, page, err := extractParamsLanzou(tt.url)
+		if (err != nil) != tt.wantErr || id != tt.wantID || page != tt.wantPage {
+			t.Errorf("extractParamsLanzou(%q) = %q, %q, %v, want %q, %q", tt.url, id, page, err, tt.wantID, tt.wantPage)
+		}
+	}
+
+	if got, id := (&LanzouChecker{}).Normalize("https://abc.lanzouo.com/iAbC123?pwd=ab12"); got != "https://www.lanzoux.com/iAbC123" || id != "iAbC123" {
+		t.Errorf("Normalize() = %q, %q", got, id)
+	}
+}
+
+func TestParseHumanSize(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int64
+	}{
+		{"1.2 M", 1258291},
+		{"356 KB", 356 << 10},
+		{"2.1GB", 2254857830},
+		{"1,024 B", 1024},
+		{"512", 512},
+		{"", 0},
+		{"未知", 0},
+	}
+	for _, tt := range tests {
+		if got := parseHumanSize(tt.s); got != tt.want {
+			t.Errorf("parseHumanSize(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
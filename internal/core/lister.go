@@ -0,0 +1,283 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// lister.go 实现了分享目录树的列举功能
+// 提供了可选的Lister接口、按页递归遍历目录的treeWalker以及统一入口List
+package core
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/errors"
+	apphttp "share-sniffer/internal/http"
+	"share-sniffer/internal/parser"
+	"share-sniffer/internal/utils"
+)
+
+const (
+	// DefaultListDepth 默认的最大遍历深度（顶层为第1层）
+	DefaultListDepth = 3
+
+	// DefaultListEntries 默认的最大条目数
+	DefaultListEntries = 500
+)
+
+// Lister 可选的分享目录树列举接口
+// 检查器实现该接口后，可以通过List列举分享中的全部文件
+type Lister interface {
+	// List 列举分享的目录树
+	//
+	// 参数:
+	// - ctx: 上下文，用于控制超时和取消
+	// - urlStr: 规范化后的分享链接
+	// - opts: 遍历深度和条目数限制
+	//
+	// 返回值:
+	// - []*ShareNode: 顶层条目
+	// - bool: 是否因达到限制而被截断
+	// - error: 列举失败时返回错误，分享本身失效等情况返回 *ResultError
+	List(ctx context.Context, urlStr string, opts ListOptions) ([]*ShareNode, bool, error)
+}
+
+// ListOptions 列举选项
+type ListOptions struct {
+	MaxDepth   int // 最大遍历深度，顶层为第1层，<=0 时使用默认值
+	MaxEntries int // 最大条目数，<=0 时使用默认值
+}
+
+// withDefaults 返回补全默认值后的列举选项
+func (o ListOptions) withDefaults() ListOptions {
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = DefaultListDepth
+	}
+	if o.MaxEntries <= 0 {
+		o.MaxEntries = DefaultListEntries
+	}
+	return o
+}
+
+// ShareNode 分享目录树中的一个文件或文件夹
+type ShareNode struct {
+	Name     string       `json:"name"`               // 文件名
+	Size     int64        `json:"size"`               // 大小（字节），文件夹为0
+	IsDir    bool         `json:"is_dir"`             // 是否为文件夹
+	Children []*ShareNode `json:"children,omitempty"` // 子条目
+}
+
+// ListResult 列举结果
+type ListResult struct {
+	Schema      int             `json:"schema"`                 // 结果结构版本
+	Error       utils.ErrorType `json:"error"`                  // 错误码，与检测结果一致
+	Reason      string          `json:"reason"`                 // 原因码
+	Msg         string          `json:"msg"`                    // 错误信息
+	URL         string          `json:"url"`                    // 列举的URL
+	ResolvedURL string          `json:"resolved_url,omitempty"` // 短链接或跳转链接展开后的链接
+	Provider    string          `json:"provider"`               // 网盘标识
+	ShareID     string          `json:"share_id"`               // 分享ID
+	Count       int             `json:"count"`                  // 已列举的条目数
+	Truncated   bool            `json:"truncated"`              // 是否因达到深度或条目数限制而被截断
+	Elapsed     int64           `json:"elapsed"`                // 耗时（毫秒）
+	Entries     []*ShareNode    `json:"entries"`                // 顶层条目
+}
+
+// ResultError 携带检测结果的错误
+// 列举时发现分享失效、需要提取码等情况时返回，List会把其中的状态原样输出
+type ResultError struct {
+	Result utils.Result
+}
+
+// Error 实现error接口
+func (e *ResultError) Error() string {
+	return e.Result.Msg
+}
+
+// resultError 使用检测结果创建错误
+func resultError(result utils.Result) error {
+	return &ResultError{Result: result}
+}
+
+// List 列举分享的目录树，提供统一的列举入口
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 用户输入的链接字符串，也可以是包含链接和提取码的分享文本
+// - opts: 遍历深度和条目数限制
+//
+// 返回值:
+// - ListResult: 列举结果
+func List(ctx context.Context, urlStr string, opts ListOptions) ListResult {
+	result := ListResult{Schema: utils.SchemaVersion, URL: urlStr}
+
+	if link, ok := parser.ParseLine(urlStr); ok {
+		urlStr = link.URL
+		result.URL = urlStr
+	}
+
+	r, normalizedURL := routes.match(urlStr)
+	if r == nil && config.ResolverEnabled() {
+		var err error
+		if r, normalizedURL, result.ResolvedURL, err = resolveInput(ctx, urlStr); err != nil {
+			return result.withResult(errorResult(err))
+		}
+	}
+	if r == nil {
+		return result.withResult(utils.ErrorMalformed(urlStr, "链接尚未支持").WithReason(utils.ReasonUnsupported))
+	}
+	result.Provider = r.provider
+	if identifier, ok := r.checker.(ShareIdentifier); ok {
+		result.ShareID = identifier.ShareID(normalizedURL)
+	}
+
+	lister, ok := r.checker.(Lister)
+	if !ok {
+		return result.withResult(utils.ErrorMalformed(urlStr, "该网盘暂不支持列举").WithReason(utils.ReasonUnsupported))
+	}
+
+	startTime := time.Now()
+	entries, truncated, err := lister.List(apphttp.WithProvider(ctx, r.provider), normalizedURL, opts.withDefaults())
+	result.Elapsed = time.Since(startTime).Milliseconds()
+	if err != nil {
+		return result.withResult(errorResult(err))
+	}
+
+	result.Reason = utils.ReasonOK
+	result.Msg = utils.ErrorToMsg(utils.Valid)
+	result.Entries = entries
+	result.Count = countNodes(entries)
+	result.Truncated = truncated
+	return result
+}
+
+// withResult 使用检测结果填充列举结果的状态
+func (r ListResult) withResult(result utils.Result) ListResult {
+	r.Error = result.Error
+	r.Reason = result.Reason
+	if r.Reason == "" {
+		r.Reason = utils.DefaultReason(result.Error)
+	}
+	r.Msg = result.Msg
+	return r
+}
+
+// errorResult 把请求过程中的错误转换为检测结果
+func errorResult(err error) utils.Result {
+	var resErr *ResultError
+	switch {
+	case stderrors.As(err, &resErr):
+		return resErr.Result
+	case errors.IsTimeoutError(err), stderrors.Is(err, context.DeadlineExceeded):
+		return utils.ErrorTimeout()
+	case errors.IsRateLimitError(err):
+		return utils.ErrorRateLimited("请求过于频繁")
+	case errors.IsUnavailableError(err):
+		return utils.ErrorUnavailable("网盘暂不可用")
+	}
+	return utils.ErrorFatal("请求失败").WithReason(requestErrorReason(err))
+}
+
+// countNodes 统计目录树中的条目数
+func countNodes(nodes []*ShareNode) int {
+	count := len(nodes)
+	for _, n := range nodes {
+		count += countNodes(n.Children)
+	}
+	return count
+}
+
+// FormatTree 把目录树格式化为缩进的文本，文件夹以 / 结尾
+func FormatTree(nodes []*ShareNode) string {
+	var b strings.Builder
+	writeTree(&b, nodes, 0)
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// writeTree 递归写入目录树
+func writeTree(b *strings.Builder, nodes []*ShareNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, n := range nodes {
+		if n.IsDir {
+			fmt.Fprintf(b, "%s%s/\n", indent, n.Name)
+			writeTree(b, n.Children, depth+1)
+		} else {
+			fmt.Fprintf(b, "%s%s (%s)\n", indent, n.Name, utils.FormatSize(n.Size))
+		}
+	}
+}
+
+// listEntry 网盘接口返回的一个目录条目
+type listEntry struct {
+	id    string // 文件夹ID或路径，用于请求子目录
+	name  string
+	size  int64
+	isDir bool
+}
+
+// pageFunc 获取目录的一页条目
+//
+// 参数:
+// - dirID: 目录ID，顶层目录为空字符串
+// - page: 页码，从1开始
+//
+// 返回值:
+// - []listEntry: 当前页的条目
+// - bool: 是否还有下一页
+type pageFunc func(ctx context.Context, dirID string, page int) ([]listEntry, bool, error)
+
+// treeWalker 按页递归遍历目录树，并在达到深度或条目数限制时停止
+type treeWalker struct {
+	fetch     pageFunc
+	opts      ListOptions
+	count     int
+	truncated bool
+}
+
+// walkTree 从顶层目录开始遍历目录树
+func walkTree(ctx context.Context, fetch pageFunc, opts ListOptions) ([]*ShareNode, bool, error) {
+	w := &treeWalker{fetch: fetch, opts: opts.withDefaults()}
+	nodes, err := w.walk(ctx, "", 1)
+	return nodes, w.truncated, err
+}
+
+// walk 遍历一个目录，depth为当前目录条目所在的层数
+func (w *treeWalker) walk(ctx context.Context, dirID string, depth int) ([]*ShareNode, error) {
+	var nodes []*ShareNode
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return nodes, err
+		}
+
+		entries, more, err := w.fetch(ctx, dirID, page)
+		if err != nil {
+			return nodes, err
+		}
+
+		for _, e := range entries {
+			if w.count >= w.opts.MaxEntries {
+				w.truncated = true
+				return nodes, nil
+			}
+			w.count++
+
+			node := &ShareNode{Name: e.name, Size: e.size, IsDir: e.isDir}
+			nodes = append(nodes, node)
+			if !e.isDir {
+				continue
+			}
+			if depth >= w.opts.MaxDepth {
+				w.truncated = true
+				continue
+			}
+			if node.Children, err = w.walk(ctx, e.id, depth+1); err != nil {
+				return nodes, err
+			}
+		}
+
+		if !more || len(entries) == 0 {
+			return nodes, nil
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// fakeTree 测试用目录树，键为目录ID，每页2个条目
+var fakeTree = map[string][]listEntry{
+	"":  {{id: "a", name: "a", isDir: true}, {name: "1.txt", size: 1}, {name: "2.txt", size: 2}},
+	"a": {{id: "b", name: "b", isDir: true}, {name: "3.txt", size: 3}},
+	"b": {{name: "4.txt", size: 4}},
+}
+
+func fakeFetch(ctx context.Context, dirID string, page int) ([]listEntry, bool, error) {
+	entries, ok := fakeTree[dirID]
+	if !ok {
+		return nil, false, fmt.Errorf("目录不存在: %s", dirID)
+	}
+	start := (page - 1) * 2
+	if start >= len(entries) {
+		return nil, false, nil
+	}
+	end := min(start+2, len(entries))
+	return entries[start:end], end < len(entries), nil
+}
+
+func TestWalkTree(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          ListOptions
+		wantCount     int
+		wantTruncated bool
+	}{
+		{name: "full", opts: ListOptions{MaxDepth: 10, MaxEntries: 100}, wantCount: 6, wantTruncated: false},
+		{name: "depth limit", opts: ListOptions{MaxDepth: 1, MaxEntries: 100}, wantCount: 3, wantTruncated: true},
+		{name: "entry limit", opts: ListOptions{MaxDepth: 10, MaxEntries: 4}, wantCount: 4, wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, truncated, err := walkTree(context.Background(), fakeFetch, tt.opts)
+			if err != nil {
+				t.Fatalf("walkTree() error = %v", err)
+			}
+			if got := countNodes(nodes); got != tt.wantCount {
+				t.Errorf("walkTree() count = %d, want %d\n%s", got, tt.wantCount, FormatTree(nodes))
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("walkTree() truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestFormatTree(t *testing.T) {
+	nodes, _, err := walkTree(context.Background(), fakeFetch, ListOptions{MaxDepth: 10, MaxEntries: 100})
+	if err != nil {
+		t.Fatalf("walkTree() error = %v", err)
+	}
+
+	want := "a/\n  b/\n    4.txt (4 B)\n  3.txt (3 B)\n1.txt (1 B)\n2.txt (2 B)"
+	if got := FormatTree(nodes); got != want {
+		t.Errorf("FormatTree() =\n%s\nwant\n%s", got, want)
+	}
+}
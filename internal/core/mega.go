@@ -0,0 +1,459 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// mega.go 实现了Mega链接检查器，作为策略模式的具体策略实现
+// 提供了MegaChecker结构体，实现了LinkChecker接口的Check和GetPrefix方法
+// 通过公开接口查询文件或文件夹是否存在及其大小，文件名经链接片段中的密钥解密得到
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/errors"
+	apphttp "share-sniffer/internal/http"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/utils"
+)
+
+// MegaChecker Mega链接检查器
+// 实现了LinkChecker接口，是策略模式的具体策略之一
+// 支持 /file/、/folder/、/embed/ 链接以及旧版的 #!句柄!密钥 和 #F!句柄!密钥 链接
+type MegaChecker struct{}
+
+// Check 实现LinkChecker接口的Check方法
+// 调用内部的checkMega方法执行具体的检查逻辑
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的Mega分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体
+func (m *MegaChecker) Check(ctx context.Context, urlStr string) utils.Result {
+	return m.checkMega(ctx, urlStr)
+}
+
+// GetPrefix 实现LinkChecker接口的GetPrefix方法
+// 返回Mega链接的前缀，用于在注册时识别
+//
+// 返回值:
+// - []string: Mega链接的前缀数组，从配置中获取
+func (m *MegaChecker) GetPrefix() []string {
+	return config.GetSupportedMega()
+}
+
+// ShareID 实现ShareIdentifier接口
+func (m *MegaChecker) ShareID(urlStr string) string {
+	link, _ := extractParamsMega(urlStr)
+	return link.handle
+}
+
+// SharePassword 实现PasswordReader接口，返回片段中的密钥
+// 同一句柄配上不同的密钥可能解出不同的结果，密钥需要参与缓存和重复检测的键
+func (m *MegaChecker) SharePassword(urlStr string) string {
+	link, _ := extractParamsMega(urlStr)
+	return link.key
+}
+
+// Normalize 实现Normalizer接口，旧版链接转换为新版链接，保留片段中的密钥
+func (m *MegaChecker) Normalize(urlStr string) (string, string) {
+	link, err := extractParamsMega(urlStr)
+	if err != nil || link.protected {
+		return "", ""
+	}
+	normalized := "https://mega.nz/" + link.kind + "/" + link.handle
+	if link.key != "" {
+		normalized += "#" + link.key
+	}
+	return normalized, link.handle
+}
+
+// checkMega 检测Mega链接是否有效
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的Mega分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体，包括URL、资源名称、错误码和耗时
+func (m *MegaChecker) checkMega(ctx context.Context, urlStr string) utils.Result {
+	logger.Debug("MegaChecker:开始检测Mega链接: %s", urlStr)
+
+	link, err := extractParamsMega(urlStr)
+	if err != nil {
+		logger.Info("MegaChecker:extractParamsMega, %s, 错误: %v\n", urlStr, err)
+		return utils.ErrorMalformed(urlStr, "链接格式无效")
+	}
+	if link.protected {
+		// 受密码保护的链接需要在网页中输入密码解出真正的链接
+		return utils.ErrorNeedPassword("链接受密码保护")
+	}
+
+	var key []byte
+	if link.key != "" {
+		if key, err = megaLinkKey(link.kind, link.key); err != nil {
+			logger.Info("MegaChecker:密钥无效: %s, 错误: %v", urlStr, err)
+			return utils.ErrorMalformed(urlStr, "解密密钥格式无效")
+		}
+	}
+
+	requestStart := time.Now()
+	var result utils.Result
+	if link.kind == megaKindFolder {
+		result, err = megaFolderResult(ctx, link.handle, key)
+	} else {
+		result, err = megaFileResult(ctx, link.handle, key)
+	}
+	logger.Debug("MegaChecker:接口请求完成，请求耗时: %dms", time.Since(requestStart).Milliseconds())
+	if err != nil {
+		logger.Info("MegaChecker:接口检测失败: %s, 错误: %v", urlStr, err)
+		return errorResult(err)
+	}
+	return result
+}
+
+const (
+	// megaKindFile 文件链接
+	megaKindFile = "file"
+	// megaKindFolder 文件夹链接
+	megaKindFolder = "folder"
+)
+
+var (
+	// megaAPIBase Mega公开接口地址
+	megaAPIBase = "https://g.api.mega.co.nz"
+	// megaHandleRegex 文件和文件夹句柄，固定为8位
+	megaHandleRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{8}$`)
+	// megaKeyRegex 链接中的密钥，为去掉填充的 base64url
+	megaKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// megaLink 从Mega链接中解析出的参数
+type megaLink struct {
+	kind      string // 链接类型，megaKindFile 或 megaKindFolder
+	handle    string // 文件或文件夹句柄
+	key       string // 片段中的密钥，可能为空
+	protected bool   // 是否为受密码保护的 #P! 链接
+}
+
+// megaNode 文件夹中的一个节点
+type megaNode struct {
+	Handle string    `json:"h"`
+	Parent string    `json:"p"`
+	Type   int       `json:"t"` // 0为文件，1为文件夹
+	Attr   string    `json:"a"`
+	Key    string    `json:"k"` // 形如 所有者句柄:加密的节点密钥
+	Size   flexInt64 `json:"s"`
+}
+
+// megaFileResult 查询文件的大小和加密属性，密钥存在时解密出文件名
+func megaFileResult(ctx context.Context, handle string, key []byte) (utils.Result, error) {
+	var file struct {
+		Size flexInt64 `json:"s"`
+		Attr string    `json:"at"`
+	}
+	if err := megaAPIRequest(ctx, map[string]interface{}{"a": "g", "p": handle}, "", &file); err != nil {
+		return utils.Result{}, err
+	}
+
+	meta := &utils.Metadata{FileCount: 1, TotalSize: int64(file.Size)}
+	if key == nil {
+		return megaKeyMissing().WithMeta(meta), nil
+	}
+	name, err := megaDecryptAttr(file.Attr, key)
+	if err != nil {
+		logger.Debug("MegaChecker:解密文件属性失败: %v", err)
+		return megaWrongKey(), nil
+	}
+	meta.AddEntry(name, int64(file.Size), false)
+	return utils.ErrorValid(name).WithMeta(meta), nil
+}
+
+// megaFolderResult 列出文件夹中的全部节点，统计顶层条目和总大小，密钥存在时解密出各节点名称
+func megaFolderResult(ctx context.Context, handle string, key []byte) (utils.Result, error) {
+	var folder struct {
+		Nodes []megaNode `json:"f"`
+	}
+	command := map[string]interface{}{"a": "f", "c": 1, "r": 1, "ca": 1}
+	if err := megaAPIRequest(ctx, command, handle, &folder); err != nil {
+		return utils.Result{}, err
+	}
+
+	var root *megaNode
+	for i := range folder.Nodes {
+		if folder.Nodes[i].Handle == handle {
+			root = &folder.Nodes[i]
+			break
+		}
+	}
+	if root == nil {
+		return utils.ErrorInvalid("无法获取分享信息").WithReason(utils.ReasonPageError), nil
+	}
+
+	meta := &utils.Metadata{}
+	var children []megaNode
+	for _, node := range folder.Nodes {
+		if node.Type == 0 {
+			meta.TotalSize += int64(node.Size)
+		}
+		if node.Parent == root.Handle {
+			children = append(children, node)
+		}
+	}
+	meta.FileCount = len(children)
+	if key == nil {
+		return megaKeyMissing().WithMeta(meta), nil
+	}
+
+	name, err := megaNodeName(*root, key)
+	if err != nil {
+		logger.Debug("MegaChecker:解密文件夹属性失败: %v", err)
+		return megaWrongKey(), nil
+	}
+	for _, child := range children {
+		// 个别节点解密失败时不影响整体结果
+		if childName, err := megaNodeName(child, key); err == nil {
+			meta.AddEntry(childName, int64(child.Size), child.Type == 1)
+		}
+	}
+	return utils.ErrorValid(name).WithMeta(meta), nil
+}
+
+// megaKeyMissing 链接缺少密钥时的结果，分享存在但无法解密名称
+func megaKeyMissing() utils.Result {
+	return utils.ErrorNeedPassword("链接缺少解密密钥，无法获取文件名").WithReason(utils.ReasonKeyMissing)
+}
+
+// megaWrongKey 密钥无法解密属性时的结果
+func megaWrongKey() utils.Result {
+	return utils.ErrorNeedPassword("解密密钥错误").WithReason(utils.ReasonWrongPasscode)
+}
+
+// megaErrorResult 根据接口返回的负数错误码创建检测结果
+func megaErrorResult(code int) utils.Result {
+	switch code {
+	case -9, -2: // ENOENT、EARGS
+		return utils.ErrorInvalid("文件不存在或已被删除").WithReason(utils.ReasonShareNotFound)
+	case -16: // EBLOCKED
+		return utils.ErrorBanned("文件因违反服务条款已被移除")
+	case -11: // EACCESS
+		return utils.ErrorLoginRequired("没有访问权限")
+	case -4, -6, -17: // ERATELIMIT、ETOOMANY、EOVERQUOTA
+		return utils.ErrorRateLimited("请求过于频繁或流量配额已用尽")
+	case -3, -18: // EAGAIN、ETEMPUNAVAIL
+		return utils.ErrorUnavailable("Mega服务暂时不可用")
+	}
+	return utils.ErrorUnknown(fmt.Sprintf("接口返回错误码%d", code))
+}
+
+// megaAPIRequest 向公开接口发送单条命令并解析结果
+// 接口以负数表示错误，整个请求失败时返回单个负数，单条命令失败时返回只含负数的数组
+// 错误码通过 *ResultError 返回
+//
+// 参数:
+// - command: 命令内容
+// - folder: 文件夹句柄，请求文件夹中的节点时需要，其余情况为空
+// - out: 命令结果的解析目标
+func megaAPIRequest(ctx context.Context, command interface{}, folder string, out interface{}) error {
+	jsonBody, _ := json.Marshal([]interface{}{command})
+	apiURL := fmt.Sprintf("%s/cs?id=%d", megaAPIBase, time.Now().UnixMilli())
+	if folder != "" {
+		apiURL += "&n=" + folder
+	}
+
+	req, err := apphttp.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("content-type", "application/json")
+
+	resp, err := apphttp.DoWithRetry(ctx, req, 0)
+	if err != nil {
+		return err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.NewStatusCodeError(fmt.Sprintf("HTTP状态码: %d", resp.StatusCode))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var code int
+	if json.Unmarshal(body, &code) == nil {
+		return resultError(megaErrorResult(code))
+	}
+	var results []json.RawMessage
+	if err = json.Unmarshal(body, &results); err != nil {
+		return errors.NewParseError("解析JSON失败", err)
+	}
+	if len(results) == 0 {
+		return errors.NewParseError("接口返回空结果", nil)
+	}
+	if json.Unmarshal(results[0], &code) == nil {
+		return resultError(megaErrorResult(code))
+	}
+	if err = json.Unmarshal(results[0], out); err != nil {
+		return errors.NewParseError("解析JSON失败", err)
+	}
+	return nil
+}
+
+// megaLinkKey 解码链接中的密钥并返回解密属性用的AES密钥
+// 文件密钥为32字节，前后两半异或得到AES密钥，文件夹密钥为16字节，直接用于解密节点密钥
+func megaLinkKey(kind, key string) ([]byte, error) {
+	raw, err := megaDecodeBase64(key)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case kind == megaKindFile && len(raw) == 32:
+		return megaFoldKey(raw), nil
+	case kind == megaKindFolder && len(raw) == 16:
+		return raw, nil
+	}
+	return nil, fmt.Errorf("密钥长度无效: %d", len(raw))
+}
+
+// megaNodeName 用文件夹密钥解密节点密钥，再用节点密钥解密出节点名称
+func megaNodeName(node megaNode, folderKey []byte) (string, error) {
+	// 节点可能有多个所有者的密钥，以 / 分隔，使用第一个
+	first, _, _ := strings.Cut(node.Key, "/")
+	_, encKey, found := strings.Cut(first, ":")
+	if !found {
+		return "", fmt.Errorf("节点密钥格式无效")
+	}
+	raw, err := megaDecodeBase64(encKey)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) != 16 && len(raw) != 32 {
+		return "", fmt.Errorf("节点密钥长度无效: %d", len(raw))
+	}
+
+	block, err := aes.NewCipher(folderKey)
+	if err != nil {
+		return "", err
+	}
+	// 节点密钥按 AES-ECB 逐块解密
+	for i := 0; i < len(raw); i += aes.BlockSize {
+		block.Decrypt(raw[i:i+aes.BlockSize], raw[i:i+aes.BlockSize])
+	}
+	if len(raw) == 32 {
+		raw = megaFoldKey(raw)
+	}
+	return megaDecryptAttr(node.Attr, raw)
+}
+
+// megaDecryptAttr 解密节点属性并返回其中的名称
+// 属性使用 AES-CBC 加密，IV为全零，明文形如 MEGA{"n":"名称"} 并以 \0 填充
+func megaDecryptAttr(attr string, key []byte) (string, error) {
+	data, err := megaDecodeBase64(attr)
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return "", fmt.Errorf("属性长度无效: %d", len(data))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(plain, data)
+	plain = bytes.TrimRight(plain, "\x00")
+	if !bytes.HasPrefix(plain, []byte("MEGA{")) {
+		return "", fmt.Errorf("属性解密失败")
+	}
+
+	var attrs struct {
+		Name string `json:"n"`
+	}
+	if err = json.Unmarshal(plain[len("MEGA"):], &attrs); err != nil {
+		return "", err
+	}
+	if attrs.Name == "" {
+		return "", fmt.Errorf("属性中没有名称")
+	}
+	return attrs.Name, nil
+}
+
+// megaFoldKey 把32字节的文件密钥前后两半异或为16字节的AES密钥
+func megaFoldKey(raw []byte) []byte {
+	key := make([]byte, 16)
+	for i := range key {
+		key[i] = raw[i] ^ raw[i+16]
+	}
+	return key
+}
+
+// megaDecodeBase64 解码Mega使用的去掉填充的 base64url
+func megaDecodeBase64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// extractParamsMega 从Mega链接中提取链接类型、句柄和密钥
+// 支持 /file/句柄#密钥、/folder/句柄#密钥[/子路径]、/embed/句柄#密钥、#!句柄!密钥、#F!句柄!密钥[!子句柄] 和 #P! 链接
+// 密钥缺失时不返回错误，由调用方区分处理
+func extractParamsMega(rawURL string) (megaLink, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return megaLink{}, err
+	}
+	if host := strings.TrimPrefix(strings.ToLower(parsedURL.Hostname()), "www."); host != "mega.nz" && host != "mega.co.nz" {
+		return megaLink{}, fmt.Errorf("不是Mega分享链接")
+	}
+
+	var link megaLink
+	segments := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
+	fragment := parsedURL.Fragment
+	switch {
+	case len(segments) >= 2 && (segments[0] == "file" || segments[0] == "embed"):
+		link.kind, link.handle = megaKindFile, segments[1]
+		link.key, _, _ = strings.Cut(fragment, "/")
+	case len(segments) >= 2 && segments[0] == "folder":
+		// 文件夹中的子文件或子文件夹以 /file/、/folder/ 附加在密钥之后
+		link.kind, link.handle = megaKindFolder, segments[1]
+		link.key, _, _ = strings.Cut(fragment, "/")
+	case segments[0] == "" && strings.HasPrefix(fragment, "P!"):
+		return megaLink{protected: true}, nil
+	case segments[0] == "" && strings.HasPrefix(fragment, "F!"):
+		parts := strings.Split(fragment[len("F!"):], "!")
+		link.kind, link.handle = megaKindFolder, parts[0]
+		if len(parts) > 1 {
+			link.key = parts[1]
+		}
+	case segments[0] == "" && strings.HasPrefix(fragment, "!"):
+		parts := strings.Split(fragment[len("!"):], "!")
+		link.kind, link.handle = megaKindFile, parts[0]
+		if len(parts) > 1 {
+			link.key = parts[1]
+		}
+	default:
+		return megaLink{}, fmt.Errorf("无法寻找分享ID")
+	}
+
+	if !megaHandleRegex.MatchString(link.handle) {
+		return megaLink{}, fmt.Errorf("句柄格式无效: %s", link.handle)
+	}
+	if link.key != "" && !megaKeyRegex.MatchString(link.key) {
+		return megaLink{}, fmt.Errorf("密钥格式无效")
+	}
+	return link, nil
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"share-sniffer/internal/cache"
+	"share-sniffer/internal/utils"
+)
+
+// megaTestEncryptAttr 按Mega的格式加密节点属性
+func megaTestEncryptAttr(t *testing.T, name string, key []byte) string {
+	t.Helper()
+	plain := []byte(`MEGA{"n":"` + name + `"}`)
+	if pad := len(plain) % aes.BlockSize; pad != 0 {
+		plain = append(plain, make([]byte, aes.BlockSize-pad)...)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher.NewCBCEncrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(plain, plain)
+	return base64.RawURLEncoding.EncodeToString(plain)
+}
+
+// megaTestEncryptKey 按 AES-ECB 用文件夹密钥加密节点密钥
+func megaTestEncryptKey(t *testing.T, nodeKey, folderKey []byte) string {
+	t.Helper()
+	block, err := aes.NewCipher(folderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]byte, len(nodeKey))
+	for i := 0; i < len(nodeKey); i += aes.BlockSize {
+		block.Encrypt(out[i:i+aes.BlockSize], nodeKey[i:i+aes.BlockSize])
+	}
+	return base64.RawURLEncoding.EncodeToString(out)
+}
+
+func TestMegaChecker(t *testing.T) {
+	fileKey := []byte("0123456789abcdefFEDCBA9876543210")
+	fileKeyB64 := base64.RawURLEncoding.EncodeToString(fileKey)
+	wrongKeyB64 := base64.RawURLEncoding.EncodeToString([]byte("ffffffffffffffffFEDCBA9876543210"))
+	folderKey := []byte("folder-key-16byt")
+	folderKeyB64 := base64.RawURLEncoding.EncodeToString(folderKey)
+	rootKey := []byte("root-node-key-16")
+	childKey := []byte("child-file-key-32-bytes-long!!!!")
+
+	fileAttr := megaTestEncryptAttr(t, "movie.mkv", megaFoldKey(fileKey))
+	rootAttr := megaTestEncryptAttr(t, "Photos", rootKey)
+	childAttr := megaTestEncryptAttr(t, "cat.jpg", megaFoldKey(childKey))
+	folderBody := `[{"f":[` +
+		`{"h":"FoLdEr01","p":"OwNeR001","t":1,"a":"` + rootAttr + `","k":"FoLdEr01:` + megaTestEncryptKey(t, rootKey, folderKey) + `"},` +
+		`{"h":"ChIlD001","p":"FoLdEr01","t":0,"s":2048,"a":"` + childAttr + `","k":"FoLdEr01:` + megaTestEncryptKey(t, childKey, folderKey) + `"},` +
+		`{"h":"SuBdIr01","p":"FoLdEr01","t":1,"a":"","k":""},` +
+		`{"h":"ChIlD002","p":"SuBdIr01","t":0,"s":1024,"a":"","k":""}` +
+		`]}]`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path != "/cs" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch {
+		case r.URL.Query().Get("n") == "FoLdEr01":
+			w.Write([]byte(folderBody))
+		case r.URL.Query().Get("n") != "":
+			w.Write([]byte(`-9`))
+		case strings.Contains(string(body), `"FiLeOk01"`):
+			w.Write([]byte(`[{"s":1048576,"at":"` + fileAttr + `"}]`))
+		case strings.Contains(string(body), `"BlOcKeD1"`):
+			w.Write([]byte(`[-16]`))
+		case strings.Contains(string(body), `"QuOtA001"`):
+			w.Write([]byte(`[-17]`))
+		default:
+			w.Write([]byte(`[-9]`))
+		}
+	}))
+	defer server.Close()
+
+	apiBase := megaAPIBase
+	megaAPIBase = server.URL
+	defer func() { megaAPIBase = apiBase }()
+
+	checker := &MegaChecker{}
+	tests := []struct {
+		url        string
+		wantError  utils.ErrorType
+		wantReason string
+		wantName   string
+	}{
+		{"https://mega.nz/file/FiLeOk01#" + fileKeyB64, utils.Valid, utils.ReasonOK, "movie.mkv"},
+		{"https://mega.nz/#!FiLeOk01!" + fileKeyB64, utils.Valid, utils.ReasonOK, "movie.mkv"},
+		{"https://mega.co.nz/#!FiLeOk01!" + fileKeyB64, utils.Valid, utils.ReasonOK, "movie.mkv"},
+		{"https://mega.nz/folder/FoLdEr01#" + folderKeyB64, utils.Valid, utils.ReasonOK, "Photos"},
+		{"https://mega.nz/folder/FoLdEr01#" + folderKeyB64 + "/file/ChIlD001", utils.Valid, utils.ReasonOK, "Photos"},
+		{"https://mega.nz/#F!FoLdEr01!" + folderKeyB64, utils.Valid, utils.ReasonOK, "Photos"},
+		{"https://mega.nz/file/FiLeOk01", utils.NeedPassword, utils.ReasonKeyMissing, ""},
+		{"https://mega.nz/#F!FoLdEr01", utils.NeedPassword, utils.ReasonKeyMissing, ""},
+		{"https://mega.nz/file/FiLeOk01#" + wrongKeyB64, utils.NeedPassword, utils.ReasonWrongPasscode, ""},
+		{"https://mega.nz/file/FiLeOk01#c2hvcnQ", utils.Malformed, utils.ReasonMalformedURL, ""},
+		{"https://mega.nz/file/GoNe0001#" + fileKeyB64, utils.Invalid, utils.ReasonShareNotFound, ""},
+		{"https://mega.nz/folder/GoNe0002#" + folderKeyB64, utils.Invalid, utils.ReasonShareNotFound, ""},
+		{"https://mega.nz/file/BlOcKeD1#" + fileKeyB64, utils.Banned, utils.ReasonBanned, ""},
+		{"https://mega.nz/file/QuOtA001#" + fileKeyB64, utils.RateLimited, utils.ReasonRateLimited, ""},
+		{"https://mega.nz/#P!AgAAAAAAAAAA", utils.NeedPassword, utils.ReasonPasscodeRequired, ""},
+		{"https://mega.nz/pro", utils.Malformed, utils.ReasonMalformedURL, ""},
+	}
+	for _, tt := range tests {
+		got := checker.Check(context.Background(), tt.url)
+		if got.Error != tt.wantError || got.Reason != tt.wantReason || got.Data.Name != tt.wantName {
+			t.Errorf("Check(%q) = %d %s %q %q, want %d %s %q", tt.url, got.Error, got.Reason, got.Msg, got.Data.Name, tt.wantError, tt.wantReason, tt.wantName)
+		}
+	}
+
+	// 文件夹统计顶层条目和全部文件的大小，无法解密的子节点不出现在预览中
+	got := checker.Check(context.Background(), "https://mega.nz/folder/FoLdEr01#"+folderKeyB64)
+	if meta := got.Data.Meta; meta == nil || meta.FileCount != 2 || meta.TotalSize != 3072 || len(meta.Entries) != 1 || meta.Entries[0].Name != "cat.jpg" {
+		t.Errorf("Check(folder) meta = %+v", meta)
+	}
+	// 缺少密钥时仍返回大小
+	got = checker.Check(context.Background(), "https://mega.nz/file/FiLeOk01")
+	if meta := got.Data.Meta; meta == nil || meta.TotalSize != 1048576 {
+		t.Errorf("Check(key missing) meta = %+v", meta)
+	}
+}
+
+func TestExtractParamsMega(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    megaLink
+		wantErr bool
+	}{
+		{"https://mega.nz/file/AbCd1234#k3y_-K3y", megaLink{kind: megaKindFile, handle: "AbCd1234", key: "k3y_-K3y"}, false},
+		{"https://mega.nz/embed/AbCd1234#k3y", megaLink{kind: megaKindFile, handle: "AbCd1234", key: "k3y"}, false},
+		{"https://mega.nz/folder/AbCd1234#k3y/folder/SuBd1234", megaLink{kind: megaKindFolder, handle: "AbCd1234", key: "k3y"}, false},
+		{"https://www.mega.nz/#!AbCd1234!k3y", megaLink{kind: megaKindFile, handle: "AbCd1234", key: "k3y"}, false},
+		{"https://mega.co.nz/#F!AbCd1234!k3y!SuBd1234", megaLink{kind: megaKindFolder, handle: "AbCd1234", key: "k3y"}, false},
+		{"https://mega.nz/#!AbCd1234", megaLink{kind: megaKindFile, handle: "AbCd1234"}, false},
+		{"https://mega.nz/#P!AgAbCd", megaLink{protected: true}, false},
+		{"https://mega.nz/file/short#k3y", megaLink{}, true},
+		{"https://mega.nz/file/AbCd1234#k3y+bad", megaLink{}, true},
+		{"https://mega.nz/", megaLink{}, true},
+		{"https://example.com/file/AbCd1234#k3y", megaLink{}, true},
+	}
+	for _, tt := range tests {
+		got, err := extractParamsMega(tt.url)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("extractParamsMega(%q) = %+v, %v, want %+v", tt.url, got, err, tt.want)
+		}
+	}
+
+	if got, id := (&MegaChecker{}).Normalize("https://mega.co.nz/#F!AbCd1234!k3y"); got != "https://mega.nz/folder/AbCd1234#k3y" || id != "AbCd1234" {
+		t.Errorf("Normalize() = %q, %q", got, id)
+	}
+}
+
+func TestMegaKeyInCacheKey(t *testing.T) {
+	fileKey := []byte("0123456789abcdefFEDCBA9876543210")
+	fileAttr := megaTestEncryptAttr(t, "movie.mkv", megaFoldKey(fileKey))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"s":1048576,"at":"` + fileAttr + `"}]`))
+	}))
+	defer server.Close()
+
+	apiBase := megaAPIBase
+	megaAPIBase = server.URL
+	defer func() { megaAPIBase = apiBase }()
+	SetCache(cache.New(filepath.Join(t.TempDir(), "cache.db")))
+	defer SetCache(nil)
+
+	right := "https://mega.nz/file/FiLeOk01#" + base64.RawURLEncoding.EncodeToString(fileKey)
+	wrong := "https://mega.nz/file/FiLeOk01#" + base64.RawURLEncoding.EncodeToString([]byte("ffffffffffffffffFEDCBA9876543210"))
+
+	// 同一句柄的两个密钥规范化后不是重复链接
+	c1, _ := Canonicalize(right)
+	c2, _ := Canonicalize(wrong)
+	if c1.ShareID != c2.ShareID || c1.Key() == c2.Key() {
+		t.Errorf("Canonicalize() keys = %q, %q, want different keys for the same handle", c1.Key(), c2.Key())
+	}
+	if got := FindDuplicates([]string{right, wrong}); got[1] != -1 {
+		t.Errorf("FindDuplicates() = %v, want no duplicates", got)
+	}
+
+	// 正确密钥的结果写入缓存后，错误密钥仍重新检测
+	if got := Adapter(context.Background(), right); got.Error != utils.Valid || got.Data.Cached {
+		t.Fatalf("Adapter(right key) = %d %s cached=%v", got.Error, got.Reason, got.Data.Cached)
+	}
+	if got := Adapter(context.Background(), wrong); got.Reason != utils.ReasonWrongPasscode || got.Data.Cached {
+		t.Errorf("Adapter(wrong key) = %d %s cached=%v, want %s", got.Error, got.Reason, got.Data.Cached, utils.ReasonWrongPasscode)
+	}
+	if got := Adapter(context.Background(), right); !got.Data.Cached {
+		t.Errorf("Adapter(right key) again cached = false, want true")
+	}
+}
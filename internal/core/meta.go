@@ -0,0 +1,88 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// meta.go 提供了填充分享内容元数据时使用的辅助类型和函数
+package core
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// flexInt64 兼容数字和字符串两种写法的整数，部分网盘接口会把数字放在字符串里返回
+type flexInt64 int64
+
+// UnmarshalJSON 实现json.Unmarshaler接口，无法解析的值视为0
+func (n *flexInt64) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*n = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		*n = 0
+		return nil
+	}
+	*n = flexInt64(v)
+	return nil
+}
+
+// expiryMillis 把接口返回的过期时间统一转换为毫秒时间戳
+// 小于等于0表示永久有效，秒级时间戳会自动转换为毫秒
+func expiryMillis(v int64) int64 {
+	if v <= 0 {
+		return 0
+	}
+	// 2001年以后的毫秒时间戳都大于该值
+	if v < 1e12 {
+		return v * 1000
+	}
+	return v
+}
+
+// parseExpiryTime 解析RFC3339格式的过期时间，为空或无法解析时返回0
+func parseExpiryTime(s string) int64 {
+	if s == "" {
+		return 0
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// sizeUnits 文件大小单位对应的字节数，按单位首字母匹配
+var sizeUnits = map[byte]float64{
+	'b': 1,
+	'k': 1 << 10,
+	'm': 1 << 20,
+	'g': 1 << 30,
+	't': 1 << 40,
+}
+
+// parseHumanSize 解析 "1.2 M"、"356 KB"、"2.1GB" 这类带单位的文件大小，无法解析时返回0
+func parseHumanSize(s string) int64 {
+	s = strings.TrimSpace(strings.ReplaceAll(s, ",", ""))
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if end == -1 {
+		end = len(s)
+	}
+	v, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil {
+		return 0
+	}
+	unit := strings.ToLower(strings.TrimSpace(s[end:]))
+	if unit == "" {
+		return int64(v)
+	}
+	multiplier, ok := sizeUnits[unit[0]]
+	if !ok {
+		return 0
+	}
+	return int64(v * multiplier)
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBaiduListMeta(t *testing.T) {
+	body := `{"errno":0,"title":"资料","list":[
+		{"server_filename":"a.mp4","size":"1024","isdir":"0"},
+		{"server_filename":"docs","size":0,"isdir":1}
+	]}`
+
+	var resp ShareListResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	meta := resp.meta()
+	if meta.FileCount != 2 || meta.TotalSize != 1024 {
+		t.Errorf("meta() = %+v, want 2 files and 1024 bytes", meta)
+	}
+	if len(meta.Entries) != 2 || meta.Entries[0].IsDir || !meta.Entries[1].IsDir {
+		t.Errorf("meta().Entries = %+v", meta.Entries)
+	}
+}
+
+func TestYywSnapMeta(t *testing.T) {
+	body := `{"state":true,"errno":0,"data":{"count":3,
+		"shareinfo":{"share_title":"合集","file_size":"2048","expire_time":1760000000},
+		"userinfo":{"user_name":"tester"},
+		"list":[{"n":"合集","cid":"1"},{"n":"b.txt","s":2048,"fid":"2"}]}}`
+
+	var resp yywResp
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	meta := resp.meta()
+	if meta.FileCount != 3 || meta.TotalSize != 2048 || meta.Creator != "tester" {
+		t.Errorf("meta() = %+v", meta)
+	}
+	if meta.ExpiresAt != 1760000000000 {
+		t.Errorf("meta().ExpiresAt = %d, want 1760000000000", meta.ExpiresAt)
+	}
+	if len(meta.Entries) != 2 || !meta.Entries[0].IsDir || meta.Entries[1].IsDir {
+		t.Errorf("meta().Entries = %+v", meta.Entries)
+	}
+}
@@ -0,0 +1,224 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// onedrive.go 实现了OneDrive链接检查器，作为策略模式的具体策略实现
+// 提供了OneDriveChecker结构体，实现了LinkChecker接口的Check和GetPrefix方法
+// 1drv.ms 短链接先解析为 onedrive.live.com 链接，再通过 shares 接口匿名获取分享的文件或文件夹
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/errors"
+	apphttp "share-sniffer/internal/http"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/utils"
+)
+
+// OneDriveChecker OneDrive链接检查器
+// 实现了LinkChecker接口，是策略模式的具体策略之一
+// 负责检查OneDrive个人版分享链接的有效性和获取分享内容信息
+type OneDriveChecker struct{}
+
+// Check 实现LinkChecker接口的Check方法
+// 调用内部的checkOneDrive方法执行具体的检查逻辑
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的OneDrive分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体
+func (o *OneDriveChecker) Check(ctx context.Context, urlStr string) utils.Result {
+	return o.checkOneDrive(ctx, urlStr)
+}
+
+// GetPrefix 实现LinkChecker接口的GetPrefix方法
+// 返回OneDrive链接的前缀，用于在注册时识别
+//
+// 返回值:
+// - []string: OneDrive链接的前缀数组，从配置中获取
+func (o *OneDriveChecker) GetPrefix() []string {
+	return config.GetSupportedOneDrive()
+}
+
+// checkOneDrive 检测OneDrive链接是否有效
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的OneDrive分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体，包括URL、资源名称、错误码和耗时
+func (o *OneDriveChecker) checkOneDrive(ctx context.Context, urlStr string) utils.Result {
+	logger.Debug("OneDriveChecker:开始检测OneDrive链接: %s", urlStr)
+
+	shareURL, short, err := extractParamsOneDrive(urlStr)
+	if err != nil {
+		logger.Info("OneDriveChecker:extractParamsOneDrive, %s, 错误: %v\n", urlStr, err)
+		return utils.ErrorMalformed(urlStr, "链接格式无效")
+	}
+
+	requestStart := time.Now()
+	if short {
+		if shareURL, err = resolveOneDriveShortLink(ctx, shareURL); err != nil {
+			logger.Info("OneDriveChecker:短链接解析失败: %s, 错误: %v", urlStr, err)
+			if errors.IsStatusCodeError(err) {
+				return utils.ErrorInvalid("短链接不存在").WithReason(utils.ReasonShareNotFound)
+			}
+			return errorResult(err)
+		}
+		logger.Debug("OneDriveChecker:短链接解析为: %s", shareURL)
+	}
+
+	response, err := oneDriveShareRequest(ctx, shareURL)
+	logger.Debug("OneDriveChecker:接口请求完成，请求耗时: %dms", time.Since(requestStart).Milliseconds())
+	if err != nil {
+		logger.Info("OneDriveChecker:接口检测失败: %s, 错误: %v", urlStr, err)
+		return errorResult(err)
+	}
+	return response.result()
+}
+
+// oneDriveAPIBase OneDrive个人版接口地址
+var oneDriveAPIBase = "https://api.onedrive.com/v1.0"
+
+// oneDriveItemResp shares 接口响应结构
+type oneDriveItemResp struct {
+	Name   string    `json:"name"`
+	Size   flexInt64 `json:"size"`
+	Folder *struct {
+		ChildCount int `json:"childCount"`
+	} `json:"folder"`
+	CreatedBy struct {
+		User struct {
+			DisplayName string `json:"displayName"`
+		} `json:"user"`
+	} `json:"createdBy"`
+
+	// 接口出错时返回的字段
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// result 根据接口响应创建检测结果，错误码区分已删除、没有权限和配额用尽
+func (r *oneDriveItemResp) result() utils.Result {
+	if r.Error != nil {
+		msg := r.Error.Message
+		switch r.Error.Code {
+		case "itemNotFound":
+			return utils.ErrorInvalid(cmp.Or(msg, "文件不存在或已被删除")).WithReason(utils.ReasonShareNotFound)
+		case "accessDenied", "unauthenticated", "forbidden":
+			return utils.ErrorLoginRequired(cmp.Or(msg, "没有访问权限，需要登录或分享者授权"))
+		case "quotaLimitReached", "activityLimitReached":
+			return utils.ErrorRateLimited(cmp.Or(msg, "分享者的流量配额已用尽"))
+		}
+		logger.Debug("OneDriveChecker:接口返回业务错误: code=%s, message=%s", r.Error.Code, msg)
+		return messageResult(msg, "分享无法访问")
+	}
+
+	if r.Name == "" {
+		return utils.ErrorInvalid("无法获取分享信息").WithReason(utils.ReasonPageError)
+	}
+	meta := &utils.Metadata{Creator: r.CreatedBy.User.DisplayName, TotalSize: int64(r.Size)}
+	if r.Folder != nil {
+		meta.FileCount = r.Folder.ChildCount
+	} else {
+		meta.FileCount = 1
+	}
+	meta.AddEntry(r.Name, int64(r.Size), r.Folder != nil)
+	return utils.ErrorValid(r.Name).WithMeta(meta)
+}
+
+// resolveOneDriveShortLink 解析 1drv.ms 短链接，返回跳转的目标地址，短链接不存在时返回状态码错误
+func resolveOneDriveShortLink(ctx context.Context, shortURL string) (string, error) {
+	page, err := fetchSharePage(ctx, shortURL)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case page.StatusCode == http.StatusNotFound:
+		return "", errors.NewStatusCodeError("短链接不存在")
+	case page.Location != "":
+		return page.Location, nil
+	}
+	// 没有跳转时直接使用短链接请求接口
+	return shortURL, nil
+}
+
+// oneDriveShareRequest 通过 shares 接口获取分享链接指向的文件或文件夹
+// 分享链接按接口约定编码为 u! 加上去掉填充的 base64url
+func oneDriveShareRequest(ctx context.Context, shareURL string) (*oneDriveItemResp, error) {
+	encoded := "u!" + base64.RawURLEncoding.EncodeToString([]byte(shareURL))
+	apiURL := oneDriveAPIBase + "/shares/" + encoded + "/driveItem?$select=name,size,folder,file,createdBy"
+
+	req, err := apphttp.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apphttp.DoWithRetry(ctx, req, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// 接口错误同时通过状态码和响应中的error字段返回
+	var response oneDriveItemResp
+	if err = json.Unmarshal(body, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.NewStatusCodeError(fmt.Sprintf("HTTP状态码: %d", resp.StatusCode))
+		}
+		return nil, errors.NewParseError("解析JSON失败", err)
+	}
+	if resp.StatusCode != http.StatusOK && response.Error == nil {
+		return nil, errors.NewStatusCodeError(fmt.Sprintf("HTTP状态码: %d", resp.StatusCode))
+	}
+
+	return &response, nil
+}
+
+// extractParamsOneDrive 检查OneDrive链接并返回用于请求接口的分享链接
+//
+// 返回值:
+// - string: 分享链接
+// - bool: 是否为需要先解析的 1drv.ms 短链接
+// - error: 链接格式无效时返回错误
+func extractParamsOneDrive(rawURL string) (string, bool, error) {
+	parsedURL, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return "", false, err
+	}
+
+	switch host := strings.TrimPrefix(strings.ToLower(parsedURL.Hostname()), "www."); host {
+	case "1drv.ms":
+		// 短链接形如 /u/s!AbCd、/f/s!AbCd 或 /u/c/CID/TOKEN
+		if strings.Count(strings.Trim(parsedURL.Path, "/"), "/") < 1 {
+			return "", false, fmt.Errorf("无法寻找分享ID")
+		}
+		return rawURL, true, nil
+	case "onedrive.live.com":
+		query := parsedURL.Query()
+		if query.Get("resid") == "" && query.Get("id") == "" && query.Get("redeem") == "" {
+			return "", false, fmt.Errorf("无法寻找分享ID")
+		}
+		return rawURL, false, nil
+	}
+	return "", false, fmt.Errorf("不是OneDrive分享链接")
+}
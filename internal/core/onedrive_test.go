@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"share-sniffer/internal/errors"
+	"share-sniffer/internal/utils"
+)
+
+// shares 接口的响应样例，按分享链接中的resid区分
+var oneDriveResponses = map[string]string{
+	"FILE!101": `{"name":"budget.xlsx","size":20480,"file":{},"createdBy":{"user":{"displayName":"Alice"}}}`,
+	"DIR!102":  `{"name":"Vacation","size":1048576,"folder":{"childCount":12}}`,
+	"GONE!103": `{"error":{"code":"itemNotFound","message":"Item does not exist"}}`,
+	"DENY!104": `{"error":{"code":"accessDenied","message":""}}`,
+	"FULL!105": `{"error":{"code":"quotaLimitReached","message":""}}`,
+}
+
+func TestOneDriveChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/u/s!ShortOk":
+			http.Redirect(w, r, "https://onedrive.live.com/redir?resid=FILE!101&authkey=!AK", http.StatusMovedPermanently)
+		case r.URL.Path == "/u/s!ShortGone":
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasPrefix(r.URL.Path, "/shares/u!"):
+			encoded := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/shares/u!"), "/driveItem")
+			shareURL, err := base64.RawURLEncoding.DecodeString(encoded)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var resid string
+			if i := strings.Index(string(shareURL), "resid="); i >= 0 {
+				resid, _, _ = strings.Cut(string(shareURL)[i+len("resid="):], "&")
+			}
+			body, ok := oneDriveResponses[resid]
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":{"code":"invalidRequest","message":"Invalid request"}}`))
+				return
+			}
+			if strings.Contains(body, `"error"`) {
+				w.WriteHeader(http.StatusForbidden)
+			}
+			w.Write([]byte(body))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apiBase := oneDriveAPIBase
+	oneDriveAPIBase = server.URL
+	defer func() { oneDriveAPIBase = apiBase }()
+
+	checker := &OneDriveChecker{}
+	tests := []struct {
+		url        string
+		wantError  utils.ErrorType
+		wantReason string
+		wantName   string
+	}{
+		{"https://onedrive.live.com/redir?resid=FILE!101&authkey=!AK", utils.Valid, utils.ReasonOK, "budget.xlsx"},
+		{"https://onedrive.live.com/?cid=C1&resid=DIR!102&authkey=!AK", utils.Valid, utils.ReasonOK, "Vacation"},
+		{"https://onedrive.live.com/redir?resid=GONE!103", utils.Invalid, utils.ReasonShareNotFound, ""},
+		{"https://onedrive.live.com/redir?resid=DENY!104", utils.LoginRequired, utils.ReasonLoginRequired, ""},
+		{"https://onedrive.live.com/redir?resid=FULL!105", utils.RateLimited, utils.ReasonRateLimited, ""},
+		{"https://onedrive.live.com/about/en-us/", utils.Malformed, utils.ReasonMalformedURL, ""},
+	}
+	for _, tt := range tests {
+		got := checker.Check(context.Background(), tt.url)
+		if got.Error != tt.wantError || got.Reason != tt.wantReason || got.Data.Name != tt.wantName {
+			t.Errorf("Check(%q) = %d %s %q %q, want %d %s %q", tt.url, got.Error, got.Reason, got.Msg, got.Data.Name, tt.wantError, tt.wantReason, tt.wantName)
+		}
+	}
+
+	got := checker.Check(context.Background(), "https://onedrive.live.com/?cid=C1&resid=DIR!102")
+	if meta := got.Data.Meta; meta == nil || meta.FileCount != 12 || meta.TotalSize != 1048576 || !meta.Entries[0].IsDir {
+		t.Errorf("Check(DIR!102) meta = %+v", meta)
+	}
+
+	// 短链接解析为完整链接，短链接不存在时返回状态码错误
+	if got, err := resolveOneDriveShortLink(context.Background(), server.URL+"/u/s!ShortOk"); err != nil || got != "https://onedrive.live.com/redir?resid=FILE!101&authkey=!AK" {
+		t.Errorf("resolveOneDriveShortLink(ShortOk) = %q, %v", got, err)
+	}
+	if _, err := resolveOneDriveShortLink(context.Background(), server.URL+"/u/s!ShortGone"); !errors.IsStatusCodeError(err) {
+		t.Errorf("resolveOneDriveShortLink(ShortGone) error = %v, want status code error", err)
+	}
+}
+
+func TestExtractParamsOneDrive(t *testing.T) {
+	tests := []struct {
+		url       string
+		wantShort bool
+		wantErr   bool
+	}{
+		{"https://1drv.ms/u/s!AbCdEfGh", true, false},
+		{"https://1drv.ms/f/c/0123abcd/EgHiJk", true, false},
+		{"https://onedrive.live.com/redir?resid=ABC!101&authkey=!AK", false, false},
+		{"https://onedrive.live.com/redir?redeem=aHR0cHM6", false, false},
+		{"https://1drv.ms/", false, true},
+		{"https://onedrive.live.com/about/", false, true},
+	}
+	for _, tt := range tests {
+		_, short, err := extractParamsOneDrive(tt.url)
+		if (err != nil) != tt.wantErr || short != tt.wantShort {
+			t.Errorf("extractParamsOneDrive(%q) = %v, %v, want %v", tt.url, short, err, tt.wantShort)
+		}
+	}
+}
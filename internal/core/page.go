@@ -0,0 +1,129 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// page.go 提供了请求分享网页的通用方法，供没有公开接口、需要根据网页判断分享状态的检查器使用
+// 请求不跟随重定向，检查器根据状态码和跳转地址区分分享已删除、需要登录和正常的分享页面
+package core
+
+import (
+	"context"
+	"html"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	apphttp "share-sniffer/internal/http"
+)
+
+// pageMaxBytes 读取网页的最大字节数，分享状态和标题都在网页开头
+const pageMaxBytes = 2 << 20
+
+var (
+	// ogTitleRegex 网页的 og:title 元数据，content 属性可能在 property 属性之前
+	ogTitleRegex = regexp.MustCompile(`<meta[^>]+(?:property="og:title"[^>]+content="([^"]*)"|content="([^"]*)"[^>]+property="og:title")`)
+	// titleRegex 网页标题
+	titleRegex = regexp.MustCompile(`(?s)<title[^>]*>(.*?)</title>`)
+	// metaRefreshRegex 网页中 meta refresh 跳转的目标地址
+	metaRefreshRegex = regexp.MustCompile(`(?i)<meta[^>]+http-equiv=["']?refresh["']?[^>]+content=["']?\s*\d*\s*;\s*url=['"]?([^"'>\s]+)`)
+)
+
+// sharePage 分享网页的响应
+type sharePage struct {
+	StatusCode int
+	// Location 重定向的目标地址，已转换为绝对地址，不是重定向时为空
+	Location string
+	Body     string
+}
+
+// fetchSharePage 请求分享网页，不跟随重定向
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - pageURL: 分享网页地址
+//
+// 返回值:
+// - *sharePage: 网页的状态码、跳转地址和内容
+// - error: 请求失败时返回错误
+func fetchSharePage(ctx context.Context, pageURL string) (*sharePage, error) {
+	req, err := apphttp.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("accept-language", "en-US,en;q=0.9")
+
+	resp, err := apphttp.DoWithClient(ctx, apphttp.GetNoRedirectClient(), req, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, pageMaxBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	page := &sharePage{StatusCode: resp.StatusCode, Body: string(body)}
+	if location := resp.Header.Get("Location"); location != "" {
+		if target, err := resp.Request.URL.Parse(location); err == nil {
+			page.Location = target.String()
+		}
+	}
+	return page, nil
+}
+
+// redirectHost 返回重定向目标地址的主机，不是重定向时为空
+func (p *sharePage) redirectHost() string {
+	if p.Location == "" {
+		return ""
+	}
+	u, err := url.Parse(p.Location)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// title 返回网页的标题，优先使用 og:title，并去掉标题末尾的网站名称
+//
+// 参数:
+// - suffixes: 标题末尾可能带有的网站名称，如 " - Google Drive"
+func (p *sharePage) title(suffixes ...string) string {
+	var title string
+	if m := ogTitleRegex.FindStringSubmatch(p.Body); m != nil {
+		title = m[1] + m[2]
+	}
+	if title == "" {
+		if m := titleRegex.FindStringSubmatch(p.Body); m != nil {
+			title = m[1]
+		}
+	}
+	title = strings.TrimSpace(html.UnescapeString(title))
+	for _, suffix := range suffixes {
+		title = strings.TrimSpace(strings.TrimSuffix(title, suffix))
+	}
+	return title
+}
+
+// metaRefresh 返回网页中 meta refresh 跳转的绝对地址，没有时为空
+// 部分短链接服务返回200状态码，通过 meta refresh 跳转
+func (p *sharePage) metaRefresh(pageURL string) string {
+	m := metaRefreshRegex.FindStringSubmatch(p.Body)
+	if m == nil {
+		return ""
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	target, err := base.Parse(html.UnescapeString(m[1]))
+	if err != nil {
+		return ""
+	}
+	return target.String()
+}
+
+// containsAnyFold 判断网页内容是否包含任意关键词，不区分大小写
+func (p *sharePage) containsAnyFold(keywords ...string) bool {
+	return containsAny(strings.ToLower(p.Body), keywords)
+}
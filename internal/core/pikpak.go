@@ -0,0 +1,140 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// pikpak.go 实现了PikPak链接检查器，作为策略模式的具体策略实现
+// 提供了PikPakChecker结构体，实现了LinkChecker接口的Check和GetPrefix方法
+// PikPak与迅雷网盘使用同一套分享接口，匿名获取验证码令牌后请求分享详情
+package core
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/errors"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/parser"
+	"share-sniffer/internal/utils"
+)
+
+// PikPakChecker PikPak链接检查器
+// 实现了LinkChecker接口，是策略模式的具体策略之一
+// 负责检查PikPak分享链接的有效性和获取分享内容信息
+type PikPakChecker struct{}
+
+// Check 实现LinkChecker接口的Check方法
+// 调用内部的checkPikPak方法执行具体的检查逻辑
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的PikPak分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体
+func (p *PikPakChecker) Check(ctx context.Context, urlStr string) utils.Result {
+	return p.checkPikPak(ctx, urlStr)
+}
+
+// GetPrefix 实现LinkChecker接口的GetPrefix方法
+// 返回PikPak链接的前缀，用于在注册时识别
+//
+// 返回值:
+// - []string: PikPak链接的前缀数组，从配置中获取
+func (p *PikPakChecker) GetPrefix() []string {
+	return config.GetSupportedPikPak()
+}
+
+// ShareID 实现ShareIdentifier接口
+func (p *PikPakChecker) ShareID(urlStr string) string {
+	shareID, _, _ := extractParamsPikPak(urlStr)
+	return shareID
+}
+
+// Normalize 实现Normalizer接口，链接中分享ID之后可能带有文件ID，规范化时去掉
+func (p *PikPakChecker) Normalize(urlStr string) (string, string) {
+	shareID := p.ShareID(urlStr)
+	if shareID == "" {
+		return "", ""
+	}
+	return "https://mypikpak.com/s/" + shareID, shareID
+}
+
+// checkPikPak 检测PikPak链接是否有效
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的PikPak分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体，包括URL、资源名称、错误码和耗时
+func (p *PikPakChecker) checkPikPak(ctx context.Context, urlStr string) utils.Result {
+	logger.Debug("PikPakChecker:开始检测PikPak链接: %s", urlStr)
+
+	shareID, passCode, err := extractParamsPikPak(urlStr)
+	if err != nil {
+		logger.Info("PikPakChecker:extractParamsPikPak, %s, 错误: %v\n", urlStr, err)
+		return utils.ErrorMalformed(urlStr, "链接格式无效")
+	}
+
+	requestStart := time.Now()
+	response, err := pikpakShield.shareRequest(ctx, shareID, passCode)
+	logger.Debug("PikPakChecker:接口请求完成，请求耗时: %dms", time.Since(requestStart).Milliseconds())
+	if err != nil {
+		logger.Info("PikPakChecker:接口检测失败: %s, 错误: %v", urlStr, err)
+		return pikpakErrorResult(err)
+	}
+	return response.result()
+}
+
+// pikpakShield PikPak分享接口的匿名客户端
+var pikpakShield = &shieldClient{
+	name:          "PikPakChecker",
+	clientID:      "YUMx5nI8ZU8Ap8pm",
+	clientVersion: "2.0.0",
+	packageName:   "mypikpak.com",
+	action:        "GET:/drive/v1/share",
+	origin:        "https://mypikpak.com",
+	apiBase:       "https://api-drive.mypikpak.com",
+	userBase:      "https://user.mypikpak.com",
+}
+
+// pikpakErrorResult 把请求错误转换为检测结果
+// 分享不存在或已过期时接口可能不返回分享状态，而是通过error字段返回错误码
+func pikpakErrorResult(err error) utils.Result {
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) || appErr.Type != errors.ErrTypeAPI {
+		return errorResult(err)
+	}
+	switch code := appErr.ErrorCode; {
+	case strings.Contains(code, "not_found"):
+		return utils.ErrorInvalid(appErr.Message).WithReason(utils.ReasonShareNotFound)
+	case strings.Contains(code, "expired"):
+		return utils.ErrorInvalid(appErr.Message).WithReason(utils.ReasonShareExpired)
+	case strings.Contains(code, "pass_code"):
+		return messageResult(appErr.Message, "")
+	}
+	return errorResult(err)
+}
+
+// extractParamsPikPak 从PikPak链接中提取分享ID和提取码
+// 链接形如 https://mypikpak.com/s/VOabc123，打开子目录时分享ID之后会带有文件ID
+func extractParamsPikPak(rawURL string) (shareID, passCode string, err error) {
+	parsedURL, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	if strings.TrimPrefix(parsedURL.Host, "www.") != "mypikpak.com" || !strings.HasPrefix(parsedURL.Path, "/s/") {
+		return "", "", fmt.Errorf("不是PikPak分享链接")
+	}
+
+	shareID, _, _ = strings.Cut(strings.TrimPrefix(parsedURL.Path, "/s/"), "/")
+	if shareID == "" {
+		return "", "", fmt.Errorf("无法寻找分享ID")
+	}
+
+	return shareID, parser.PasswordFromURL(rawURL), nil
+}
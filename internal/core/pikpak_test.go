@@ -0,0 +1,120 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"share-sniffer/internal/utils"
+)
+
+// 分享详情接口的响应样例
+var pikpakShareResponses = map[string]string{
+	"VPOk": `{"share_status":"OK","share_status_text":"","file_num":"3",
+		"user_info":{"nickname":"pikpak_user"},
+		"files":[{"name":"电影","size":"0","kind":"drive#folder"},{"name":"字幕.srt","size":"4096","kind":"drive#file"}]}`,
+	"VPLock":    `{"share_status":"PASS_CODE_EMPTY","share_status_text":"","files":[]}`,
+	"VPWrong":   `{"share_status":"PASS_CODE_ERROR","share_status_text":"","files":[]}`,
+	"VPGone":    `{"share_status":"NOT_FOUND","share_status_text":"","files":[]}`,
+	"VPExpired": `{"share_status":"EXPIRED","share_status_text":"","files":[]}`,
+	"VPBanned":  `{"share_status":"SENSITIVE_RESOURCE","share_status_text":"","files":[]}`,
+}
+
+func TestPikPakChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/shield/captcha/init":
+			var body struct {
+				ClientID string `json:"client_id"`
+				Action   string `json:"action"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.ClientID != pikpakShield.clientID || body.Action != pikpakShield.action {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"invalid_argument","error_description":"参数错误"}`))
+				return
+			}
+			w.Write([]byte(`{"captcha_token":"ck0.pikpak","expires_in":300}`))
+		case "/drive/v1/share":
+			if r.Header.Get("x-captcha-token") != "ck0.pikpak" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"captcha_invalid","error_code":9,"error_description":"验证码无效"}`))
+				return
+			}
+			shareID := r.URL.Query().Get("share_id")
+			if shareID == "VPLock" && r.URL.Query().Get("pass_code") == "ab12" {
+				shareID = "VPOk"
+			}
+			body, ok := pikpakShareResponses[shareID]
+			if !ok {
+				// 分享ID不存在时接口直接返回错误
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":"share_not_found","error_code":404,"error_description":"Share not found"}`))
+				return
+			}
+			w.Write([]byte(body))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apiBase, userBase := pikpakShield.apiBase, pikpakShield.userBase
+	pikpakShield.apiBase, pikpakShield.userBase = server.URL, server.URL
+	defer func() { pikpakShield.apiBase, pikpakShield.userBase = apiBase, userBase }()
+
+	checker := &PikPakChecker{}
+	tests := []struct {
+		url        string
+		wantError  utils.ErrorType
+		wantReason string
+	}{
+		{"https://mypikpak.com/s/VPOk", utils.Valid, utils.ReasonOK},
+		{"https://mypikpak.com/s/VPOk/VFileID", utils.Valid, utils.ReasonOK},
+		{"https://mypikpak.com/s/VPLock", utils.NeedPassword, utils.ReasonPasscodeRequired},
+		{"https://mypikpak.com/s/VPLock?pwd=ab12", utils.Valid, utils.ReasonOK},
+		{"https://mypikpak.com/s/VPWrong?pwd=0000", utils.NeedPassword, utils.ReasonWrongPasscode},
+		{"https://mypikpak.com/s/VPGone", utils.Invalid, utils.ReasonShareNotFound},
+		{"https://mypikpak.com/s/VPMissing", utils.Invalid, utils.ReasonShareNotFound},
+		{"https://mypikpak.com/s/VPExpired", utils.Invalid, utils.ReasonShareExpired},
+		{"https://mypikpak.com/s/VPBanned", utils.Banned, utils.ReasonBanned},
+		{"https://mypikpak.com/drive/all", utils.Malformed, utils.ReasonMalformedURL},
+	}
+	for _, tt := range tests {
+		got := checker.Check(context.Background(), tt.url)
+		if got.Error != tt.wantError || got.Reason != tt.wantReason {
+			t.Errorf("Check(%q) = %d %s %q, want %d %s", tt.url, got.Error, got.Reason, got.Msg, tt.wantError, tt.wantReason)
+		}
+	}
+
+	got := checker.Check(context.Background(), "https://mypikpak.com/s/VPOk")
+	if got.Data.Name != "电影" || got.Data.Meta == nil {
+		t.Fatalf("Check(VPOk) = %+v", got)
+	}
+	if meta := got.Data.Meta; meta.FileCount != 3 || meta.TotalSize != 4096 || meta.Creator != "pikpak_user" || len(meta.Entries) != 2 {
+		t.Errorf("Check(VPOk) meta = %+v", meta)
+	}
+}
+
+func TestExtractParamsPikPak(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantID   string
+		wantCode string
+		wantErr  bool
+	}{
+		{"https://mypikpak.com/s/VOabc123", "VOabc123", "", false},
+		{"https://mypikpak.com/s/VOabc123/VFile456", "VOabc123", "", false},
+		{"https://mypikpak.com/s/VOabc123?pwd=ab12", "VOabc123", "ab12", false},
+		{"https://mypikpak.com/s/", "", "", true},
+		{"https://mypikpak.com/drive/all", "", "", true},
+	}
+	for _, tt := range tests {
+		id, code, err := extractParamsPikPak(tt.url)
+		if (err != nil) != tt.wantErr || id != tt.wantID || code != tt.wantCode {
+			t.Errorf("extractParamsPikPak(%q) = %q, %q, %v, want %q, %q", tt.url, id, code, err, tt.wantID, tt.wantCode)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// plugin.go 实现了外部检查器插件
+// 插件是任意可执行文件，通过标准输入输出交换按行分隔的JSON：
+// 每行请求为 {"id":1,"url":"...","timeout_ms":5000}，插件按行返回带有相同id的检测结果（utils.Result 的字段加上id），
+// 多个请求可以同时进行，响应顺序不限。插件应在标准输入关闭时退出，标准错误输出会写入日志
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/utils"
+)
+
+const (
+	// pluginRestartInterval 插件两次启动之间的最短间隔，避免反复崩溃的插件占满CPU
+	pluginRestartInterval = time.Second
+	// pluginStopTimeout 关闭插件时等待其自行退出的时长，超时后强制结束
+	pluginStopTimeout = 2 * time.Second
+	// pluginMaxLine 插件单行响应的最大字节数
+	pluginMaxLine = 4 * 1024 * 1024
+	// pluginMaxTimeouts 常驻插件连续超时的次数达到该值时结束进程，下一次检测时重新启动
+	pluginMaxTimeouts = 3
+)
+
+// PluginFile 插件列表文件，支持TOML和JSON两种格式，按扩展名区分
+type PluginFile struct {
+	Plugins []Plugin `toml:"plugin" json:"plugins"`
+}
+
+// Plugin 一个外部检查器插件
+type Plugin struct {
+	Name     string   `toml:"name" json:"name"`         // 网盘标识
+	Command  string   `toml:"command" json:"command"`   // 可执行文件路径，相对路径按插件列表文件所在目录解析
+	Args     []string `toml:"args" json:"args"`         // 启动参数
+	Env      []string `toml:"env" json:"env"`           // 额外的环境变量，形如 KEY=VALUE
+	Prefixes []string `toml:"prefixes" json:"prefixes"` // 插件负责的链接前缀
+	Override bool     `toml:"override" json:"override"` // 是否替换已注册的同前缀检查器
+	OneShot  bool     `toml:"oneshot" json:"oneshot"`   // 每次检测启动新进程，默认保持进程常驻
+}
+
+// pluginRequest 发送给插件的检测请求
+type pluginRequest struct {
+	ID        uint64 `json:"id"`
+	URL       string `json:"url"`
+	TimeoutMs int64  `json:"timeout_ms,omitempty"` // 剩余的检测时间，没有截止时间时省略
+}
+
+// pluginResponse 插件返回的检测结果
+type pluginResponse struct {
+	ID uint64 `json:"id"`
+	utils.Result
+}
+
+// PluginChecker 把外部插件包装为检查器
+// 常驻模式下所有检测共用一个进程，进程退出或连续超时后在下一次检测时重新启动
+type PluginChecker struct {
+	plugin Plugin
+
+	mu        sync.Mutex
+	proc      *pluginProcess
+	lastStart time.Time
+	nextID    atomic.Uint64
+}
+
+// NewPluginChecker 根据插件配置创建检查器，进程在第一次检测时才启动
+//
+// 返回值:
+// - *PluginChecker: 检查器实例
+// - error: 插件缺少必填项时返回错误
+func NewPluginChecker(plugin Plugin) (*PluginChecker, error) {
+	if plugin.Name == "" {
+		return nil, fmt.Errorf("插件缺少name")
+	}
+	if plugin.Command == "" {
+		return nil, fmt.Errorf("插件 %s 缺少command", plugin.Name)
+	}
+	if len(plugin.Prefixes) == 0 {
+		return nil, fmt.Errorf("插件 %s 缺少prefixes", plugin.Name)
+	}
+	return &PluginChecker{plugin: plugin}, nil
+}
+
+// GetPrefix 获取插件负责的链接前缀
+func (q *PluginChecker) GetPrefix() []string {
+	return q.plugin.Prefixes
+}
+
+// Check 把链接发送给插件并等待结果
+func (q *PluginChecker) Check(ctx context.Context, urlStr string) utils.Result {
+	proc, err := q.process(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return utils.ErrorTimeout()
+		}
+		logger.Warn("PluginChecker:启动插件失败,%s,%v", q.plugin.Name, err)
+		return utils.ErrorFatal("插件启动失败")
+	}
+	if q.plugin.OneShot {
+		defer proc.stop()
+	}
+
+	req := pluginRequest{ID: q.nextID.Add(1), URL: urlStr}
+	if deadline, ok := ctx.Deadline(); ok {
+		req.TimeoutMs = time.Until(deadline).Milliseconds()
+	}
+	result := proc.check(ctx, req)
+	if !q.plugin.OneShot && proc.timeouts.Load() >= pluginMaxTimeouts {
+		q.kill(proc)
+	}
+	return result
+}
+
+// kill 结束卡住的常驻进程，下一次检测时重新启动
+func (q *PluginChecker) kill(proc *pluginProcess) {
+	q.mu.Lock()
+	if q.proc != proc {
+		// 其他检测已经结束了该进程
+		q.mu.Unlock()
+		return
+	}
+	q.proc = nil
+	q.mu.Unlock()
+
+	logger.Warn("PluginChecker:插件连续%d次超时，结束进程,%s", pluginMaxTimeouts, q.plugin.Name)
+	_ = proc.cmd.Process.Kill()
+}
+
+// process 返回可用的插件进程，常驻进程已退出时重新启动
+func (q *PluginChecker) process(ctx context.Context) (*pluginProcess, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.plugin.OneShot && q.proc != nil && !q.proc.exited() {
+		return q.proc, nil
+	}
+
+	// 崩溃后不立即重启，等待到最短间隔
+	if wait := pluginRestartInterval - time.Since(q.lastStart); !q.plugin.OneShot && !q.lastStart.IsZero() && wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	q.lastStart = time.Now()
+	proc, err := startPlugin(q.plugin)
+	if err != nil {
+		return nil, err
+	}
+	if !q.plugin.OneShot {
+		if q.proc != nil {
+			logger.Warn("PluginChecker:插件已重启,%s", q.plugin.Name)
+		}
+		q.proc = proc
+	}
+	return proc, nil
+}
+
+// Close 关闭常驻的插件进程
+func (q *PluginChecker) Close() {
+	q.mu.Lock()
+	proc := q.proc
+	q.proc = nil
+	q.mu.Unlock()
+
+	if proc != nil {
+		proc.stop()
+	}
+}
+
+// pluginProcess 一个运行中的插件进程
+type pluginProcess struct {
+	name  string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu  sync.Mutex
+	mu       sync.Mutex
+	pending  map[uint64]chan utils.Result
+	timeouts atomic.Int32  // 连续超时的次数，收到任意响应后清零
+	logged   chan struct{} // 标准错误输出读完后关闭
+	done     chan struct{} // 进程退出后关闭
+}
+
+// startPlugin 启动插件进程并开始读取其输出
+func startPlugin(plugin Plugin) (*pluginProcess, error) {
+	cmd := exec.Command(plugin.Command, plugin.Args...)
+	cmd.Env = append(os.Environ(), plugin.Env...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &pluginProcess{
+		name:    plugin.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[uint64]chan utils.Result),
+		logged:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go p.logStderr(stderr)
+	go p.readLoop(stdout)
+	return p, nil
+}
+
+// check 发送一个请求并等待对应的响应
+func (p *pluginProcess) check(ctx context.Context, req pluginRequest) utils.Result {
+	ch := make(chan utils.Result, 1)
+	p.mu.Lock()
+	p.pending[req.ID] = ch
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, req.ID)
+		p.mu.Unlock()
+	}()
+
+	line, err := json.Marshal(req)
+	if err != nil {
+		return utils.ErrorFatal("插件请求编码失败")
+	}
+	p.writeMu.Lock()
+	_, err = p.stdin.Write(append(line, '\n'))
+	p.writeMu.Unlock()
+	if err != nil {
+		logger.Warn("PluginChecker:写入插件失败,%s,%v", p.name, err)
+		return utils.ErrorFatal("插件已退出")
+	}
+
+	select {
+	case result := <-ch:
+		p.timeouts.Store(0)
+		return result
+	case <-p.done:
+		// 插件可能在退出前已返回结果
+		select {
+		case result := <-ch:
+			return result
+		default:
+		}
+		return utils.ErrorFatal("插件已退出")
+	case <-ctx.Done():
+		// 调用方主动取消不代表插件卡住
+		if ctx.Err() == context.DeadlineExceeded {
+			p.timeouts.Add(1)
+		}
+		return utils.ErrorTimeout()
+	}
+}
+
+// readLoop 逐行读取插件的响应，按id交给等待中的请求，进程退出后关闭done
+func (p *pluginProcess) readLoop(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), pluginMaxLine)
+	for scanner.Scan() {
+		var resp pluginResponse
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			logger.Warn("PluginChecker:无法解析插件响应,%s,%v", p.name, err)
+			continue
+		}
+
+		p.mu.Lock()
+		ch, ok := p.pending[resp.ID]
+		delete(p.pending, resp.ID)
+		p.mu.Unlock()
+		if ok {
+			ch <- resp.Result
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Warn("PluginChecker:读取插件输出失败,%s,%v", p.name, err)
+	}
+
+	// 输出结束后插件不再可用，结束进程并回收资源
+	_ = p.stdin.Close()
+	_ = p.cmd.Process.Kill()
+	select {
+	case <-p.logged:
+	case <-time.After(pluginStopTimeout):
+	}
+	if err := p.cmd.Wait(); err != nil {
+		logger.Debug("PluginChecker:插件已退出,%s,%v", p.name, err)
+	}
+	close(p.done)
+}
+
+// logStderr 把插件的标准错误输出写入日志
+func (p *pluginProcess) logStderr(stderr io.Reader) {
+	defer close(p.logged)
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		logger.Warn("PluginChecker:[%s] %s", p.name, scanner.Text())
+	}
+}
+
+// exited 进程是否已经退出
+func (p *pluginProcess) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop 关闭标准输入通知插件退出，超时后强制结束
+func (p *pluginProcess) stop() {
+	_ = p.stdin.Close()
+	select {
+	case <-p.done:
+	case <-time.After(pluginStopTimeout):
+		_ = p.cmd.Process.Kill()
+		<-p.done
+	}
+}
+
+// plugins 已注册的插件检查器，退出前需要关闭
+var (
+	pluginsMu sync.Mutex
+	plugins   []*PluginChecker
+)
+
+// LoadPlugins 读取插件列表文件并创建检查器
+//
+// 返回值:
+// - []*PluginChecker: 有效的插件检查器，无效的插件会被跳过
+// - error: 文件无法读取或解析时返回错误；存在无效插件时返回第一个错误，同时返回其余有效的检查器
+func LoadPlugins(path string) ([]*PluginChecker, error) {
+	var file PluginFile
+	if err := decodeConfigFile(path, &file); err != nil {
+		return nil, err
+	}
+
+	var firstErr error
+	checkers := make([]*PluginChecker, 0, len(file.Plugins))
+	for _, plugin := range file.Plugins {
+		if plugin.Command != "" && !filepath.IsAbs(plugin.Command) && strings.ContainsAny(plugin.Command, `/\`) {
+			plugin.Command = filepath.Join(filepath.Dir(path), plugin.Command)
+		}
+		checker, err := NewPluginChecker(plugin)
+		if err != nil {
+			logger.Warn("PluginChecker:跳过无效插件,%v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		checkers = append(checkers, checker)
+	}
+	return checkers, firstErr
+}
+
+// RegisterPlugins 读取插件列表文件并注册其中的插件，文件不存在时忽略
+//
+// 返回值:
+// - int: 注册的插件数量
+// - error: 文件无法解析、插件无效或前缀冲突时返回第一个错误，其余插件仍会注册
+func RegisterPlugins(path string) (int, error) {
+	checkers, err := LoadPlugins(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	firstErr := err
+
+	count := 0
+	for _, checker := range checkers {
+		if err := registerExternal(checker.plugin.Name, checker.plugin.Prefixes, checker.plugin.Override, checker); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		pluginsMu.Lock()
+		plugins = append(plugins, checker)
+		pluginsMu.Unlock()
+		logger.Info("PluginChecker:已注册插件,%s", checker.plugin.Name)
+		count++
+	}
+	return count, firstErr
+}
+
+// ClosePlugins 关闭所有常驻的插件进程，在程序退出前调用
+func ClosePlugins() {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, checker := range plugins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checker.Close()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,179 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"share-sniffer/internal/utils"
+)
+
+// TestPluginHelper 作为测试插件运行，由其他测试通过环境变量启动
+func TestPluginHelper(t *testing.T) {
+	if os.Getenv("SHARE_SNIFFER_TEST_PLUGIN") != "1" {
+		t.Skip("仅作为测试插件运行")
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	var mu sync.Mutex
+	for scanner.Scan() {
+		var req pluginRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			fmt.Fprintln(os.Stderr, "bad request:", err)
+			continue
+		}
+
+		reply := func(result utils.Result) {
+			line, _ := json.Marshal(pluginResponse{ID: req.ID, Result: result})
+			mu.Lock()
+			fmt.Println(string(line))
+			mu.Unlock()
+		}
+		switch {
+		case strings.HasSuffix(req.URL, "/ok"):
+			reply(utils.ErrorValid(fmt.Sprintf("pid-%d", os.Getpid())))
+		case strings.HasSuffix(req.URL, "/gone"):
+			reply(utils.ErrorInvalid("分享不存在").WithReason(utils.ReasonShareNotFound))
+		case strings.HasSuffix(req.URL, "/slow"):
+			// 不返回结果，等待调用方超时
+		case strings.HasSuffix(req.URL, "/crash"):
+			os.Exit(3)
+		}
+	}
+	os.Exit(0)
+}
+
+func newTestPlugin(t *testing.T, oneShot bool) *PluginChecker {
+	t.Helper()
+	checker, err := NewPluginChecker(Plugin{
+		Name:     "test",
+		Command:  os.Args[0],
+		Args:     []string{"-test.run=^TestPluginHelper$"},
+		Env:      []string{"SHARE_SNIFFER_TEST_PLUGIN=1"},
+		Prefixes: []string{"https://files.example.com/s/"},
+		OneShot:  oneShot,
+	})
+	if err != nil {
+		t.Fatalf("NewPluginChecker() error = %v", err)
+	}
+	t.Cleanup(checker.Close)
+	return checker
+}
+
+func TestPluginChecker(t *testing.T) {
+	checker := newTestPlugin(t, false)
+	ctx := context.Background()
+
+	got := checker.Check(ctx, "https://files.example.com/s/ok")
+	if got.Error != utils.Valid || !strings.HasPrefix(got.Data.Name, "pid-") {
+		t.Fatalf("Check(ok) = %+v", got)
+	}
+	first := got.Data.Name
+
+	// 常驻进程处理后续请求，并发请求按id分发
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := checker.Check(ctx, "https://files.example.com/s/gone"); got.Error != utils.Invalid || got.Reason != utils.ReasonShareNotFound {
+				t.Errorf("Check(gone) = %+v", got)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := checker.Check(ctx, "https://files.example.com/s/ok"); got.Data.Name != first {
+		t.Errorf("Check(ok) 使用了新进程 %s, want %s", got.Data.Name, first)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if got := checker.Check(timeoutCtx, "https://files.example.com/s/slow"); got.Error != utils.Timeout {
+		t.Errorf("Check(slow) = %+v, want timeout", got)
+	}
+
+	// 插件崩溃后返回错误，下一次检测自动重启
+	if got := checker.Check(ctx, "https://files.example.com/s/crash"); got.Error != utils.Fatal {
+		t.Errorf("Check(crash) = %+v, want fatal", got)
+	}
+	got = checker.Check(ctx, "https://files.example.com/s/ok")
+	if got.Error != utils.Valid || got.Data.Name == first {
+		t.Errorf("Check(ok) after crash = %+v, want restarted plugin", got)
+	}
+}
+
+func TestPluginCheckerTimeoutRestart(t *testing.T) {
+	checker := newTestPlugin(t, false)
+	first := checker.Check(context.Background(), "https://files.example.com/s/ok")
+
+	// 连续超时达到上限后结束卡住的进程
+	for range pluginMaxTimeouts {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		if got := checker.Check(ctx, "https://files.example.com/s/slow"); got.Error != utils.Timeout {
+			t.Errorf("Check(slow) = %+v, want timeout", got)
+		}
+		cancel()
+	}
+	got := checker.Check(context.Background(), "https://files.example.com/s/ok")
+	if got.Error != utils.Valid || got.Data.Name == first.Data.Name {
+		t.Errorf("Check(ok) after timeouts = %+v, want restarted plugin", got)
+	}
+}
+
+func TestPluginCheckerOneShot(t *testing.T) {
+	checker := newTestPlugin(t, true)
+
+	first := checker.Check(context.Background(), "https://files.example.com/s/ok")
+	second := checker.Check(context.Background(), "https://files.example.com/s/ok")
+	if first.Error != utils.Valid || second.Error != utils.Valid || first.Data.Name == second.Data.Name {
+		t.Errorf("Check() oneshot = %q %q, want two processes", first.Data.Name, second.Data.Name)
+	}
+}
+
+func TestLoadPlugins(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugins.toml")
+	content := `
+[[plugin]]
+name = "corp"
+command = "./bin/corp-checker"
+args = ["--stdio"]
+prefixes = ["https://files.corp.example/s/"]
+
+[[plugin]]
+name = "broken"
+prefixes = ["https://broken.example.com/s/"]
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	checkers, err := LoadPlugins(path)
+	if err == nil {
+		t.Errorf("LoadPlugins() 预期无效插件错误")
+	}
+	if len(checkers) != 1 {
+		t.Fatalf("LoadPlugins() = %d checkers, want 1", len(checkers))
+	}
+	if want := filepath.Join(dir, "bin", "corp-checker"); checkers[0].plugin.Command != want {
+		t.Errorf("LoadPlugins() command = %s, want %s", checkers[0].plugin.Command, want)
+	}
+
+	if count, err := RegisterPlugins(filepath.Join(dir, "missing.toml")); count != 0 || err != nil {
+		t.Errorf("RegisterPlugins() 文件不存在时应忽略, got %d %v", count, err)
+	}
+}
+
+func TestExamplePlugins(t *testing.T) {
+	checkers, err := LoadPlugins("../../config/plugins.example.toml")
+	if err != nil || len(checkers) != 1 {
+		t.Fatalf("LoadPlugins(plugins.example.toml) = %d checkers, %v", len(checkers), err)
+	}
+}
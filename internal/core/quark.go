@@ -35,14 +35,45 @@ func (q *QuarkChecker) GetPrefix() []string {
 	return config.GetSupportedQuark()
 }
 
+// ShareID 实现ShareIdentifier接口
+func (q *QuarkChecker) ShareID(urlStr string) string {
+	resourceID, _, _ := extractParamsQuark(urlStr)
+	return resourceID
+}
+
 // quarkResp 夸克API响应结构
 type quarkResp struct {
 	Status  int    `json:"status"`
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Data    struct {
-		Title string `json:"title"`
+		Title  string `json:"title"`
+		Stoken string `json:"stoken"`
+	} `json:"data"`
+}
+
+// quarkListPageSize 列举目录时每页的条目数
+const quarkListPageSize = 50
+
+// sharepageFile 夸克和UC网盘分享页接口返回的文件条目
+type sharepageFile struct {
+	Fid      string    `json:"fid"`
+	FileName string    `json:"file_name"`
+	Size     flexInt64 `json:"size"`
+	Dir      bool      `json:"dir"`
+}
+
+// quarkDetailResp 夸克分享目录接口响应结构
+type quarkDetailResp struct {
+	Status  int    `json:"status"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		List []sharepageFile `json:"list"`
 	} `json:"data"`
+	Metadata struct {
+		Total int `json:"_total"`
+	} `json:"metadata"`
 }
 
 // checkQuark 检测夸克网盘链接是否有效
@@ -63,23 +94,108 @@ func (q *QuarkChecker) checkQuark(ctx context.Context, urlStr string) utils.Resu
 	logger.Debug("QuarkChecker:请求完成，请求耗时: %v", requestElapsed)
 
 	if err != nil {
-		if errors.IsTimeoutError(err) {
-			return utils.ErrorTimeout()
-		}
 		if errors.IsStatusCodeError(err) {
 			return utils.ErrorInvalid("分享链接失效")
 		}
-		return utils.ErrorFatal("请求失败")
+		return errorResult(err)
 	}
 
-	// 检查API响应状态
+	// 检查API响应状态，根据提示信息区分需要提取码、违规等状态
 	if response.Status != http.StatusOK || response.Code != 0 {
-		return utils.ErrorInvalid("分享链接失效或不存在")
+		return messageResult(response.Message, "分享链接失效或不存在")
 	}
 
 	return utils.ErrorValid(response.Data.Title)
 }
 
+// List 实现Lister接口，先获取stoken，再按页请求各层目录
+func (q *QuarkChecker) List(ctx context.Context, urlStr string, opts ListOptions) ([]*ShareNode, bool, error) {
+	resourceID, passCode, err := extractParamsQuark(urlStr)
+	if err != nil {
+		return nil, false, resultError(utils.ErrorMalformed(urlStr, "链接格式无效"))
+	}
+
+	token, err := quarkRequest(ctx, resourceID, passCode)
+	if err != nil {
+		if errors.IsStatusCodeError(err) {
+			return nil, false, resultError(utils.ErrorInvalid("分享链接失效"))
+		}
+		return nil, false, err
+	}
+	if token.Status != http.StatusOK || token.Code != 0 {
+		return nil, false, resultError(messageResult(token.Message, "分享链接失效或不存在"))
+	}
+
+	return walkTree(ctx, func(ctx context.Context, dirID string, page int) ([]listEntry, bool, error) {
+		if dirID == "" {
+			dirID = "0"
+		}
+		response, err := quarkDetailRequest(ctx, resourceID, token.Data.Stoken, dirID, page)
+		if err != nil {
+			return nil, false, err
+		}
+		if response.Status != http.StatusOK || response.Code != 0 {
+			return nil, false, resultError(messageResult(response.Message, "获取分享内容失败"))
+		}
+		return sharepageEntries(response.Data.List), page*quarkListPageSize < response.Metadata.Total, nil
+	}, opts)
+}
+
+// sharepageEntries 把分享页接口返回的文件条目转换为目录条目
+func sharepageEntries(files []sharepageFile) []listEntry {
+	entries := make([]listEntry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, listEntry{id: f.Fid, name: f.FileName, size: int64(f.Size), isDir: f.Dir})
+	}
+	return entries
+}
+
+// quarkDetailRequest 按页获取夸克网盘分享中指定目录的文件列表
+func quarkDetailRequest(ctx context.Context, resourceID, stoken, dirID string, page int) (*quarkDetailResp, error) {
+	params := url.Values{}
+	params.Set("pr", "ucpro")
+	params.Set("fr", "pc")
+	params.Set("pwd_id", resourceID)
+	params.Set("stoken", stoken)
+	params.Set("pdir_fid", dirID)
+	params.Set("force", "0")
+	params.Set("_page", fmt.Sprintf("%d", page))
+	params.Set("_size", fmt.Sprintf("%d", quarkListPageSize))
+	params.Set("_fetch_total", "1")
+	params.Set("_sort", "file_type:asc,file_name:asc")
+	apiURL := "https://drive-h.quark.cn/1/clouddrive/share/sharepage/detail?" + params.Encode()
+
+	req, err := apphttp.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("origin", "https://pan.quark.cn")
+	req.Header.Set("referer", "https://pan.quark.cn/")
+
+	resp, err := apphttp.DoWithRetry(ctx, req, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("状态码: %d", resp.StatusCode)
+	}
+
+	var response quarkDetailResp
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, errors.NewParseError("解析JSON失败", err)
+	}
+
+	return &response, nil
+}
+
 // quarkRequest 获取夸克网盘分享信息
 func quarkRequest(ctx context.Context, resourceID string, passCode string) (*quarkResp, error) {
 	apiURL := "https://drive-h.quark.cn/1/clouddrive/share/sharepage/token"
@@ -113,8 +229,12 @@ func quarkRequest(ctx context.Context, resourceID string, passCode string) (*qua
 		return nil, err
 	}
 
-	// 检查HTTP状态码
+	// 检查HTTP状态码，失效链接的响应体中仍带有提示信息
 	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound {
+		var response quarkResp
+		if json.Unmarshal(body, &response) == nil && response.Message != "" {
+			return &response, nil
+		}
 		return nil, errors.NewStatusCodeError("链接已失效")
 	}
 
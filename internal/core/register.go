@@ -1,7 +1,15 @@
 package core
 
 import (
-	"share-sniffer/internal/utils"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/logger"
 )
 
 // 初始化检查器
@@ -9,30 +17,89 @@ func init() {
 	registerCheckers()
 }
 
+// builtinCheckers 内置的链接检查器，按注册顺序排列
+func builtinCheckers() []LinkChecker {
+	return []LinkChecker{
+		// 夸克网盘、电信云盘、百度网盘、阿里云盘、115网盘、123网盘、UC网盘
+		&QuarkChecker{},
+		&TelecomChecker{},
+		&BaiduChecker{},
+		&AliPanChecker{},
+		&YywChecker{},
+		&YesChecker{},
+		&UcChecker{},
+
+		// 迅雷网盘，接口检测失败且有可用浏览器时才使用浏览器
+		&XunleiChecker{},
+
+		// 移动云盘(139云盘)，接口检测失败且有可用浏览器时才使用浏览器
+		&YdChecker{},
+
+		// 蓝奏云、腾讯微云、PikPak、城通网盘
+		&LanzouChecker{},
+		&WeiyunChecker{},
+		&PikPakChecker{},
+		&CtfileChecker{},
+
+		// OneDrive、Google Drive、Dropbox和Mega
+		&OneDriveChecker{},
+		&GDriveChecker{},
+		&DropboxChecker{},
+		&MegaChecker{},
+	}
+}
+
 // registerCheckers 注册所有链接检查器
 // 使用sync.Once确保只执行一次初始化
+// 前缀来自配置文件，冲突的前缀记录错误日志而不是中止程序，其余前缀仍可使用
 func registerCheckers() {
 	once.Do(func() {
-		// 注册夸克网盘检查器
-		RegisterChecker(&QuarkChecker{})
-		// 注册电信云盘检查器
-		RegisterChecker(&TelecomChecker{})
-		// 注册百度网盘检查器
-		RegisterChecker(&BaiduChecker{})
-		// 注册阿里云盘检查器
-		RegisterChecker(&AliPanChecker{})
-		// 注册115网盘检查器
-		RegisterChecker(&YywChecker{})
-		// 注册123网盘检查器
-		RegisterChecker(&YesChecker{})
-		// 注册UC网盘检查器
-		RegisterChecker(&UcChecker{})
-
-		if utils.IsDesktop() {
-			// 注册迅雷网盘检查器
-			RegisterChecker(&XunleiChecker{})
-			// 注册移动云盘(139云盘)检查器
-			RegisterChecker(&YdChecker{})
+		for _, checker := range builtinCheckers() {
+			if err := RegisterChecker(checker); err != nil {
+				logger.Error("LinkChecker:内置检查器%T的前缀无法注册，对应链接不会被检测,%v", checker, err)
+			}
 		}
 	})
 }
+
+// registerExternal 注册规则、脚本或插件声明的检查器
+// 前缀注册成功后才加入网盘列表，冲突的前缀不出现在支持的链接中
+//
+// 参数:
+// - name: 网盘标识
+// - prefixes: 检查器的链接前缀
+// - override: 前缀已被其他检查器注册时是否替换
+// - c: 检查器实例
+//
+// 返回值:
+// - error: 第一个注册失败的前缀的错误，其余前缀仍会注册
+func registerExternal(name string, prefixes []string, override bool, c LinkChecker) error {
+	// 内置检查器先注册，外部检查器只有声明 override 时才能替换它们
+	registerCheckers()
+
+	registered, err := registerPrefixes(c, prefixes, name, override)
+	if len(registered) > 0 {
+		config.RegisterProvider(name, registered)
+	}
+	return err
+}
+
+// decodeConfigFile 读取规则文件或插件列表，扩展名为 .json 时按JSON解析，否则按TOML解析
+//
+// 返回值:
+// - error: 文件无法读取时原样返回读取错误，便于调用方判断文件是否存在；无法解析时返回解析错误
+func decodeConfigFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, v)
+	} else {
+		err = toml.Unmarshal(data, v)
+	}
+	if err != nil {
+		return fmt.Errorf("解析%s失败: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// resolve.go 实现了短链接展开，输入不是受支持的链接时，
+// 先拆开跳转链接中携带的目标地址、跟随短链接的HTTP跳转，再按展开后的链接匹配检查器
+package core
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/parser"
+	"share-sniffer/internal/utils"
+)
+
+// resolveInput 展开输入文本中的短链接或跳转链接并匹配检查器，展开后的链接附带输入文本中的提取码
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - input: 用户输入的链接字符串，也可以是包含链接和提取码的分享文本
+//
+// 返回值:
+// - *route: 展开后的链接对应的路由，无法展开或展开后仍不受支持时为nil
+// - string: 按路由规范化后的链接
+// - string: 展开后的链接
+// - error: 跳转次数过多、出现循环或请求失败时返回错误
+func resolveInput(ctx context.Context, input string) (*route, string, string, error) {
+	raw := parser.FirstURL(input)
+	if raw == "" {
+		return nil, "", "", nil
+	}
+	resolved, err := resolveLink(ctx, raw)
+	if err != nil || resolved == "" {
+		return nil, "", "", err
+	}
+
+	r, _ := routes.match(resolved)
+	if r == nil {
+		return nil, "", "", nil
+	}
+	resolved = parser.WithPassword(resolved, r.provider, parser.PasswordFromText(input))
+	r, normalizedURL := routes.match(resolved)
+	logger.Debug("Resolver:链接展开为: %s -> %s", raw, resolved)
+	return r, normalizedURL, resolved, nil
+}
+
+// resolveLink 展开链接，直到得到受支持的链接
+// 跳转链接直接取出查询参数中的目标地址，短链接主机使用不跟随重定向的客户端逐次请求，
+// 进入跳转后不再限制主机，跳转次数受配置限制，同一地址出现两次时视为循环
+//
+// 返回值:
+// - string: 受支持的链接，无法展开为受支持的链接时为空
+// - error: 跳转次数过多、出现循环或请求失败时返回错误
+func resolveLink(ctx context.Context, raw string) (string, error) {
+	current := raw
+	if !strings.Contains(current, "://") {
+		current = "https://" + current
+	}
+
+	visited := make(map[string]bool)
+	followed := false
+	for hops := 0; ; {
+		if r, _ := routes.match(current); r != nil {
+			return current, nil
+		}
+		if visited[current] {
+			return "", resultError(utils.ErrorMalformed(raw, "短链接跳转出现循环").WithReason(utils.ReasonUnsupported))
+		}
+		visited[current] = true
+
+		if target := redirectTarget(current); target != "" {
+			current = target
+			continue
+		}
+
+		u, err := url.Parse(current)
+		if err != nil {
+			return "", nil
+		}
+		if !followed && !config.IsShortLinkHost(parser.NormalizeHost(u.Host)) {
+			return "", nil
+		}
+		if hops >= config.GetResolverMaxHops() {
+			return "", resultError(utils.ErrorMalformed(raw, "短链接跳转次数过多").WithReason(utils.ReasonUnsupported))
+		}
+		hops++
+
+		page, err := fetchSharePage(ctx, current)
+		if err != nil {
+			return "", err
+		}
+		next := page.Location
+		if next == "" {
+			next = page.metaRefresh(current)
+		}
+		if next == "" {
+			// 跳转终点仍不受支持
+			return "", nil
+		}
+		followed = true
+		current = next
+	}
+}
+
+// redirectTarget 返回跳转链接查询参数中携带的目标地址，不是跳转链接时为空
+// 只拆解配置中列出的主机，并且只读取该主机对应的参数，避免把普通链接的参数当作目标地址
+// 目标地址被重复编码时再解码一次，如 link.zhihu.com/?target=https%253A//pan.baidu.com/s/1
+func redirectTarget(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	key := config.GetRedirectParam(parser.NormalizeHost(u.Host))
+	if key == "" {
+		return ""
+	}
+	target := u.Query().Get(key)
+	if !strings.Contains(target, "://") {
+		if unescaped, err := url.QueryUnescape(target); err == nil {
+			target = unescaped
+		}
+	}
+	if t, err := url.Parse(target); err == nil && (t.Scheme == "http" || t.Scheme == "https") && t.Host != "" {
+		return target
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/utils"
+)
+
+func TestResolveLink(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch {
+		case r.URL.Path == "/s1":
+			http.Redirect(w, r, "/s2", http.StatusFound)
+		case r.URL.Path == "/s2":
+			http.Redirect(w, r, "https://pan.quark.cn/s/0592e1dbe475", http.StatusMovedPermanently)
+		case r.URL.Path == "/wrap":
+			http.Redirect(w, r, "https://link.zhihu.com/?target=https%3A%2F%2Fpan.baidu.com%2Fs%2F1xyz", http.StatusFound)
+		case r.URL.Path == "/meta":
+			w.Write([]byte(`<html><head><meta http-equiv="refresh" content="0; url=https://pan.baidu.com/s/1abc"></head></html>`))
+		case r.URL.Path == "/loop1":
+			http.Redirect(w, r, "/loop2", http.StatusFound)
+		case r.URL.Path == "/loop2":
+			http.Redirect(w, r, "/loop1", http.StatusFound)
+		case strings.HasPrefix(r.URL.Path, "/chain/"):
+			n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/chain/"))
+			http.Redirect(w, r, "/chain/"+strconv.Itoa(n+1), http.StatusFound)
+		default:
+			w.Write([]byte(`<html><body>not a share</body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	// 测试服务器作为短链接主机
+	serverURL, _ := url.Parse(server.URL)
+	cfg := &config.GetConfig().ResolverConfig
+	hosts := cfg.ShortHosts
+	cfg.ShortHosts = append([]string{serverURL.Hostname()}, hosts...)
+	defer func() { cfg.ShortHosts = hosts }()
+
+	tests := []struct {
+		url     string
+		want    string
+		wantErr string
+	}{
+		{server.URL + "/s1", "https://pan.quark.cn/s/0592e1dbe475", ""},
+		{server.URL + "/wrap", "https://pan.baidu.com/s/1xyz", ""},
+		{server.URL + "/meta", "https://pan.baidu.com/s/1abc", ""},
+		{"https://www.jianshu.com/go-wild?ac=2&url=https%3A%2F%2Fpan.quark.cn%2Fs%2Fabc", "https://pan.quark.cn/s/abc", ""},
+		{"https://pan.quark.cn/s/abc", "https://pan.quark.cn/s/abc", ""},
+		{server.URL + "/end", "", ""},
+		{server.URL + "/loop1", "", "短链接跳转出现循环"},
+		{server.URL + "/chain/0", "", "短链接跳转次数过多"},
+	}
+	for _, tt := range tests {
+		got, err := resolveLink(context.Background(), tt.url)
+		var resErr *ResultError
+		switch {
+		case tt.wantErr != "":
+			if !stderrors.As(err, &resErr) || resErr.Result.Msg != tt.wantErr || resErr.Result.Reason != utils.ReasonUnsupported {
+				t.Errorf("resolveLink(%q) error = %v, want %s", tt.url, err, tt.wantErr)
+			}
+		case err != nil || got != tt.want:
+			t.Errorf("resolveLink(%q) = %q, %v, want %q", tt.url, got, err, tt.want)
+		}
+	}
+
+	// 其他主机的链接不发送请求
+	requests.Store(0)
+	if got, err := resolveLink(context.Background(), "https://example.com/abc"); got != "" || err != nil || requests.Load() != 0 {
+		t.Errorf("resolveLink(example.com) = %q, %v, %d requests", got, err, requests.Load())
+	}
+
+	// 展开后的链接附带分享文本中的提取码
+	r, normalizedURL, resolved, err := resolveInput(context.Background(), "链接：https://link.zhihu.com/?target=https%3A%2F%2Fpan.quark.cn%2Fs%2F0592e1dbe475 提取码: ab12")
+	if err != nil || r == nil || r.provider != "quark" || resolved != "https://pan.quark.cn/s/0592e1dbe475?pwd=ab12" || normalizedURL != resolved {
+		t.Errorf("resolveInput() = %v, %q, %q, %v", r, normalizedURL, resolved, err)
+	}
+}
+
+func TestRedirectTarget(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://link.zhihu.com/?target=https%3A//pan.baidu.com/s/1abc", "https://pan.baidu.com/s/1abc"},
+		{"https://weibo.cn/sinaurl?u=https%253A%252F%252Fpan.quark.cn%252Fs%252Fabc", "https://pan.quark.cn/s/abc"},
+		{"https://www.douban.com/link2/?url=http://pan.baidu.com/s/1abc", "http://pan.baidu.com/s/1abc"},
+		{"https://c.pc.qq.com/middlem.html?pfurl=https%3A%2F%2Fpan.quark.cn%2Fs%2Fabc", "https://pan.quark.cn/s/abc"},
+		// 未列出的主机和参数不拆解
+		{"https://bbs.example.com/plugin.php?id=link&url=http://pan.baidu.com/s/1abc", ""},
+		{"https://link.zhihu.com/?url=https%3A//pan.baidu.com/s/1abc", ""},
+		{"https://example.com/search?url=keyword", ""},
+		{"https://example.com/go?to=javascript:alert(1)", ""},
+		{"https://example.com/s/abc", ""},
+	}
+	for _, tt := range tests {
+		if got := redirectTarget(tt.url); got != tt.want {
+			t.Errorf("redirectTarget(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
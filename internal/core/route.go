@@ -0,0 +1,169 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// route.go 实现了检查器的路由表，根据URL的主机和路径选择最长匹配前缀的检查器
+// 匹配时忽略协议(http/https)、主机大小写、www. 前缀以及链接末尾的多余字符，*.example.com 形式的主机匹配所有子域名
+package core
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/parser"
+)
+
+// linkTrailingJunk 链接末尾常见的多余字符（复制粘贴时带入的标点）
+const linkTrailingJunk = ".,;:!?'\")]}>，。；：！？、）】》」』”’"
+
+// route 路由表中的一条记录
+type route struct {
+	prefix   string      // 注册时的原始前缀
+	scheme   string      // 前缀的协议，用于生成规范化URL
+	rawHost  string      // 前缀的原始主机，用于生成规范化URL
+	host     string      // 规范化后的主机（小写、去掉www.和端口）
+	path     string      // 路径前缀
+	provider string      // 网盘标识，对应config.Providers的键
+	checker  LinkChecker // 对应的检查器
+}
+
+// routeTable 检查器路由表
+// 记录先按主机排列：确定的主机在前，通配主机按后缀长度降序；主机相同时按路径前缀长度降序，
+// 其余按注册顺序排列，保证匹配结果确定
+type routeTable struct {
+	mu     sync.RWMutex
+	routes []*route
+}
+
+// newRouteTable 创建空的路由表
+func newRouteTable() *routeTable {
+	return &routeTable{}
+}
+
+// add 添加一条路由
+// 同一主机和路径已被其他类型的检查器注册时返回错误，保留先注册的检查器
+func (t *routeTable) add(prefix string, checker LinkChecker) error {
+	return t.insert(prefix, "", checker, false)
+}
+
+// replace 添加一条路由，同一主机和路径已被注册时替换为新的检查器
+func (t *routeTable) replace(prefix string, checker LinkChecker) error {
+	return t.insert(prefix, "", checker, true)
+}
+
+// insert 添加一条路由，override为true时替换已注册的其他类型检查器
+// provider为空时按前缀从配置中查找网盘标识
+func (t *routeTable) insert(prefix, provider string, checker LinkChecker, override bool) error {
+	u, err := parser.ParseLink(prefix)
+	if err != nil {
+		return fmt.Errorf("无效的前缀: %w", err)
+	}
+	if provider == "" {
+		provider = config.GetProvider(prefix)
+	}
+
+	r := &route{
+		prefix:   prefix,
+		scheme:   strings.ToLower(u.Scheme),
+		rawHost:  strings.ToLower(u.Host),
+		host:     parser.NormalizeHost(u.Host),
+		path:     u.Path,
+		provider: provider,
+		checker:  checker,
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, existing := range t.routes {
+		if existing.host != r.host || existing.path != r.path {
+			continue
+		}
+		if override || reflect.TypeOf(existing.checker) == reflect.TypeOf(checker) {
+			// 同类型检查器重复注册或显式替换，使用新实例
+			t.routes[i] = r
+			return nil
+		}
+		return fmt.Errorf("前缀与 %s(%T) 冲突", existing.prefix, existing.checker)
+	}
+
+	t.routes = append(t.routes, r)
+	sort.SliceStable(t.routes, func(i, j int) bool {
+		if hi, hj := hostRank(t.routes[i].host), hostRank(t.routes[j].host); hi != hj {
+			return hi > hj
+		}
+		return len(t.routes[i].path) > len(t.routes[j].path)
+	})
+	return nil
+}
+
+// hostRank 主机的匹配优先级，确定的主机高于所有通配主机，通配主机的后缀越长优先级越高
+func hostRank(host string) int {
+	if suffix, ok := strings.CutPrefix(host, "*."); ok {
+		return len(suffix)
+	}
+	return math.MaxInt
+}
+
+// match 查找URL对应的路由，并返回按注册前缀规范化后的URL
+func (t *routeTable) match(urlStr string) (*route, string) {
+	cleaned := cleanLink(urlStr)
+	u, err := parser.ParseLink(cleaned)
+	if err != nil {
+		return nil, ""
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, r := range t.routes {
+		if parser.MatchPrefix(r.host, r.path, u) {
+			// 通配主机的前缀保留链接原来的主机
+			rawHost := r.rawHost
+			if strings.HasPrefix(rawHost, "*.") {
+				rawHost = strings.ToLower(u.Host)
+			}
+			return r, r.scheme + "://" + rawHost + linkRest(cleaned)
+		}
+	}
+	return nil, ""
+}
+
+// cleanLink 去掉链接前后的空白、空白之后的内容以及末尾的多余标点
+func cleanLink(urlStr string) string {
+	s := strings.TrimSpace(urlStr)
+	if idx := strings.IndexFunc(s, unicode.IsSpace); idx != -1 {
+		s = s[:idx]
+	}
+	return strings.TrimRight(s, linkTrailingJunk)
+}
+
+// lastPathSegment 返回链接路径的最后一段，常用作分享ID
+func lastPathSegment(urlStr string) string {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return ""
+	}
+	segment := path.Base(strings.TrimRight(u.Path, "/"))
+	if segment == "." || segment == "/" {
+		return ""
+	}
+	return segment
+}
+
+// linkRest 返回链接中主机之后的部分（路径、查询参数和片段），保持原始编码
+func linkRest(s string) string {
+	if idx := strings.Index(s, "://"); idx != -1 {
+		s = s[idx+3:]
+	}
+	if idx := strings.IndexAny(s, "/?#"); idx != -1 {
+		return s[idx:]
+	}
+	return ""
+}
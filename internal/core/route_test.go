@@ -0,0 +1,209 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"share-sniffer/internal/utils"
+)
+
+// fakeChecker 测试用检查器
+type fakeChecker struct {
+	prefixes []string
+}
+
+func (f *fakeChecker) Check(ctx context.Context, urlStr string) utils.Result {
+	return utils.ErrorValid(urlStr)
+}
+
+func (f *fakeChecker) GetPrefix() []string {
+	return f.prefixes
+}
+
+// otherChecker 与fakeChecker类型不同的测试用检查器
+type otherChecker struct {
+	fakeChecker
+}
+
+func TestRouteTableLongestPrefix(t *testing.T) {
+	table := newRouteTable()
+	short := &fakeChecker{}
+	long := &otherChecker{}
+
+	if err := table.add("https://example.com/s/", short); err != nil {
+		t.Fatalf("add() error = %v", err)
+	}
+	if err := table.add("https://example.com/s/special/", long); err != nil {
+		t.Fatalf("add() error = %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		got, _ := table.match("https://example.com/s/special/abc")
+		if got == nil || got.checker != LinkChecker(long) {
+			t.Fatalf("match() 未选择最长前缀，第%d次", i)
+		}
+		got, _ = table.match("https://example.com/s/abc")
+		if got == nil || got.checker != LinkChecker(short) {
+			t.Fatalf("match() 未选择较短前缀，第%d次", i)
+		}
+	}
+}
+
+func TestRouteTableExactHost(t *testing.T) {
+	table := newRouteTable()
+	lanzou := &LanzouChecker{}
+	for _, prefix := range lanzou.GetPrefix() {
+		if err := table.add(prefix, lanzou); err != nil {
+			t.Fatalf("add(%s) error = %v", prefix, err)
+		}
+	}
+	special := &otherChecker{}
+	if err := table.add("https://special.lanzoux.com/", special); err != nil {
+		t.Fatalf("add() error = %v", err)
+	}
+
+	// 确定的主机优先于路径更长的通配主机
+	got, _ := table.match("https://special.lanzoux.com/iAbc123")
+	if got == nil || got.checker != LinkChecker(special) {
+		t.Errorf("match(special.lanzoux.com) = %+v, want exact host", got)
+	}
+	got, _ = table.match("https://wwi.lanzoux.com/iAbc123")
+	if got == nil || got.checker != LinkChecker(lanzou) {
+		t.Errorf("match(wwi.lanzoux.com) = %+v, want lanzou", got)
+	}
+}
+
+func TestRouteTableAmbiguous(t *testing.T) {
+	table := newRouteTable()
+	if err := table.add("https://www.example.com/s/", &fakeChecker{}); err != nil {
+		t.Fatalf("add() error = %v", err)
+	}
+	if err := table.add("http://EXAMPLE.com/s/", &otherChecker{}); err == nil {
+		t.Errorf("add() 预期冲突错误，但实际没有错误")
+	}
+	if err := table.add("https://example.com/s/", &fakeChecker{}); err != nil {
+		t.Errorf("add() 同类型重复注册不应报错: %v", err)
+	}
+	if err := table.add("ftp://example.com/s/", &fakeChecker{}); err == nil {
+		t.Errorf("add() 预期协议错误，但实际没有错误")
+	}
+}
+
+func TestBuiltinCheckersRegister(t *testing.T) {
+	table := newRouteTable()
+	for _, checker := range builtinCheckers() {
+		for _, prefix := range checker.GetPrefix() {
+			if err := table.add(prefix, checker); err != nil {
+				t.Errorf("add(%s, %T) error = %v", prefix, checker, err)
+			}
+		}
+	}
+}
+
+func TestResolveChecker(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    LinkChecker
+		wantURL string
+	}{
+		{
+			name:    "standard",
+			url:     "https://pan.quark.cn/s/0592e1dbe475",
+			want:    &QuarkChecker{},
+			wantURL: "https://pan.quark.cn/s/0592e1dbe475",
+		},
+		{
+			name:    "http and uppercase host",
+			url:     "http://PAN.QUARK.CN/s/0592e1dbe475?pwd=D3eM",
+			want:    &QuarkChecker{},
+			wantURL: "https://pan.quark.cn/s/0592e1dbe475?pwd=D3eM",
+		},
+		{
+			name:    "without www",
+			url:     "https://123865.com/s/abc-def",
+			want:    &YesChecker{},
+			wantURL: "https://www.123865.com/s/abc-def",
+		},
+		{
+			name:    "without scheme",
+			url:     "pan.baidu.com/s/1oq-lgc1uqwwuCAA3PmVRWQ?pwd=MCPH",
+			want:    &BaiduChecker{},
+			wantURL: "https://pan.baidu.com/s/1oq-lgc1uqwwuCAA3PmVRWQ?pwd=MCPH",
+		},
+		{
+			name:    "trailing junk",
+			url:     "  https://www.alipan.com/s/Xd4HxfpMdVk， 来自阿里云盘",
+			want:    &AliPanChecker{},
+			wantURL: "https://www.alipan.com/s/Xd4HxfpMdVk",
+		},
+		{
+			name:    "telecom query prefix",
+			url:     "https://cloud.189.cn/web/share?code=eMJZVvUnUbaq",
+			want:    &TelecomChecker{},
+			wantURL: "https://cloud.189.cn/web/share?code=eMJZVvUnUbaq",
+		},
+		{
+			name:    "lanzou wildcard subdomain",
+			url:     "https://WWI.lanzoux.com/iAbC123 密码:ab12",
+			want:    &LanzouChecker{},
+			wantURL: "https://wwi.lanzoux.com/iAbC123",
+		},
+		{
+			name:    "lanzou bare domain",
+			url:     "lanzouw.com/b0abc123",
+			want:    &LanzouChecker{},
+			wantURL: "https://lanzouw.com/b0abc123",
+		},
+		{
+			name:    "dropbox without www",
+			url:     "https://dropbox.com/scl/fi/abc123/photo.jpg?rlkey=k1&dl=0",
+			want:    &DropboxChecker{},
+			wantURL: "https://www.dropbox.com/scl/fi/abc123/photo.jpg?rlkey=k1&dl=0",
+		},
+		{
+			name:    "google docs",
+			url:     "https://docs.google.com/document/d/1AbCdEfGhIjK/edit",
+			want:    &GDriveChecker{},
+			wantURL: "https://docs.google.com/document/d/1AbCdEfGhIjK/edit",
+		},
+		{
+			name:    "mega legacy fragment",
+			url:     "https://mega.nz/#!AbCd1234!k3y",
+			want:    &MegaChecker{},
+			wantURL: "https://mega.nz/#!AbCd1234!k3y",
+		},
+		{
+			name: "unsupported path",
+			url:  "https://pan.quark.cn/list",
+			want: nil,
+		},
+		{
+			name: "unsupported host",
+			url:  "https://example.com/s/abc",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotURL := ResolveChecker(tt.url)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("ResolveChecker() = %T, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("ResolveChecker() = nil, want %T", tt.want)
+			}
+			if gotType, wantType := fmt.Sprintf("%T", got), fmt.Sprintf("%T", tt.want); gotType != wantType {
+				t.Errorf("ResolveChecker() = %s, want %s", gotType, wantType)
+			}
+			if gotURL != tt.wantURL {
+				t.Errorf("ResolveChecker() url = %s, want %s", gotURL, tt.wantURL)
+			}
+		})
+	}
+}
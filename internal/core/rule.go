@@ -0,0 +1,440 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// rule.go 实现了由规则文件声明的检查器
+// 大多数网盘的检测流程相同：用正则从链接中提取分享ID，发送一个JSON请求，读取状态字段和标题字段，
+// RuleChecker 把这些步骤写成规则，新增或修补网盘时只需修改规则文件，无需重新编译
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	apphttp "share-sniffer/internal/http"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/parser"
+	"share-sniffer/internal/utils"
+)
+
+// ruleMaxBody 规则检查器读取响应的最大字节数
+const ruleMaxBody = 4 * 1024 * 1024
+
+// RuleFile 规则文件，支持TOML和JSON两种格式，按扩展名区分
+type RuleFile struct {
+	Checkers []Rule `toml:"checker" json:"checkers"`
+}
+
+// Rule 一个网盘的检测规则
+//
+// 模板中的 {{name}} 会被替换为链接正则中同名捕获组的值，另外提供:
+// - {{url}}: 规范化后的链接
+// - {{pwd}}: 提取码，正则中没有pwd捕获组时从链接参数中读取
+type Rule struct {
+	Name     string       `toml:"name" json:"name"`         // 网盘标识
+	Prefixes []string     `toml:"prefixes" json:"prefixes"` // 链接前缀
+	Pattern  string       `toml:"pattern" json:"pattern"`   // 链接正则，使用命名捕获组提取参数
+	ShareID  string       `toml:"share_id" json:"share_id"` // 分享ID模板，默认为 {{id}}
+	Override bool         `toml:"override" json:"override"` // 是否替换已注册的同前缀检查器
+	Request  RuleRequest  `toml:"request" json:"request"`
+	Response RuleResponse `toml:"response" json:"response"`
+}
+
+// RuleRequest 检测请求模板
+type RuleRequest struct {
+	Method  string            `toml:"method" json:"method"`   // 请求方法，默认为GET
+	URL     string            `toml:"url" json:"url"`         // 请求地址模板，参数值会被URL编码
+	Headers map[string]string `toml:"headers" json:"headers"` // 请求头模板，参数值中的换行会被去掉
+	Body    string            `toml:"body" json:"body"`       // 请求体模板，JSON请求体中的参数值按JSON字符串转义，其余按URL编码
+}
+
+// RuleResponse 响应解析规则
+// 选择器形如 $.data.list[0].name，$. 前缀可以省略
+//
+// 结果状态写作 valid、invalid、need_password、login_required、rate_limited、banned、malformed、fatal，
+// 可以在冒号后附带原因码，如 invalid:share_expired
+type RuleResponse struct {
+	Status     string            `toml:"status" json:"status"`           // 状态字段选择器，为空时只按HTTP状态码判断
+	Success    []string          `toml:"success" json:"success"`         // 表示有效的状态值
+	Title      string            `toml:"title" json:"title"`             // 标题选择器
+	Message    string            `toml:"message" json:"message"`         // 提示信息选择器
+	States     map[string]string `toml:"states" json:"states"`           // 状态值对应的结果状态，未列出时按提示信息判断
+	HTTPStates map[string]string `toml:"http_states" json:"http_states"` // HTTP状态码对应的结果状态
+	FileCount  string            `toml:"file_count" json:"file_count"`   // 文件数量选择器（可选）
+	TotalSize  string            `toml:"total_size" json:"total_size"`   // 总大小选择器（可选）
+	Creator    string            `toml:"creator" json:"creator"`         // 分享者选择器（可选）
+}
+
+// ruleState 规则中声明的结果状态
+type ruleState struct {
+	status utils.ErrorType
+	reason string
+}
+
+// ruleStates 规则中可用的结果状态名称
+var ruleStates = map[string]utils.ErrorType{
+	"valid":          utils.Valid,
+	"invalid":        utils.Invalid,
+	"need_password":  utils.NeedPassword,
+	"login_required": utils.LoginRequired,
+	"rate_limited":   utils.RateLimited,
+	"banned":         utils.Banned,
+	"malformed":      utils.Malformed,
+	"fatal":          utils.Fatal,
+}
+
+// templateRegex 匹配模板中的 {{name}}
+var templateRegex = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// RuleChecker 由规则驱动的检查器
+type RuleChecker struct {
+	rule       Rule
+	pattern    *regexp.Regexp
+	states     map[string]ruleState
+	httpStates map[int]ruleState
+}
+
+// NewRuleChecker 根据规则创建检查器
+//
+// 返回值:
+// - *RuleChecker: 检查器实例
+// - error: 规则缺少必填项、正则或状态名称无效时返回错误
+func NewRuleChecker(rule Rule) (*RuleChecker, error) {
+	if rule.Name == "" {
+		return nil, fmt.Errorf("规则缺少name")
+	}
+	if len(rule.Prefixes) == 0 {
+		return nil, fmt.Errorf("规则 %s 缺少prefixes", rule.Name)
+	}
+	if rule.Request.URL == "" {
+		return nil, fmt.Errorf("规则 %s 缺少request.url", rule.Name)
+	}
+	pattern, err := regexp.Compile(rule.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("规则 %s 的pattern无效: %w", rule.Name, err)
+	}
+	if rule.ShareID == "" {
+		rule.ShareID = "{{id}}"
+	}
+	if rule.Request.Method == "" {
+		rule.Request.Method = http.MethodGet
+	}
+
+	c := &RuleChecker{
+		rule:       rule,
+		pattern:    pattern,
+		states:     make(map[string]ruleState, len(rule.Response.States)),
+		httpStates: make(map[int]ruleState, len(rule.Response.HTTPStates)),
+	}
+	for value, name := range rule.Response.States {
+		state, err := parseRuleState(name)
+		if err != nil {
+			return nil, fmt.Errorf("规则 %s 的states.%s无效: %w", rule.Name, value, err)
+		}
+		c.states[value] = state
+	}
+	for code, name := range rule.Response.HTTPStates {
+		statusCode, err := strconv.Atoi(code)
+		if err != nil {
+			return nil, fmt.Errorf("规则 %s 的http_states.%s不是状态码", rule.Name, code)
+		}
+		state, err := parseRuleState(name)
+		if err != nil {
+			return nil, fmt.Errorf("规则 %s 的http_states.%s无效: %w", rule.Name, code, err)
+		}
+		c.httpStates[statusCode] = state
+	}
+	return c, nil
+}
+
+// parseRuleState 解析结果状态，如 invalid 或 invalid:share_expired
+func parseRuleState(s string) (ruleState, error) {
+	name, reason, _ := strings.Cut(strings.TrimSpace(s), ":")
+	status, ok := ruleStates[name]
+	if !ok {
+		return ruleState{}, fmt.Errorf("未知的结果状态 %q", name)
+	}
+	if reason == "" {
+		reason = utils.DefaultReason(status)
+	}
+	return ruleState{status: status, reason: reason}, nil
+}
+
+// Check 实现LinkChecker接口
+func (c *RuleChecker) Check(ctx context.Context, urlStr string) utils.Result {
+	logger.Debug("RuleChecker:开始检测%s链接: %s", c.rule.Name, urlStr)
+
+	vars, ok := c.vars(urlStr)
+	if !ok {
+		return utils.ErrorMalformed(urlStr, "链接格式无效")
+	}
+
+	req, err := c.newRequest(ctx, vars)
+	if err != nil {
+		return utils.ErrorMalformed(urlStr, "请求模板无效")
+	}
+
+	resp, err := apphttp.DoWithRetry(ctx, req, 0)
+	if err != nil {
+		logger.Info("RuleChecker:%s,%s,错误: %v\n", c.rule.Name, urlStr, err)
+		return errorResult(err)
+	}
+	defer apphttp.CloseResponse(resp)
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, ruleMaxBody))
+	if err != nil {
+		return utils.ErrorFatal("读取响应失败").WithReason(utils.ReasonRequestError)
+	}
+	return c.parseResponse(resp.StatusCode, body)
+}
+
+// GetPrefix 实现LinkChecker接口
+func (c *RuleChecker) GetPrefix() []string {
+	return c.rule.Prefixes
+}
+
+// ShareID 实现ShareIdentifier接口
+func (c *RuleChecker) ShareID(urlStr string) string {
+	vars, ok := c.vars(urlStr)
+	if !ok {
+		return ""
+	}
+	return renderTemplate(c.rule.ShareID, vars, nil)
+}
+
+// vars 从链接中提取模板参数
+func (c *RuleChecker) vars(urlStr string) (map[string]string, bool) {
+	m := c.pattern.FindStringSubmatch(urlStr)
+	if m == nil {
+		return nil, false
+	}
+	vars := map[string]string{"url": urlStr}
+	for i, name := range c.pattern.SubexpNames() {
+		if name != "" {
+			vars[name] = m[i]
+		}
+	}
+	if vars["pwd"] == "" {
+		vars["pwd"] = parser.PasswordFromURL(urlStr)
+	}
+	return vars, true
+}
+
+// newRequest 按请求模板创建请求
+func (c *RuleChecker) newRequest(ctx context.Context, vars map[string]string) (*http.Request, error) {
+	r := c.rule.Request
+	var body io.Reader
+	if r.Body != "" {
+		escape := url.QueryEscape
+		if isJSONBody(r) {
+			escape = jsonEscape
+		}
+		body = strings.NewReader(renderTemplate(r.Body, vars, escape))
+	}
+
+	req, err := apphttp.NewRequestWithContext(ctx, strings.ToUpper(r.Method), renderTemplate(r.URL, vars, url.QueryEscape), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range r.Headers {
+		req.Header.Set(k, renderTemplate(v, vars, headerEscape))
+	}
+	return req, nil
+}
+
+// isJSONBody 判断请求体是否为JSON，优先按Content-Type判断，未设置时看请求体是否以 { 或 [ 开头
+func isJSONBody(r RuleRequest) bool {
+	for k, v := range r.Headers {
+		if strings.EqualFold(k, "Content-Type") {
+			return strings.Contains(strings.ToLower(v), "json")
+		}
+	}
+	body := strings.TrimSpace(r.Body)
+	return strings.HasPrefix(body, "{") || strings.HasPrefix(body, "[")
+}
+
+// jsonEscape 按JSON字符串转义参数值，不含两侧的引号
+func jsonEscape(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
+
+// headerEscape 去掉参数值中的回车和换行，避免注入额外的请求头
+func headerEscape(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// parseResponse 按响应规则判断检测结果
+func (c *RuleChecker) parseResponse(statusCode int, body []byte) utils.Result {
+	if state, ok := c.httpStates[statusCode]; ok {
+		return statusResult(state.status, fmt.Sprintf("HTTP %d", statusCode)).WithReason(state.reason)
+	}
+	if statusCode < 200 || statusCode >= 300 {
+		return utils.ErrorFatal(fmt.Sprintf("状态码%d", statusCode)).WithReason(utils.ReasonUpstreamStatus)
+	}
+
+	r := c.rule.Response
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var data any
+	if err := decoder.Decode(&data); err != nil {
+		return utils.ErrorFatal("解析响应失败").WithReason(utils.ReasonParseError)
+	}
+
+	message := selectString(data, r.Message)
+	if r.Status != "" {
+		status := selectString(data, r.Status)
+		if !slices.Contains(r.Success, status) {
+			if state, ok := c.states[status]; ok {
+				if message == "" {
+					message = utils.ErrorToMsg(state.status)
+				}
+				return statusResult(state.status, message).WithReason(state.reason)
+			}
+			return messageResult(message, "分享链接失效")
+		}
+	}
+
+	result := utils.ErrorValid(selectString(data, r.Title))
+	if r.FileCount != "" || r.TotalSize != "" || r.Creator != "" {
+		result = result.WithMeta(&utils.Metadata{
+			FileCount: int(selectInt(data, r.FileCount)),
+			TotalSize: selectInt(data, r.TotalSize),
+			Creator:   selectString(data, r.Creator),
+		})
+	}
+	return result
+}
+
+// renderTemplate 替换模板中的 {{name}}，escape不为nil时对参数值编码
+func renderTemplate(tpl string, vars map[string]string, escape func(string) string) string {
+	return templateRegex.ReplaceAllStringFunc(tpl, func(m string) string {
+		value := vars[templateRegex.FindStringSubmatch(m)[1]]
+		if escape != nil {
+			return escape(value)
+		}
+		return value
+	})
+}
+
+// selectJSON 按选择器读取JSON中的值，选择器形如 $.data.list[0].name
+func selectJSON(data any, selector string) (any, bool) {
+	selector = strings.TrimPrefix(strings.TrimPrefix(selector, "$"), ".")
+	if selector == "" {
+		return data, true
+	}
+
+	for _, part := range strings.Split(selector, ".") {
+		name, indexes, _ := strings.Cut(part, "[")
+		if name != "" {
+			obj, ok := data.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if data, ok = obj[name]; !ok {
+				return nil, false
+			}
+		}
+		if indexes == "" {
+			continue
+		}
+		for _, idx := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+			i, err := strconv.Atoi(idx)
+			arr, ok := data.([]any)
+			if err != nil || !ok || i < 0 || i >= len(arr) {
+				return nil, false
+			}
+			data = arr[i]
+		}
+	}
+	return data, true
+}
+
+// selectString 按选择器读取JSON中的值并转换为字符串，选择器为空或值不存在时返回空字符串
+func selectString(data any, selector string) string {
+	if selector == "" {
+		return ""
+	}
+	v, ok := selectJSON(data, selector)
+	if !ok || v == nil {
+		return ""
+	}
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// selectInt 按选择器读取JSON中的整数，兼容字符串和小数写法，无法解析时返回0
+func selectInt(data any, selector string) int64 {
+	var n flexInt64
+	_ = n.UnmarshalJSON([]byte(selectString(data, selector)))
+	return int64(n)
+}
+
+// LoadRules 读取规则文件并创建检查器
+//
+// 返回值:
+// - []*RuleChecker: 有效规则对应的检查器
+// - error: 文件无法读取或解析时返回错误，单条规则无效时跳过该规则并返回第一个错误
+func LoadRules(path string) ([]*RuleChecker, error) {
+	var file RuleFile
+	if err := decodeConfigFile(path, &file); err != nil {
+		return nil, err
+	}
+
+	var firstErr error
+	checkers := make([]*RuleChecker, 0, len(file.Checkers))
+	for _, rule := range file.Checkers {
+		checker, err := NewRuleChecker(rule)
+		if err != nil {
+			logger.Warn("RuleChecker:跳过无效规则,%v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		checkers = append(checkers, checker)
+	}
+	return checkers, firstErr
+}
+
+// RegisterRules 读取规则文件并注册其中的检查器，文件不存在时忽略
+//
+// 返回值:
+// - int: 注册的检查器数量
+// - error: 文件无法解析、规则无效或前缀冲突时返回第一个错误，其余规则仍会注册
+func RegisterRules(path string) (int, error) {
+	checkers, err := LoadRules(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	firstErr := err
+
+	count := 0
+	for _, checker := range checkers {
+		if err := registerExternal(checker.rule.Name, checker.rule.Prefixes, checker.rule.Override, checker); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		logger.Info("RuleChecker:已注册规则,%s", checker.rule.Name)
+		count++
+	}
+	return count, firstErr
+}
@@ -0,0 +1,283 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/utils"
+)
+
+func TestSelectString(t *testing.T) {
+	var data any
+	if err := json.Unmarshal([]byte(`{"code":0,"data":{"title":"合集","list":[{"n":"a.mp4","s":1024}],"ok":true}}`), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"$.code":             "0",
+		"data.title":         "合集",
+		"$.data.list[0].n":   "a.mp4",
+		"$.data.list[0].s":   "1024",
+		"$.data.ok":          "true",
+		"$.data.list[1].n":   "",
+		"$.data.missing.key": "",
+	}
+	for selector, want := range tests {
+		if got := selectString(data, selector); got != want {
+			t.Errorf("selectString(%q) = %q, want %q", selector, got, want)
+		}
+	}
+}
+
+func TestRuleChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("X-Share") != r.URL.Query().Get("id") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Query().Get("id") {
+		case "ok":
+			w.Write([]byte(`{"code":0,"data":{"name":"资料","count":"3","size":"2048.0"}}`))
+		case "gone":
+			w.Write([]byte(`{"code":41004,"msg":"分享不存在"}`))
+		case "locked":
+			if !strings.Contains(string(body), `"pwd":"ab12"`) {
+				w.Write([]byte(`{"code":41008,"msg":"提取码错误"}`))
+				return
+			}
+			w.Write([]byte(`{"code":0,"data":{"name":"加密资料"}}`))
+		case "banned":
+			w.Write([]byte(`{"code":1,"msg":"该分享涉嫌违规"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	checker, err := NewRuleChecker(Rule{
+		Name:     "example",
+		Prefixes: []string{"https://pan.example.com/s/"},
+		Pattern:  `^https://pan\.example\.com/s/(?P<id>\w+)`,
+		Request: RuleRequest{
+			Method:  "POST",
+			URL:     server.URL + "/share?id={{id}}",
+			Headers: map[string]string{"X-Share": "{{id}}"},
+			Body:    `{"id":"{{id}}","pwd":"{{pwd}}"}`,
+		},
+		Response: RuleResponse{
+			Status:     "$.code",
+			Success:    []string{"0"},
+			Title:      "$.data.name",
+			Message:    "$.msg",
+			States:     map[string]string{"41004": "invalid:share_not_found", "41008": "need_password:wrong_passcode"},
+			HTTPStates: map[string]string{"404": "invalid"},
+			FileCount:  "$.data.count",
+			TotalSize:  "$.data.size",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRuleChecker() error = %v", err)
+	}
+
+	tests := []struct {
+		url        string
+		wantError  utils.ErrorType
+		wantReason string
+		wantName   string
+	}{
+		{"https://pan.example.com/s/ok", utils.Valid, utils.ReasonOK, "资料"},
+		{"https://pan.example.com/s/gone", utils.Invalid, utils.ReasonShareNotFound, ""},
+		{"https://pan.example.com/s/locked", utils.NeedPassword, utils.ReasonWrongPasscode, ""},
+		{"https://pan.example.com/s/locked?pwd=ab12", utils.Valid, utils.ReasonOK, "加密资料"},
+		{"https://pan.example.com/s/banned", utils.Banned, utils.ReasonBanned, ""},
+		{"https://pan.example.com/s/missing", utils.Invalid, utils.ReasonShareInvalid, ""},
+		{"https://pan.example.com/x/bad", utils.Malformed, utils.ReasonMalformedURL, ""},
+	}
+	for _, tt := range tests {
+		got := checker.Check(context.Background(), tt.url)
+		if got.Error != tt.wantError || got.Reason != tt.wantReason || got.Data.Name != tt.wantName {
+			t.Errorf("Check(%q) = %d %s %q, want %d %s %q", tt.url, got.Error, got.Reason, got.Data.Name, tt.wantError, tt.wantReason, tt.wantName)
+		}
+	}
+
+	got := checker.Check(context.Background(), "https://pan.example.com/s/ok")
+	if got.Data.Meta == nil || got.Data.Meta.FileCount != 3 || got.Data.Meta.TotalSize != 2048 {
+		t.Errorf("Check() meta = %+v", got.Data.Meta)
+	}
+	if id := checker.ShareID("https://pan.example.com/s/ok?pwd=1234"); id != "ok" {
+		t.Errorf("ShareID() = %q, want ok", id)
+	}
+}
+
+func TestRuleRequestEscape(t *testing.T) {
+	vars := map[string]string{"id": "a\"b&c=d\r\nX-Evil: 1"}
+	tests := []struct {
+		name    string
+		headers map[string]string
+		body    string
+		check   func(body string) bool
+	}{
+		{
+			name: "json body",
+			body: `{"id":"{{id}}"}`,
+			check: func(body string) bool {
+				var v struct{ ID string }
+				return json.Unmarshal([]byte(body), &v) == nil && v.ID == vars["id"]
+			},
+		},
+		{
+			name:    "form body",
+			headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:    "id={{id}}&type=1",
+			check: func(body string) bool {
+				v, err := url.ParseQuery(body)
+				return err == nil && v.Get("id") == vars["id"] && v.Get("type") == "1"
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{"X-Share": "{{id}}"}
+			for k, v := range tt.headers {
+				headers[k] = v
+			}
+			c := &RuleChecker{rule: Rule{Request: RuleRequest{Method: "POST", URL: "https://pan.example.com/api", Headers: headers, Body: tt.body}}}
+			req, err := c.newRequest(context.Background(), vars)
+			if err != nil {
+				t.Fatalf("newRequest() error = %v", err)
+			}
+			body, _ := io.ReadAll(req.Body)
+			if !tt.check(string(body)) {
+				t.Errorf("newRequest() body = %s", body)
+			}
+			if got := req.Header.Get("X-Share"); strings.ContainsAny(got, "\r\n") {
+				t.Errorf("newRequest() header = %q, want no newlines", got)
+			}
+		})
+	}
+}
+
+func TestRuleMetadata(t *testing.T) {
+	c := &RuleChecker{rule: Rule{Response: RuleResponse{Title: "$.name", TotalSize: "$.size", Creator: "$.owner"}}}
+	got := c.parseResponse(http.StatusOK, []byte(`{"name":"资料","size":1.5e3,"owner":"alice"}`))
+	if got.Data.Meta == nil || got.Data.Meta.TotalSize != 1500 || got.Data.Meta.Creator != "alice" || got.Data.Meta.FileCount != 0 {
+		t.Errorf("parseResponse() meta = %+v", got.Data.Meta)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"rules.toml": `
+[[checker]]
+name = "example"
+prefixes = ["https://pan.example.com/s/"]
+pattern = '^https://pan\.example\.com/s/(?P<id>\w+)'
+
+[checker.request]
+url = "https://api.example.com/share/{{id}}"
+
+[checker.response]
+status = "$.code"
+success = ["0"]
+title = "$.data.name"
+
+[checker.response.states]
+41004 = "invalid:share_expired"
+
+[[checker]]
+name = "broken"
+prefixes = ["https://broken.example.com/s/"]
+pattern = "("
+`,
+		"rules.json": `{"checkers": [{
+			"name": "example",
+			"prefixes": ["https://pan.example.com/s/"],
+			"pattern": "^https://pan\\.example\\.com/s/(?P<id>\\w+)",
+			"request": {"url": "https://api.example.com/share/{{id}}"},
+			"response": {"status": "$.code", "success": ["0"], "states": {"41004": "invalid:share_expired"}}
+		}]}`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		checkers, err := LoadRules(path)
+		if name == "rules.toml" && err == nil {
+			t.Errorf("LoadRules(%s) 预期无效规则错误", name)
+		}
+		if name == "rules.json" && err != nil {
+			t.Errorf("LoadRules(%s) error = %v", name, err)
+		}
+		if len(checkers) != 1 {
+			t.Fatalf("LoadRules(%s) = %d checkers, want 1", name, len(checkers))
+		}
+		c := checkers[0]
+		if c.rule.Request.Method != http.MethodGet || c.states["41004"].reason != utils.ReasonShareExpired {
+			t.Errorf("LoadRules(%s) rule = %+v", name, c.rule)
+		}
+	}
+
+	if count, err := RegisterRules(filepath.Join(dir, "missing.toml")); count != 0 || err != nil {
+		t.Errorf("RegisterRules() 文件不存在时应忽略, got %d %v", count, err)
+	}
+}
+
+func TestExampleRules(t *testing.T) {
+	checkers, err := LoadRules("../../config/rules.example.toml")
+	if err != nil || len(checkers) != 1 {
+		t.Fatalf("LoadRules(rules.example.toml) = %d checkers, %v", len(checkers), err)
+	}
+}
+
+func TestRegisterRulesConflict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.toml")
+	content := `
+[[checker]]
+name = "conflictrule"
+prefixes = ["https://pan.quark.cn/s/"]
+pattern = '^https://pan\.quark\.cn/s/(?P<id>\w+)'
+[checker.request]
+url = "https://api.example.com/share/{{id}}"
+
+[[checker]]
+name = "okrule"
+prefixes = ["https://pan.okrule.example.com/s/"]
+pattern = '^https://pan\.okrule\.example\.com/s/(?P<id>\w+)'
+[checker.request]
+url = "https://api.example.com/share/{{id}}"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := RegisterRules(path)
+	if count != 1 || err == nil {
+		t.Fatalf("RegisterRules() = %d, %v, want 1 and a conflict error", count, err)
+	}
+	// 前缀冲突的规则不加入网盘列表，内置检查器的网盘标识不变
+	if got := config.GetSupported("conflictrule"); len(got) != 0 {
+		t.Errorf("GetSupported(conflictrule) = %v, want none", got)
+	}
+	if r, _ := routes.match("https://pan.quark.cn/s/abc"); r == nil || r.provider != "quark" {
+		t.Errorf("routes.match(quark) = %+v, want provider quark", r)
+	}
+	if r, _ := routes.match("https://pan.okrule.example.com/s/abc"); r == nil || r.provider != "okrule" {
+		t.Errorf("routes.match(okrule) = %+v, want provider okrule", r)
+	}
+	if got := config.GetSupported("okrule"); len(got) != 1 {
+		t.Errorf("GetSupported(okrule) = %v, want the rule prefix", got)
+	}
+}
@@ -0,0 +1,510 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// script.go 实现了用JavaScript编写的检查器
+// 网盘接口变化时，修改脚本即可修复检测，无需等待新版本发布。脚本由内嵌的纯Go解释器执行，
+// 每个 .js 文件定义一个全局的 checker 对象:
+//
+//	var checker = {
+//	  name: "example",                          // 网盘标识
+//	  prefixes: ["https://pan.example.com/s/"], // 链接前缀
+//	  pattern: "/s/(?P<id>\\w+)",               // 可选，提取分享ID的Go正则
+//	  override: false,                          // 可选，是否替换内置的同前缀检查器
+//	  check: function (url) { return result.valid("标题") }
+//	}
+//
+// 脚本可以使用的宿主接口:
+//   - http.get(url, opts) / http.post(url, body, opts) / http.request(req): 使用共享的HTTP客户端（限速、熔断、重试、默认请求头），
+//     同一次检测内共享Cookie；返回 {status, url, headers, body, json()}，请求失败时抛出异常
+//   - json.parse(text) / json.stringify(value) / json.select(value, "$.data.list[0].name")
+//   - result.valid(name, meta) / result.invalid(msg, reason) / result.needPassword / result.loginRequired /
+//     result.rateLimited / result.banned / result.malformed / result.fatal / result.unknown(msg, reason) /
+//     result.timeout() / result.fromMessage(upstreamMsg, invalidMsg)
+//   - password(url): 读取链接中的提取码
+//   - log(...): 写入调试日志
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"share-sniffer/internal/config"
+	apphttp "share-sniffer/internal/http"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/parser"
+	"share-sniffer/internal/utils"
+)
+
+// scriptMaxBody 脚本读取响应的最大字节数
+const scriptMaxBody = 4 * 1024 * 1024
+
+// scriptLoadTimeout 加载脚本时执行顶层代码的最长时间，防止脚本中的死循环阻塞启动
+var scriptLoadTimeout = 5 * time.Second
+
+// scriptCheckTimeout 单次检测执行脚本的最长时间，脚本可能发出多个请求，按长耗时检测处理
+// 调用方没有设置截止时间（如命令行检测）时也能中断死循环的脚本
+var scriptCheckTimeout = config.GetLongTimeout
+
+// scriptDefinition 脚本中 checker 对象的静态字段
+type scriptDefinition struct {
+	Name     string   `json:"name"`
+	Prefixes []string `json:"prefixes"`
+	Pattern  string   `json:"pattern"`
+	Override bool     `json:"override"`
+}
+
+// scriptRequest 脚本发起的请求
+type scriptRequest struct {
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Headers    map[string]string `json:"headers"`
+	Body       any               `json:"body"`       // 字符串原样发送，其他值编码为JSON
+	NoRedirect bool              `json:"noRedirect"` // 不跟随重定向
+}
+
+// ScriptChecker 由JavaScript脚本实现的检查器
+// 脚本只编译一次，每次检测使用新的解释器实例，因此可以并发检测
+type ScriptChecker struct {
+	path    string
+	def     scriptDefinition
+	pattern *regexp.Regexp
+	program *goja.Program
+}
+
+// NewScriptChecker 编译脚本并读取其中的检查器定义
+//
+// 参数:
+// - name: 脚本名称，用于错误信息和日志
+// - src: 脚本源码
+//
+// 返回值:
+// - *ScriptChecker: 检查器实例
+// - error: 脚本语法错误、执行失败或缺少必填项时返回错误
+func NewScriptChecker(name, src string) (*ScriptChecker, error) {
+	program, err := goja.Compile(name, src, false)
+	if err != nil {
+		return nil, fmt.Errorf("脚本 %s 编译失败: %w", name, err)
+	}
+
+	c := &ScriptChecker{path: name, program: program}
+	ctx, cancel := context.WithTimeout(context.Background(), scriptLoadTimeout)
+	defer cancel()
+	vm, stop, err := c.newRuntime(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("脚本 %s 执行失败: %w", name, err)
+	}
+	defer stop()
+	obj, ok := c.checkerObject(vm)
+	if !ok {
+		return nil, fmt.Errorf("脚本 %s 未定义checker对象", name)
+	}
+	if _, ok := goja.AssertFunction(obj.Get("check")); !ok {
+		return nil, fmt.Errorf("脚本 %s 缺少check函数", name)
+	}
+	if err := vm.ExportTo(obj, &c.def); err != nil {
+		return nil, fmt.Errorf("脚本 %s 的checker定义无效: %w", name, err)
+	}
+
+	if c.def.Name == "" {
+		return nil, fmt.Errorf("脚本 %s 缺少name", name)
+	}
+	if len(c.def.Prefixes) == 0 {
+		return nil, fmt.Errorf("脚本 %s 缺少prefixes", name)
+	}
+	if c.def.Pattern != "" {
+		if c.pattern, err = regexp.Compile(c.def.Pattern); err != nil {
+			return nil, fmt.Errorf("脚本 %s 的pattern无效: %w", name, err)
+		}
+	}
+	return c, nil
+}
+
+// Check 实现LinkChecker接口
+func (c *ScriptChecker) Check(ctx context.Context, urlStr string) utils.Result {
+	logger.Debug("ScriptChecker:开始检测%s链接: %s", c.def.Name, urlStr)
+
+	ctx, cancel := context.WithTimeout(ctx, scriptCheckTimeout())
+	defer cancel()
+	vm, stop, err := c.newRuntime(ctx)
+	if err != nil {
+		return c.errorResult(urlStr, err)
+	}
+	defer stop()
+	obj, _ := c.checkerObject(vm)
+	check, ok := goja.AssertFunction(obj.Get("check"))
+	if !ok {
+		return utils.ErrorUnknown("脚本缺少check函数")
+	}
+
+	value, err := check(obj, vm.ToValue(urlStr))
+	if err != nil {
+		return c.errorResult(urlStr, err)
+	}
+	return c.toResult(value)
+}
+
+// GetPrefix 实现LinkChecker接口
+func (c *ScriptChecker) GetPrefix() []string {
+	return c.def.Prefixes
+}
+
+// ShareID 实现ShareIdentifier接口，脚本未声明pattern时返回空字符串
+// 使用pattern中名为id的捕获组，没有时使用第一个捕获组
+func (c *ScriptChecker) ShareID(urlStr string) string {
+	if c.pattern == nil {
+		return ""
+	}
+	m := c.pattern.FindStringSubmatch(urlStr)
+	if m == nil {
+		return ""
+	}
+	if i := c.pattern.SubexpIndex("id"); i > 0 {
+		return m[i]
+	}
+	if len(m) > 1 {
+		return m[1]
+	}
+	return ""
+}
+
+// newRuntime 创建解释器实例，安装宿主接口并执行脚本
+// ctx结束时中断脚本的执行，使用完解释器后调用返回的stop取消中断，避免ctx存活期间一直持有解释器
+func (c *ScriptChecker) newRuntime(ctx context.Context) (*goja.Runtime, func() bool, error) {
+	vm := goja.New()
+	vm.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
+	stop := context.AfterFunc(ctx, func() {
+		vm.Interrupt(ctx.Err())
+	})
+
+	jar, _ := cookiejar.New(nil)
+	host := &scriptHost{ctx: ctx, vm: vm, name: c.path, jar: jar}
+	if err := host.install(); err != nil {
+		stop()
+		return nil, nil, err
+	}
+	if _, err := vm.RunProgram(c.program); err != nil {
+		stop()
+		return nil, nil, err
+	}
+	return vm, stop, nil
+}
+
+// checkerObject 读取脚本定义的全局checker对象
+func (c *ScriptChecker) checkerObject(vm *goja.Runtime) (*goja.Object, bool) {
+	v := vm.Get("checker")
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return nil, false
+	}
+	obj, ok := v.(*goja.Object)
+	return obj, ok
+}
+
+// toResult 把脚本的返回值转换为检测结果
+// 返回值可以是result对象的构造结果，也可以是与CLI输出结构相同的普通对象
+func (c *ScriptChecker) toResult(value goja.Value) utils.Result {
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return utils.ErrorUnknown("脚本没有返回检测结果")
+	}
+	if result, ok := value.Export().(utils.Result); ok {
+		return result
+	}
+
+	var result utils.Result
+	data, err := json.Marshal(value.Export())
+	if err == nil {
+		err = json.Unmarshal(data, &result)
+	}
+	if err != nil {
+		logger.Warn("ScriptChecker:%s,返回值无效,%v", c.path, err)
+		return utils.ErrorUnknown("脚本返回值无效")
+	}
+	if result.Msg == "" {
+		result.Msg = utils.ErrorToMsg(result.Error)
+	}
+	return result
+}
+
+// errorResult 把脚本抛出的异常转换为检测结果
+// 请求失败按请求错误处理，ctx结束视为超时，其余异常视为脚本错误
+func (c *ScriptChecker) errorResult(urlStr string, err error) utils.Result {
+	var interrupted *goja.InterruptedError
+	if stderrors.As(err, &interrupted) {
+		return utils.ErrorTimeout()
+	}
+	var exception *goja.Exception
+	if stderrors.As(err, &exception) {
+		if goErr := exception.Unwrap(); goErr != nil {
+			logger.Info("ScriptChecker:%s,%s,错误: %v", c.def.Name, urlStr, goErr)
+			return errorResult(goErr)
+		}
+	}
+	logger.Warn("ScriptChecker:%s,%s,脚本错误: %v", c.path, urlStr, err)
+	return utils.ErrorUnknown("脚本执行失败")
+}
+
+// scriptHost 提供给一次脚本执行的宿主接口
+type scriptHost struct {
+	ctx  context.Context
+	vm   *goja.Runtime
+	name string
+	jar  http.CookieJar
+}
+
+// install 在解释器中注册宿主接口
+func (h *scriptHost) install() error {
+	vm := h.vm
+
+	httpObj := vm.NewObject()
+	_ = httpObj.Set("request", h.request)
+	_ = httpObj.Set("get", func(url string, opts *scriptRequest) (*goja.Object, error) {
+		req := scriptRequest{}
+		if opts != nil {
+			req = *opts
+		}
+		req.Method, req.URL = http.MethodGet, url
+		return h.request(req)
+	})
+	_ = httpObj.Set("post", func(url string, body any, opts *scriptRequest) (*goja.Object, error) {
+		req := scriptRequest{}
+		if opts != nil {
+			req = *opts
+		}
+		req.Method, req.URL, req.Body = http.MethodPost, url, body
+		return h.request(req)
+	})
+
+	jsonObj := vm.NewObject()
+	_ = jsonObj.Set("parse", func(text string) any {
+		var v any
+		if err := json.Unmarshal([]byte(text), &v); err != nil {
+			return nil
+		}
+		return v
+	})
+	_ = jsonObj.Set("stringify", func(v any) string {
+		b, _ := json.Marshal(v)
+		return string(b)
+	})
+	_ = jsonObj.Set("select", func(v any, selector string) any {
+		if text, ok := v.(string); ok {
+			if err := json.Unmarshal([]byte(text), &v); err != nil {
+				return nil
+			}
+		}
+		// 统一为JSON解码后的类型再按选择器读取
+		b, _ := json.Marshal(v)
+		var data any
+		_ = json.Unmarshal(b, &data)
+		value, _ := selectJSON(data, selector)
+		return value
+	})
+
+	for name, value := range map[string]any{
+		"http":     httpObj,
+		"json":     jsonObj,
+		"result":   h.resultObject(),
+		"password": parser.PasswordFromURL,
+		"log": func(call goja.FunctionCall) goja.Value {
+			parts := make([]string, len(call.Arguments))
+			for i, arg := range call.Arguments {
+				parts[i] = arg.String()
+			}
+			logger.Debug("ScriptChecker:[%s] %s", h.name, strings.Join(parts, " "))
+			return goja.Undefined()
+		},
+	} {
+		if err := vm.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// request 发送脚本的请求并返回响应对象
+func (h *scriptHost) request(r scriptRequest) (*goja.Object, error) {
+	if r.Method == "" {
+		r.Method = http.MethodGet
+	}
+
+	var body io.Reader
+	switch b := r.Body.(type) {
+	case nil:
+	case string:
+		body = strings.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+		if r.Headers["Content-Type"] == "" && r.Headers["content-type"] == "" {
+			if r.Headers == nil {
+				r.Headers = make(map[string]string)
+			}
+			r.Headers["Content-Type"] = "application/json"
+		}
+	}
+
+	req, err := apphttp.NewRequestWithContext(h.ctx, strings.ToUpper(r.Method), r.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range r.Headers {
+		req.Header.Set(k, v)
+	}
+
+	shared := apphttp.GetClient()
+	if r.NoRedirect {
+		shared = apphttp.GetNoRedirectClient()
+	}
+	client := &http.Client{
+		Transport:     shared.Transport,
+		Timeout:       shared.Timeout,
+		CheckRedirect: shared.CheckRedirect,
+		Jar:           h.jar,
+	}
+	resp, err := apphttp.DoWithClient(h.ctx, client, req, config.GetRetryCount())
+	if err != nil {
+		return nil, err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, scriptMaxBody))
+	if err != nil {
+		return nil, err
+	}
+
+	headers := h.vm.NewObject()
+	for k := range resp.Header {
+		_ = headers.Set(strings.ToLower(k), resp.Header.Get(k))
+	}
+	obj := h.vm.NewObject()
+	_ = obj.Set("status", resp.StatusCode)
+	_ = obj.Set("url", resp.Request.URL.String())
+	_ = obj.Set("headers", headers)
+	_ = obj.Set("body", string(data))
+	_ = obj.Set("json", func() (any, error) {
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("解析响应失败: %w", err)
+		}
+		return v, nil
+	})
+	return obj, nil
+}
+
+// resultObject 创建与utils中结果构造函数对应的result对象
+// reason为空时使用结果状态的默认原因码
+func (h *scriptHost) resultObject() *goja.Object {
+	withReason := func(result utils.Result, reason string) utils.Result {
+		if reason == "" {
+			return result
+		}
+		return result.WithReason(reason)
+	}
+	status := func(status utils.ErrorType) func(string, string) utils.Result {
+		return func(msg, reason string) utils.Result {
+			if msg == "" {
+				msg = utils.ErrorToMsg(status)
+			}
+			return withReason(statusResult(status, msg), reason)
+		}
+	}
+
+	obj := h.vm.NewObject()
+	_ = obj.Set("valid", func(name string, meta map[string]any) utils.Result {
+		result := utils.ErrorValid(name)
+		if meta != nil {
+			var m utils.Metadata
+			if data, err := json.Marshal(meta); err == nil && json.Unmarshal(data, &m) == nil {
+				result = result.WithMeta(&m)
+			}
+		}
+		return result
+	})
+	_ = obj.Set("invalid", status(utils.Invalid))
+	_ = obj.Set("needPassword", status(utils.NeedPassword))
+	_ = obj.Set("loginRequired", status(utils.LoginRequired))
+	_ = obj.Set("rateLimited", status(utils.RateLimited))
+	_ = obj.Set("banned", status(utils.Banned))
+	_ = obj.Set("malformed", status(utils.Malformed))
+	_ = obj.Set("fatal", status(utils.Fatal))
+	_ = obj.Set("unknown", status(utils.Unknown))
+	_ = obj.Set("timeout", utils.ErrorTimeout)
+	_ = obj.Set("fromMessage", messageResult)
+	return obj
+}
+
+// LoadScripts 读取目录下的所有 .js 脚本并创建检查器，按文件名排序
+//
+// 返回值:
+// - []*ScriptChecker: 有效脚本对应的检查器
+// - error: 目录无法读取时返回错误，单个脚本无效时跳过该脚本并返回第一个错误
+func LoadScripts(dir string) ([]*ScriptChecker, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	var firstErr error
+	var checkers []*ScriptChecker
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".js") {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err == nil {
+			var checker *ScriptChecker
+			if checker, err = NewScriptChecker(entry.Name(), string(src)); err == nil {
+				checkers = append(checkers, checker)
+				continue
+			}
+		}
+		logger.Warn("ScriptChecker:跳过无效脚本,%v", err)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return checkers, firstErr
+}
+
+// RegisterScripts 读取脚本目录并注册其中的检查器，目录不存在时忽略
+//
+// 返回值:
+// - int: 注册的检查器数量
+// - error: 目录无法读取、脚本无效或前缀冲突时返回第一个错误，其余脚本仍会注册
+func RegisterScripts(dir string) (int, error) {
+	checkers, err := LoadScripts(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	firstErr := err
+
+	count := 0
+	for _, checker := range checkers {
+		if err := registerExternal(checker.def.Name, checker.def.Prefixes, checker.def.Override, checker); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		logger.Info("ScriptChecker:已注册脚本,%s", checker.path)
+		count++
+	}
+	return count, firstErr
+}
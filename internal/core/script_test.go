@@ -0,0 +1,171 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"share-sniffer/internal/utils"
+)
+
+const testScript = `
+var checker = {
+  name: "example",
+  prefixes: ["https://pan.example.com/s/"],
+  pattern: "/s/(?P<id>\\w+)",
+  check: function (url) {
+    var id = url.split("/s/")[1].split("?")[0];
+    if (id === "loop") {
+      for (;;) {}
+    }
+    if (id === "broken") {
+      return undefinedFunction();
+    }
+    if (id === "plain") {
+      return {error: 11, reason: "share_expired", data: {name: "过期"}};
+    }
+
+    // 第一个请求设置Cookie，第二个请求带上Cookie
+    http.get(API + "/login");
+    var resp = http.post(API + "/share", {id: id, pwd: password(url)}, {headers: {"X-Test": "1"}});
+    if (resp.status === 404) {
+      return result.invalid("分享不存在", "share_not_found");
+    }
+    var data = resp.json();
+    if (data.code === 41008) {
+      return result.needPassword(data.msg, "wrong_passcode");
+    }
+    if (data.code !== 0) {
+      return result.fromMessage(data.msg, "");
+    }
+    log("title", json.select(resp.body, "$.data.title"));
+    return result.valid(data.data.title, {file_count: data.data.count, total_size: 2048});
+  }
+};
+`
+
+func TestScriptChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1"})
+			return
+		}
+		if c, err := r.Cookie("session"); err != nil || c.Value != "s1" || r.Header.Get("X-Test") != "1" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body := make([]byte, 256)
+		n, _ := r.Body.Read(body)
+		switch s := string(body[:n]); {
+		case strings.Contains(s, `"id":"ok"`):
+			w.Write([]byte(`{"code":0,"data":{"title":"资料","count":3}}`))
+		case strings.Contains(s, `"id":"locked"`):
+			w.Write([]byte(`{"code":41008,"msg":"提取码错误"}`))
+		case strings.Contains(s, `"id":"banned"`):
+			w.Write([]byte(`{"code":1,"msg":"该分享涉嫌违规"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	checker, err := NewScriptChecker("example.js", "var API = '"+server.URL+"';"+testScript)
+	if err != nil {
+		t.Fatalf("NewScriptChecker() error = %v", err)
+	}
+
+	tests := []struct {
+		url        string
+		wantError  utils.ErrorType
+		wantReason string
+		wantName   string
+	}{
+		{"https://pan.example.com/s/ok", utils.Valid, utils.ReasonOK, "资料"},
+		{"https://pan.example.com/s/gone", utils.Invalid, utils.ReasonShareNotFound, ""},
+		{"https://pan.example.com/s/locked?pwd=ab12", utils.NeedPassword, utils.ReasonWrongPasscode, ""},
+		{"https://pan.example.com/s/banned", utils.Banned, utils.ReasonBanned, ""},
+		{"https://pan.example.com/s/plain", utils.Invalid, utils.ReasonShareExpired, "过期"},
+		{"https://pan.example.com/s/broken", utils.Unknown, utils.ReasonUnknown, ""},
+	}
+	for _, tt := range tests {
+		got := checker.Check(context.Background(), tt.url)
+		if got.Error != tt.wantError || got.Reason != tt.wantReason || got.Data.Name != tt.wantName {
+			t.Errorf("Check(%q) = %d %s %q, want %d %s %q", tt.url, got.Error, got.Reason, got.Data.Name, tt.wantError, tt.wantReason, tt.wantName)
+		}
+	}
+
+	got := checker.Check(context.Background(), "https://pan.example.com/s/ok")
+	if got.Data.Meta == nil || got.Data.Meta.FileCount != 3 || got.Data.Meta.TotalSize != 2048 {
+		t.Errorf("Check() meta = %+v", got.Data.Meta)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if got := checker.Check(ctx, "https://pan.example.com/s/loop"); got.Error != utils.Timeout {
+		t.Errorf("Check(loop) = %+v, want timeout", got)
+	}
+
+	// 调用方没有设置截止时间时按检测超时中断
+	checkTimeout := scriptCheckTimeout
+	scriptCheckTimeout = func() time.Duration { return 100 * time.Millisecond }
+	defer func() { scriptCheckTimeout = checkTimeout }()
+	if got := checker.Check(context.Background(), "https://pan.example.com/s/loop"); got.Error != utils.Timeout {
+		t.Errorf("Check(loop) without deadline = %+v, want timeout", got)
+	}
+
+	if id := checker.ShareID("https://pan.example.com/s/ok?pwd=1234"); id != "ok" {
+		t.Errorf("ShareID() = %q, want ok", id)
+	}
+}
+
+func TestLoadScripts(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.js":       testScript,
+		"b.js":       `var checker = {name: "nocheck", prefixes: ["https://b.example.com/s/"]};`,
+		"c.js":       `var checker = {`,
+		"readme.txt": "not a script",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkers, err := LoadScripts(dir)
+	if err == nil {
+		t.Errorf("LoadScripts() 预期无效脚本错误")
+	}
+	if len(checkers) != 1 || checkers[0].def.Name != "example" {
+		t.Fatalf("LoadScripts() = %d checkers, want example", len(checkers))
+	}
+
+	checkers, err = LoadScripts("../../config/scripts")
+	if err != nil || len(checkers) == 0 {
+		t.Errorf("LoadScripts(config/scripts) = %d checkers, %v", len(checkers), err)
+	}
+
+	if count, err := RegisterScripts(filepath.Join(dir, "missing")); count != 0 || err != nil {
+		t.Errorf("RegisterScripts() 目录不存在时应忽略, got %d %v", count, err)
+	}
+}
+
+func TestScriptLoadTimeout(t *testing.T) {
+	timeout := scriptLoadTimeout
+	scriptLoadTimeout = 100 * time.Millisecond
+	defer func() { scriptLoadTimeout = timeout }()
+
+	// 顶层代码死循环时加载超时，不会阻塞启动
+	start := time.Now()
+	if _, err := NewScriptChecker("loop.js", `while (true) {}`); err == nil {
+		t.Errorf("NewScriptChecker(loop.js) error = nil, want timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("NewScriptChecker(loop.js) took %v", elapsed)
+	}
+}
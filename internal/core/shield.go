@@ -0,0 +1,267 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// shield.go 实现了迅雷系网盘(迅雷网盘、PikPak)分享接口的匿名客户端
+// 两者使用同一套接口：先通过 /v1/shield/captcha/init 获取验证码令牌，再带着令牌请求 /drive/v1/share
+package core
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"share-sniffer/internal/errors"
+	apphttp "share-sniffer/internal/http"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/utils"
+)
+
+// shieldClient 迅雷系网盘分享接口的匿名客户端
+// 验证码令牌由同一网盘的所有检测共用，过期或失效后重新获取
+type shieldClient struct {
+	// name 日志中使用的检查器名称
+	name string
+	// 网页版客户端的接口参数
+	clientID      string
+	clientVersion string
+	packageName   string
+	action        string
+	// origin 网页版地址，作为请求的 origin 和 referer
+	origin string
+	// apiBase 分享接口地址
+	apiBase string
+	// userBase 验证码令牌接口地址
+	userBase string
+
+	mu       sync.Mutex
+	deviceID string
+	token    string
+	expires  time.Time
+}
+
+// shieldCaptchaResp 验证码令牌接口响应结构
+type shieldCaptchaResp struct {
+	CaptchaToken     string `json:"captcha_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// shieldShareResp 分享接口响应结构
+type shieldShareResp struct {
+	ShareStatus     string    `json:"share_status"`
+	ShareStatusText string    `json:"share_status_text"`
+	Title           string    `json:"title"`
+	FileNum         flexInt64 `json:"file_num"`
+	ExpirationLeft  flexInt64 `json:"expiration_left"`
+	UserInfo        struct {
+		Nickname string `json:"nickname"`
+	} `json:"user_info"`
+	Files []struct {
+		Name string    `json:"name"`
+		Size flexInt64 `json:"size"`
+		Kind string    `json:"kind"`
+	} `json:"files"`
+
+	// 接口出错时返回的字段
+	Error            string `json:"error"`
+	ErrorCode        int    `json:"error_code"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// result 根据分享状态创建检测结果
+func (r *shieldShareResp) result() utils.Result {
+	msg := r.ShareStatusText
+	switch r.ShareStatus {
+	case "OK":
+		name := r.Title
+		if name == "" && len(r.Files) > 0 {
+			name = r.Files[0].Name
+		}
+		return utils.ErrorValid(name).WithMeta(r.meta())
+	case "PASS_CODE_EMPTY":
+		return utils.ErrorNeedPassword(cmp.Or(msg, "请输入提取码"))
+	case "PASS_CODE_ERROR":
+		return utils.ErrorNeedPassword(cmp.Or(msg, "提取码错误")).WithReason(utils.ReasonWrongPasscode)
+	case "NOT_FOUND":
+		return utils.ErrorInvalid(cmp.Or(msg, "分享不存在")).WithReason(utils.ReasonShareNotFound)
+	case "DELETED":
+		return utils.ErrorInvalid(cmp.Or(msg, "该分享已被作者删除")).WithReason(utils.ReasonShareCancelled)
+	case "EXPIRED":
+		return utils.ErrorInvalid(cmp.Or(msg, "分享已过期")).WithReason(utils.ReasonShareExpired)
+	case "SENSITIVE_RESOURCE", "SENSITIVE_WORD":
+		return utils.ErrorBanned(cmp.Or(msg, "该分享内容可能涉及违规信息，无法访问！"))
+	}
+	return messageResult(msg, "分享内容无法访问")
+}
+
+// meta 生成分享内容元数据
+func (r *shieldShareResp) meta() *utils.Metadata {
+	meta := &utils.Metadata{
+		FileCount: int(r.FileNum),
+		Creator:   r.UserInfo.Nickname,
+	}
+	if meta.FileCount == 0 {
+		meta.FileCount = len(r.Files)
+	}
+	if r.ExpirationLeft > 0 {
+		meta.ExpiresAt = time.Now().Add(time.Duration(r.ExpirationLeft) * time.Second).UnixMilli()
+	}
+	for _, f := range r.Files {
+		meta.TotalSize += int64(f.Size)
+		meta.AddEntry(f.Name, int64(f.Size), f.Kind == "drive#folder")
+	}
+	return meta
+}
+
+// shareRequest 获取分享信息，验证码令牌失效时重新获取并重试一次
+func (c *shieldClient) shareRequest(ctx context.Context, shareID, passCode string) (*shieldShareResp, error) {
+	for attempt := 0; ; attempt++ {
+		deviceID, token, err := c.captchaToken(ctx, attempt > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := c.shareInfo(ctx, shareID, passCode, deviceID, token)
+		if err != nil {
+			return nil, err
+		}
+		if response.Error == "" {
+			return response, nil
+		}
+		if strings.HasPrefix(response.Error, "captcha") && attempt == 0 {
+			logger.Debug("%s:验证码令牌失效，重新获取: %s", c.name, response.Error)
+			continue
+		}
+		return nil, errors.NewAPIError(cmp.Or(response.ErrorDescription, response.Error), response.Error, nil)
+	}
+}
+
+// shareInfo 请求分享接口，接口错误通过响应中的error字段返回
+func (c *shieldClient) shareInfo(ctx context.Context, shareID, passCode, deviceID, token string) (*shieldShareResp, error) {
+	params := url.Values{}
+	params.Set("share_id", shareID)
+	params.Set("pass_code", passCode)
+	params.Set("limit", "100")
+	params.Set("pass_code_token", "")
+	params.Set("page_token", "")
+	params.Set("thumbnail_size", "SIZE_SMALL")
+	apiURL := c.apiBase + "/drive/v1/share?" + params.Encode()
+
+	req, err := apphttp.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("origin", c.origin)
+	req.Header.Set("referer", c.origin+"/")
+	req.Header.Set("x-client-id", c.clientID)
+	req.Header.Set("x-device-id", deviceID)
+	req.Header.Set("x-captcha-token", token)
+
+	resp, err := apphttp.DoWithRetry(ctx, req, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response shieldShareResp
+	if err = json.Unmarshal(body, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.NewStatusCodeError(fmt.Sprintf("HTTP状态码: %d", resp.StatusCode))
+		}
+		return nil, errors.NewParseError("解析JSON失败", err)
+	}
+	if resp.StatusCode != http.StatusOK && response.Error == "" {
+		return nil, errors.NewStatusCodeError(fmt.Sprintf("HTTP状态码: %d", resp.StatusCode))
+	}
+
+	return &response, nil
+}
+
+// captchaToken 获取验证码令牌，令牌未过期时直接复用
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - refresh: 是否丢弃当前令牌重新获取
+//
+// 返回值:
+// - string: 设备ID，请求分享接口时需与令牌一起发送
+// - string: 验证码令牌
+// - error: 获取失败时返回错误
+func (c *shieldClient) captchaToken(ctx context.Context, refresh bool) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.deviceID == "" {
+		id := make([]byte, 16)
+		_, _ = rand.Read(id)
+		c.deviceID = hex.EncodeToString(id)
+	}
+	if !refresh && c.token != "" && time.Now().Before(c.expires) {
+		return c.deviceID, c.token, nil
+	}
+
+	requestBody := map[string]interface{}{
+		"client_id": c.clientID,
+		"action":    c.action,
+		"device_id": c.deviceID,
+		"meta": map[string]string{
+			"package_name":   c.packageName,
+			"client_version": c.clientVersion,
+			"timestamp":      strconv.FormatInt(time.Now().UnixMilli(), 10),
+			"user_id":        "0",
+		},
+	}
+	jsonBody, _ := json.Marshal(requestBody)
+
+	req, err := apphttp.NewRequestWithContext(ctx, "POST", c.userBase+"/v1/shield/captcha/init", bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("origin", c.origin)
+	req.Header.Set("referer", c.origin+"/")
+	req.Header.Set("x-client-id", c.clientID)
+	req.Header.Set("x-device-id", c.deviceID)
+
+	resp, err := apphttp.DoWithRetry(ctx, req, 0)
+	if err != nil {
+		return "", "", err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", err
+	}
+
+	var response shieldCaptchaResp
+	if err = json.Unmarshal(body, &response); err != nil {
+		return "", "", errors.NewParseError("解析JSON失败", err)
+	}
+	if response.CaptchaToken == "" {
+		return "", "", errors.NewAPIError(cmp.Or(response.ErrorDescription, response.Error, "获取验证码令牌失败"), response.Error, nil)
+	}
+
+	// 提前一分钟过期，避免令牌在请求途中失效
+	expiresIn := time.Duration(max(response.ExpiresIn-60, 0)) * time.Second
+	c.token = response.CaptchaToken
+	c.expires = time.Now().Add(expiresIn)
+	return c.deviceID, c.token, nil
+}
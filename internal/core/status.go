@@ -0,0 +1,148 @@
+package core
+
+import (
+	"strings"
+
+	"share-sniffer/internal/errors"
+	"share-sniffer/internal/utils"
+)
+
+// 上游提示信息中用于判断结果状态的关键词，按优先级从高到低排列
+var (
+	// bannedKeywords 分享因违规被屏蔽
+	bannedKeywords = []string{"违规", "侵权", "违反", "涉嫌", "屏蔽", "审核未通过", "blocked"}
+
+	// loginKeywords 需要登录才能访问
+	loginKeywords = []string{"登录", "登陆", "login"}
+
+	// rateLimitKeywords 请求过于频繁或需要验证码
+	rateLimitKeywords = []string{"频繁", "验证码", "稍后再试", "限流", "captcha", "too many"}
+
+	// passwordKeywords 需要提取码或提取码错误
+	passwordKeywords = []string{"提取码", "访问码", "密码", "passcode", "password"}
+
+	// wrongKeywords 提取码错误，只匹配提取码相关的说法，"参数错误"之类的提示不算
+	wrongKeywords = []string{
+		"提取码错误", "提取码不正确", "提取码有误", "访问码错误", "访问码不正确", "密码错误", "密码不正确", "密码有误",
+		"wrong password", "incorrect password", "invalid password", "wrong passcode", "incorrect passcode", "invalid passcode",
+	}
+
+	// expiredKeywords 分享已过期
+	expiredKeywords = []string{"过期", "失效", "expired"}
+
+	// notFoundKeywords 分享不存在
+	notFoundKeywords = []string{"不存在", "not found", "notfound", "not exist"}
+
+	// cancelledKeywords 分享已取消或删除
+	cancelledKeywords = []string{"取消", "删除", "cancel", "deleted"}
+)
+
+// classifyMessage 根据上游返回的提示信息判断结果状态
+//
+// 参数:
+// - msg: 上游返回的提示信息
+// - fallback: 无法识别时使用的状态
+//
+// 返回值:
+// - ErrorType: 结果状态
+func classifyMessage(msg string, fallback utils.ErrorType) utils.ErrorType {
+	lower := strings.ToLower(msg)
+	switch {
+	case containsAny(lower, bannedKeywords):
+		return utils.Banned
+	case containsAny(lower, loginKeywords):
+		return utils.LoginRequired
+	case containsAny(lower, rateLimitKeywords):
+		return utils.RateLimited
+	case containsAny(lower, passwordKeywords):
+		return utils.NeedPassword
+	}
+	return fallback
+}
+
+// statusResult 根据结果状态创建检测结果
+func statusResult(status utils.ErrorType, msg string) utils.Result {
+	switch status {
+	case utils.Valid:
+		return utils.ErrorValid(msg)
+	case utils.NeedPassword:
+		return utils.ErrorNeedPassword(msg)
+	case utils.LoginRequired:
+		return utils.ErrorLoginRequired(msg)
+	case utils.RateLimited:
+		return utils.ErrorRateLimited(msg)
+	case utils.Banned:
+		return utils.ErrorBanned(msg)
+	case utils.Malformed:
+		return utils.ErrorMalformed("", msg)
+	case utils.Timeout:
+		return utils.ErrorTimeout()
+	case utils.Fatal:
+		return utils.ErrorFatal(msg)
+	case utils.Unknown:
+		return utils.ErrorUnknown(msg)
+	}
+	return utils.ErrorInvalid(msg)
+}
+
+// messageResult 根据上游提示信息创建失败的检测结果，无法识别时视为失效
+//
+// 参数:
+// - upstreamMsg: 上游返回的提示信息，用于判断状态并作为结果信息
+// - invalidMsg: 视为失效时展示的信息，为空时使用上游提示信息
+func messageResult(upstreamMsg string, invalidMsg string) utils.Result {
+	status := classifyMessage(upstreamMsg, utils.Invalid)
+	reason := messageReason(status, upstreamMsg)
+	if status == utils.Invalid && invalidMsg != "" {
+		return utils.ErrorInvalid(invalidMsg).WithReason(reason)
+	}
+	return statusResult(status, upstreamMsg).WithReason(reason)
+}
+
+// messageReason 根据结果状态和上游提示信息判断原因码
+func messageReason(status utils.ErrorType, msg string) string {
+	lower := strings.ToLower(msg)
+	switch status {
+	case utils.NeedPassword:
+		if containsAny(lower, wrongKeywords) {
+			return utils.ReasonWrongPasscode
+		}
+	case utils.Invalid:
+		switch {
+		case containsAny(lower, cancelledKeywords):
+			return utils.ReasonShareCancelled
+		case containsAny(lower, notFoundKeywords):
+			return utils.ReasonShareNotFound
+		case containsAny(lower, expiredKeywords):
+			return utils.ReasonShareExpired
+		}
+	}
+	return utils.DefaultReason(status)
+}
+
+// requestErrorReason 根据请求错误判断原因码
+func requestErrorReason(err error) string {
+	switch {
+	case errors.IsTimeoutError(err):
+		return utils.ReasonTimeout
+	case errors.IsRateLimitError(err):
+		return utils.ReasonRateLimited
+	case errors.IsUnavailableError(err):
+		return utils.ReasonProviderUnavailable
+	case errors.IsServerError(err):
+		return utils.ReasonUpstream5xx
+	case errors.IsParseError(err):
+		return utils.ReasonParseError
+	}
+	return utils.ReasonRequestError
+}
+
+// containsAny 判断字符串是否包含任意关键词
+func containsAny(s string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(s, keyword) {
+			return true
+		}
+	}
+	return false
+}
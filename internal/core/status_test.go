@@ -0,0 +1,59 @@
+package core
+
+import (
+	"testing"
+
+	"share-sniffer/internal/utils"
+)
+
+func TestClassifyMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want utils.ErrorType
+	}{
+		{name: "need password", msg: "需要提取码", want: utils.NeedPassword},
+		{name: "wrong password", msg: "分享提取码错误", want: utils.NeedPassword},
+		{name: "login", msg: "请先登录后查看", want: utils.LoginRequired},
+		{name: "rate limited", msg: "操作过于频繁，请稍后再试", want: utils.RateLimited},
+		{name: "captcha", msg: "请输入验证码", want: utils.RateLimited},
+		{name: "banned", msg: "该分享涉嫌侵权，已被屏蔽", want: utils.Banned},
+		{name: "expired", msg: "分享已过期", want: utils.Invalid},
+		{name: "empty", msg: "", want: utils.Invalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyMessage(tt.msg, utils.Invalid); got != tt.want {
+				t.Errorf("classifyMessage(%q) = %d, want %d", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageResultReason(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{name: "wrong password", msg: "分享提取码错误", want: utils.ReasonWrongPasscode},
+		{name: "wrong password en", msg: "Incorrect password", want: utils.ReasonWrongPasscode},
+		{name: "need password", msg: "需要提取码", want: utils.ReasonPasscodeRequired},
+		{name: "need password with error", msg: "请输入提取码，参数错误", want: utils.ReasonPasscodeRequired},
+		{name: "invalid request", msg: "password parameter invalid", want: utils.ReasonPasscodeRequired},
+		{name: "expired", msg: "分享已过期", want: utils.ReasonShareExpired},
+		{name: "cancelled", msg: "分享已取消", want: utils.ReasonShareCancelled},
+		{name: "not found", msg: "文件不存在", want: utils.ReasonShareNotFound},
+		{name: "banned", msg: "该分享涉嫌侵权", want: utils.ReasonBanned},
+		{name: "unknown", msg: "", want: utils.ReasonShareInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messageResult(tt.msg, "").Reason; got != tt.want {
+				t.Errorf("messageResult(%q).Reason = %s, want %s", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"share-sniffer/internal/errors"
 	apphttp "share-sniffer/internal/http"
 	"share-sniffer/internal/logger"
+	"share-sniffer/internal/parser"
 	"share-sniffer/internal/utils"
 )
 
@@ -31,6 +32,24 @@ func (q *TelecomChecker) GetPrefix() []string {
 	return config.GetSupportedTelecom()
 }
 
+// ShareID 实现ShareIdentifier接口
+func (q *TelecomChecker) ShareID(urlStr string) string {
+	codeValue, _, _ := extractParamsTelecom(urlStr)
+	return codeValue
+}
+
+// Normalize 实现Normalizer接口，/web/share?code= 形式统一为 /t/ 短链接
+func (q *TelecomChecker) Normalize(urlStr string) (string, string) {
+	codeValue, _, err := extractParamsTelecom(urlStr)
+	if err != nil {
+		return "", ""
+	}
+	return telecomShortPrefix + codeValue, codeValue
+}
+
+// telecomShortPrefix 电信云盘短链接前缀
+const telecomShortPrefix = "https://cloud.189.cn/t/"
+
 func (q *TelecomChecker) checkTelecom(ctx context.Context, urlStr string) utils.Result {
 	logger.Debug("TelecomChecker:开始检测电信云盘链接: %s", urlStr)
 
@@ -39,29 +58,40 @@ func (q *TelecomChecker) checkTelecom(ctx context.Context, urlStr string) utils.
 		logger.Info("TelecomChecker:extractParamsTelecom,%s,错误: %v\n", urlStr, err)
 		return utils.ErrorMalformed(urlStr, "链接格式无效")
 	}
+	accessCode := parser.PasswordFromURL(urlStr)
 
 	response, err := telecomRequest(ctx, codeValue, refererValue)
 	if err != nil {
-		if errors.IsTimeoutError(err) {
-			return utils.ErrorTimeout()
-		}
 		if errors.IsStatusCodeError(err) {
 			return utils.ErrorInvalid("分享链接失效")
 		}
-		return utils.ErrorFatal("检测失败")
+		return errorResult(err)
 	}
 
 	if response.ResCode == 0 && response.ResMessage == "成功" {
-		return utils.ErrorValid(response.FileName)
+		// 需要访问码的分享，校验链接中携带的访问码
+		if response.NeedAccessCode == 1 {
+			if accessCode == "" {
+				return utils.ErrorNeedPassword("需要访问码")
+			}
+			if err = telecomCheckAccessCode(ctx, codeValue, accessCode, refererValue); err != nil {
+				logger.Info("TelecomChecker:访问码校验失败: %s, 错误: %v", urlStr, err)
+				return errorResult(err)
+			}
+		}
+		return utils.ErrorValid(response.FileName).WithMeta(response.meta())
 	}
 
 	logger.Debug("TelecomChecker:接口返回业务错误: res_code=%d, res_message=%s", response.ResCode, response.ResMessage)
-	return utils.ErrorInvalid("分享内容无法访问")
+	return messageResult(response.ResMessage, "分享内容无法访问")
 }
 
+// telecomAPIBase 电信云盘分享接口地址
+var telecomAPIBase = "https://cloud.189.cn/api/open/share"
+
 func telecomRequest(ctx context.Context, codeValue string, refererValue string) (*TelecomResp, error) {
 	// 4. 构建目标URL
-	baseURL := "https://cloud.189.cn/api/open/share/getShareInfoByCodeV2.action"
+	baseURL := telecomAPIBase + "/getShareInfoByCodeV2.action"
 
 	params := url.Values{}
 	params.Set("noCache", fmt.Sprintf("%f", rand.New(rand.NewSource(time.Now().UnixNano())).Float64()))
@@ -102,9 +132,68 @@ func telecomRequest(ctx context.Context, codeValue string, refererValue string)
 }
 
 type TelecomResp struct {
-	ResCode    int    `json:"res_code"`
-	ResMessage string `json:"res_message"`
-	FileName   string `json:"fileName"`
+	ResCode        int       `json:"res_code"`
+	ResMessage     string    `json:"res_message"`
+	FileName       string    `json:"fileName"`
+	FileSize       flexInt64 `json:"fileSize"`
+	IsFolder       bool      `json:"isFolder"`
+	NeedAccessCode int       `json:"needAccessCode"` // 1表示需要访问码
+	Creator        struct {
+		NickName string `json:"nickName"`
+	} `json:"creator"`
+}
+
+// telecomCheckAccessCode 校验分享的访问码，访问码错误时返回 *ResultError
+func telecomCheckAccessCode(ctx context.Context, codeValue, accessCode, refererValue string) error {
+	params := url.Values{}
+	params.Set("shareCode", codeValue)
+	params.Set("accessCode", accessCode)
+
+	req, err := apphttp.NewRequestWithContext(ctx, "GET", telecomAPIBase+"/checkAccessCode.action?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("referer", refererValue)
+	req.Header.Set("sign-type", "1")
+	req.Header.Set("accept", "application/json;charset=UTF-8")
+
+	resp, err := apphttp.DoWithRetry(ctx, req, 0)
+	if err != nil {
+		return err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.NewStatusCodeError(fmt.Sprintf("HTTP状态码: %d", resp.StatusCode))
+	}
+
+	var response struct {
+		ResCode    int    `json:"res_code"`
+		ResMessage string `json:"res_message"`
+	}
+	if err = json.Unmarshal(body, &response); err != nil {
+		return errors.NewParseError("解析JSON失败", err)
+	}
+	if response.ResCode != 0 {
+		logger.Debug("TelecomChecker:访问码校验返回: res_code=%d, res_message=%s", response.ResCode, response.ResMessage)
+		return resultError(utils.ErrorNeedPassword("访问码错误").WithReason(utils.ReasonWrongPasscode))
+	}
+	return nil
+}
+
+// meta 生成分享内容元数据，电信云盘的分享只有一个顶层文件或文件夹
+func (r *TelecomResp) meta() *utils.Metadata {
+	meta := &utils.Metadata{
+		FileCount: 1,
+		TotalSize: int64(r.FileSize),
+		Creator:   r.Creator.NickName,
+	}
+	meta.AddEntry(r.FileName, int64(r.FileSize), r.IsFolder)
+	return meta
 }
 
 func extractParamsTelecom(urlStr string) (string, string, error) {
@@ -1,9 +1,64 @@
 package core
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"share-sniffer/internal/utils"
 )
 
+func TestTelecomChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/getShareInfoByCodeV2.action":
+			switch query.Get("shareCode") {
+			case "OpenShare001":
+				w.Write([]byte(`{"res_code":0,"res_message":"成功","fileName":"open.zip","fileSize":10,"needAccessCode":0}`))
+			case "CodeShare002":
+				w.Write([]byte(`{"res_code":0,"res_message":"成功","fileName":"code.zip","fileSize":20,"needAccessCode":1}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case "/checkAccessCode.action":
+			if query.Get("accessCode") == "ab12" {
+				w.Write([]byte(`{"res_code":0,"res_message":"成功","shareId":1}`))
+				return
+			}
+			w.Write([]byte(`{"res_code":40002,"res_message":"访问码错误"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apiBase := telecomAPIBase
+	telecomAPIBase = server.URL
+	defer func() { telecomAPIBase = apiBase }()
+
+	checker := &TelecomChecker{}
+	tests := []struct {
+		url        string
+		wantError  utils.ErrorType
+		wantReason string
+		wantName   string
+	}{
+		{"https://cloud.189.cn/t/OpenShare001", utils.Valid, utils.ReasonOK, "open.zip"},
+		{"https://cloud.189.cn/t/CodeShare002?pwd=ab12", utils.Valid, utils.ReasonOK, "code.zip"},
+		{"https://cloud.189.cn/web/share?code=CodeShare002&pwd=ab12", utils.Valid, utils.ReasonOK, "code.zip"},
+		{"https://cloud.189.cn/t/CodeShare002", utils.NeedPassword, utils.ReasonPasscodeRequired, ""},
+		{"https://cloud.189.cn/t/CodeShare002?pwd=zz99", utils.NeedPassword, utils.ReasonWrongPasscode, ""},
+	}
+	for _, tt := range tests {
+		got := checker.Check(context.Background(), tt.url)
+		if got.Error != tt.wantError || got.Reason != tt.wantReason || got.Data.Name != tt.wantName {
+			t.Errorf("Check(%q) = %d %s %q %q, want %d %s %q", tt.url, got.Error, got.Reason, got.Msg, got.Data.Name, tt.wantError, tt.wantReason, tt.wantName)
+		}
+	}
+}
+
 func TestExtractParamsTelecom(t *testing.T) {
 	// 定义测试用例
 	testCases := []struct {
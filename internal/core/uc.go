@@ -31,6 +31,12 @@ func (u *UcChecker) GetPrefix() []string {
 	return config.GetSupportedUc()
 }
 
+// ShareID 实现ShareIdentifier接口
+func (u *UcChecker) ShareID(urlStr string) string {
+	code, _ := extractParamsUc(urlStr)
+	return code
+}
+
 func (u *UcChecker) checkUc(ctx context.Context, urlStr string) utils.Result {
 	logger.Debug("UcChecker:开始检测UC网盘链接: %s", urlStr)
 
@@ -39,36 +45,93 @@ func (u *UcChecker) checkUc(ctx context.Context, urlStr string) utils.Result {
 		return utils.ErrorMalformed(urlStr, "链接格式无效")
 	}
 
-	response, err := ucRequest(ctx, code)
+	response, err := ucRequest(ctx, code, "0", 1)
 	if err != nil {
-		if errors.IsTimeoutError(err) {
-			return utils.ErrorTimeout()
-		}
-		return utils.ErrorFatal("检测失败")
+		return errorResult(err)
 	}
 
 	if response.Status == http.StatusOK && response.Code == 0 {
-		return utils.ErrorValid(response.Data.DetailInfo.Share.Title)
+		return utils.ErrorValid(response.Data.DetailInfo.Share.Title).WithMeta(response.meta())
 	}
 
-	return utils.ErrorInvalid("分享链接失效")
+	return messageResult(response.Message, "分享链接失效")
 }
 
 type ucResp struct {
-	Status int `json:"status"`
-	Code   int `json:"code"`
-	Data   struct {
+	Status  int    `json:"status"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
 		DetailInfo struct {
 			Share struct {
-				Title string `json:"title"`
+				Title     string    `json:"title"`
+				FileNum   int       `json:"file_num"`
+				Size      flexInt64 `json:"size"`
+				ExpiredAt flexInt64 `json:"expired_at"`
 			} `json:"share"`
+			Author struct {
+				NickName string `json:"nick_name"`
+			} `json:"author"`
+			List []sharepageFile `json:"list"`
 		} `json:"detail_info"`
 	} `json:"data"`
+	Metadata struct {
+		Total int `json:"_total"`
+	} `json:"metadata"`
+}
+
+// meta 根据分享详情生成分享内容元数据
+func (r *ucResp) meta() *utils.Metadata {
+	info := r.Data.DetailInfo
+	meta := &utils.Metadata{
+		FileCount: r.Metadata.Total,
+		TotalSize: int64(info.Share.Size),
+		ExpiresAt: expiryMillis(int64(info.Share.ExpiredAt)),
+		Creator:   info.Author.NickName,
+	}
+	if meta.FileCount == 0 {
+		meta.FileCount = max(info.Share.FileNum, len(info.List))
+	}
+	var listSize int64
+	for _, f := range info.List {
+		listSize += int64(f.Size)
+		meta.AddEntry(f.FileName, int64(f.Size), f.Dir)
+	}
+	if meta.TotalSize == 0 {
+		meta.TotalSize = listSize
+	}
+	return meta
+}
+
+// ucListPageSize 分享详情接口每页的条目数
+const ucListPageSize = 50
+
+// List 实现Lister接口，通过分享详情接口的pdir_fid参数逐层列举
+func (u *UcChecker) List(ctx context.Context, urlStr string, opts ListOptions) ([]*ShareNode, bool, error) {
+	code, err := extractParamsUc(urlStr)
+	if err != nil {
+		return nil, false, resultError(utils.ErrorMalformed(urlStr, "链接格式无效"))
+	}
+
+	return walkTree(ctx, func(ctx context.Context, dirID string, page int) ([]listEntry, bool, error) {
+		if dirID == "" {
+			dirID = "0"
+		}
+		response, err := ucRequest(ctx, code, dirID, page)
+		if err != nil {
+			return nil, false, err
+		}
+		if response.Status != http.StatusOK || response.Code != 0 {
+			return nil, false, resultError(messageResult(response.Message, "分享链接失效"))
+		}
+		return sharepageEntries(response.Data.DetailInfo.List), page*ucListPageSize < response.Metadata.Total, nil
+	}, opts)
 }
 
-func ucRequest(ctx context.Context, code string) (*ucResp, error) {
+// ucRequest 按页获取UC网盘分享详情，dirID为"0"时获取顶层目录
+func ucRequest(ctx context.Context, code string, dirID string, page int) (*ucResp, error) {
 	apiURL := "https://pc-api.uc.cn/1/clouddrive/share/sharepage/v2/detail?pr=UCBrowser&fr=pc"
-	requestBody := fmt.Sprintf(`{"pwd_id":"%s","passcode":"","force":0,"page":1,"size":50,"fetch_banner":1,"fetch_share":1,"fetch_total":1,"sort":"file_type:asc,file_name:asc","banner_platform":"other","web_platform":"windows","fetch_error_background":1}`, code)
+	requestBody := fmt.Sprintf(`{"pwd_id":"%s","passcode":"","pdir_fid":"%s","force":0,"page":%d,"size":%d,"fetch_banner":1,"fetch_share":1,"fetch_total":1,"sort":"file_type:asc,file_name:asc","banner_platform":"other","web_platform":"windows","fetch_error_background":1}`, code, dirID, page, ucListPageSize)
 
 	req, err := apphttp.NewRequestWithContext(ctx, "POST", apiURL, strings.NewReader(requestBody))
 	if err != nil {
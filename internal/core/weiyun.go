@@ -0,0 +1,269 @@
+// Package core Copyright 2025 Share Sniffer
+//
+// weiyun.go 实现了腾讯微云链接检查器，作为策略模式的具体策略实现
+// 提供了WeiyunChecker结构体，实现了LinkChecker接口的Check和GetPrefix方法
+// 通过网页版使用的分享信息接口检测分享状态并获取分享标题
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/errors"
+	apphttp "share-sniffer/internal/http"
+	"share-sniffer/internal/logger"
+	"share-sniffer/internal/parser"
+	"share-sniffer/internal/utils"
+)
+
+// WeiyunChecker 腾讯微云链接检查器
+// 实现了LinkChecker接口，是策略模式的具体策略之一
+// 负责检查腾讯微云分享链接的有效性和获取分享内容信息
+type WeiyunChecker struct{}
+
+// Check 实现LinkChecker接口的Check方法
+// 调用内部的checkWeiyun方法执行具体的检查逻辑
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的腾讯微云分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体
+func (w *WeiyunChecker) Check(ctx context.Context, urlStr string) utils.Result {
+	return w.checkWeiyun(ctx, urlStr)
+}
+
+// GetPrefix 实现LinkChecker接口的GetPrefix方法
+// 返回腾讯微云链接的前缀，用于在注册时识别
+//
+// 返回值:
+// - []string: 腾讯微云链接的前缀数组，从配置中获取
+func (w *WeiyunChecker) GetPrefix() []string {
+	return config.GetSupportedWeiyun()
+}
+
+// ShareID 实现ShareIdentifier接口
+func (w *WeiyunChecker) ShareID(urlStr string) string {
+	shareKey, _, _ := extractParamsWeiyun(urlStr)
+	return shareKey
+}
+
+// checkWeiyun 检测腾讯微云链接是否有效
+//
+// 参数:
+// - ctx: 上下文，用于控制超时和取消
+// - urlStr: 需要检查的腾讯微云分享链接
+//
+// 返回值:
+// - Result: 包含检查结果的结构体，包括URL、资源名称、错误码和耗时
+func (w *WeiyunChecker) checkWeiyun(ctx context.Context, urlStr string) utils.Result {
+	logger.Debug("WeiyunChecker:开始检测腾讯微云链接: %s", urlStr)
+
+	shareKey, sharePwd, err := extractParamsWeiyun(urlStr)
+	if err != nil {
+		logger.Info("WeiyunChecker:extractParamsWeiyun, %s, 错误: %v\n", urlStr, err)
+		return utils.ErrorMalformed(urlStr, "链接格式无效")
+	}
+
+	requestStart := time.Now()
+	response, err := weiyunShareRequest(ctx, shareKey, sharePwd)
+	logger.Debug("WeiyunChecker:接口请求完成，请求耗时: %dms", time.Since(requestStart).Milliseconds())
+	if err != nil {
+		logger.Info("WeiyunChecker:接口检测失败: %s, 错误: %v", urlStr, err)
+		return errorResult(err)
+	}
+	return response.result()
+}
+
+// weiyunShareURL 分享信息接口地址
+var weiyunShareURL = "https://share.weiyun.com/webapp/json/weiyunShare/WeiyunShareView"
+
+const (
+	// weiyunShareCmd 查看分享的命令字
+	weiyunShareCmd = 12002
+	// weiyunAppID 网页版的应用ID
+	weiyunAppID = 30113
+)
+
+// weiyunShareResp 分享信息接口响应结构
+type weiyunShareResp struct {
+	Data struct {
+		RspHeader struct {
+			Retcode int    `json:"retcode"`
+			Retmsg  string `json:"retmsg"`
+		} `json:"rsp_header"`
+		RspBody struct {
+			RspMsgBody struct {
+				ShareName     string `json:"share_name"`
+				ShareNickName string `json:"share_nick_name"`
+				// 分享中的文件夹
+				DirList []struct {
+					DirName string `json:"dir_name"`
+				} `json:"dir_list"`
+				// 分享中的文件
+				FileList []struct {
+					FileName string    `json:"filename"`
+					FileSize flexInt64 `json:"file_size"`
+				} `json:"file_list"`
+			} `json:"RspMsg_body"`
+		} `json:"rsp_body"`
+	} `json:"data"`
+}
+
+// 分享信息接口的业务错误码
+// 尚未用抓包核对，未列出的错误码按提示信息判断
+const (
+	weiyunCodeDeleted       = 114100 // 分享已被删除
+	weiyunCodeExpired       = 114101 // 分享已过期
+	weiyunCodeNotFound      = 114102 // 分享不存在
+	weiyunCodeNeedPassword  = 114303 // 需要访问密码
+	weiyunCodeWrongPassword = 114304 // 访问密码错误
+	weiyunCodeBanned        = 114400 // 分享涉嫌违规被屏蔽
+)
+
+// result 根据接口响应创建检测结果，失败时按错误码区分删除、过期、需要访问密码和违规，
+// 未知的错误码再根据提示信息判断
+func (r *weiyunShareResp) result() utils.Result {
+	header := r.Data.RspHeader
+	if header.Retcode != 0 {
+		logger.Debug("WeiyunChecker:接口返回业务错误: retcode=%d, retmsg=%s", header.Retcode, header.Retmsg)
+		switch header.Retcode {
+		case weiyunCodeDeleted:
+			return utils.ErrorInvalid("分享已被删除").WithReason(utils.ReasonShareCancelled)
+		case weiyunCodeExpired:
+			return utils.ErrorInvalid("分享已过期").WithReason(utils.ReasonShareExpired)
+		case weiyunCodeNotFound:
+			return utils.ErrorInvalid("分享不存在").WithReason(utils.ReasonShareNotFound)
+		case weiyunCodeNeedPassword:
+			return utils.ErrorNeedPassword("需要访问密码")
+		case weiyunCodeWrongPassword:
+			return utils.ErrorNeedPassword("访问密码错误").WithReason(utils.ReasonWrongPasscode)
+		case weiyunCodeBanned:
+			return utils.ErrorBanned("分享涉嫌违规，已被屏蔽")
+		}
+		return messageResult(header.Retmsg, "")
+	}
+
+	body := r.Data.RspBody.RspMsgBody
+	meta := r.meta()
+	if meta.FileCount == 0 {
+		return utils.ErrorInvalid("分享中没有文件").WithReason(utils.ReasonEmptyShare)
+	}
+	name := body.ShareName
+	if name == "" {
+		name = meta.Entries[0].Name
+	}
+	return utils.ErrorValid(name).WithMeta(meta)
+}
+
+// meta 生成分享内容元数据
+func (r *weiyunShareResp) meta() *utils.Metadata {
+	body := r.Data.RspBody.RspMsgBody
+	meta := &utils.Metadata{
+		FileCount: len(body.DirList) + len(body.FileList),
+		Creator:   body.ShareNickName,
+	}
+	for _, dir := range body.DirList {
+		meta.AddEntry(dir.DirName, 0, true)
+	}
+	for _, file := range body.FileList {
+		meta.TotalSize += int64(file.FileSize)
+		meta.AddEntry(file.FileName, int64(file.FileSize), false)
+	}
+	return meta
+}
+
+// weiyunShareRequest 获取腾讯微云分享的标题和根目录内容
+// 接口的请求头和请求体都是序列化后的JSON字符串
+func weiyunShareRequest(ctx context.Context, shareKey, sharePwd string) (*weiyunShareResp, error) {
+	reqHeader, _ := json.Marshal(map[string]interface{}{
+		"seq":           time.Now().UnixMilli(),
+		"type":          1,
+		"cmd":           weiyunShareCmd,
+		"appid":         weiyunAppID,
+		"version":       3,
+		"major_version": 3,
+		"minor_version": 3,
+		"fix_version":   3,
+		"wx_openid":     "",
+		"user_flag":     0,
+	})
+	reqBody, _ := json.Marshal(map[string]interface{}{
+		"ReqMsg_body": map[string]interface{}{
+			"ext_req_head": map[string]interface{}{
+				"token_info": map[string]interface{}{
+					"token_type":     0,
+					"login_key_type": 1,
+				},
+			},
+			".weiyun.WeiyunShareViewMsgReq_body": map[string]interface{}{
+				"share_pwd": sharePwd,
+				"share_key": shareKey,
+			},
+		},
+	})
+	jsonBody, _ := json.Marshal(map[string]string{
+		"req_header": string(reqHeader),
+		"req_body":   string(reqBody),
+	})
+
+	apiURL := fmt.Sprintf("%s?refer=chrome_windows&g_tk=&r=%d", weiyunShareURL, time.Now().UnixNano())
+	req, err := apphttp.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("content-type", "application/json;charset=UTF-8")
+	req.Header.Set("origin", "https://share.weiyun.com")
+	req.Header.Set("referer", "https://share.weiyun.com/"+shareKey)
+
+	resp, err := apphttp.DoWithRetry(ctx, req, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.NewStatusCodeError(fmt.Sprintf("HTTP状态码: %d", resp.StatusCode))
+	}
+
+	var response weiyunShareResp
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, errors.NewParseError("解析JSON失败", err)
+	}
+
+	return &response, nil
+}
+
+// extractParamsWeiyun 从腾讯微云链接中提取分享key和访问密码
+// 链接形如 https://share.weiyun.com/5Xyz1AbC，访问密码通过 pwd 参数携带
+func extractParamsWeiyun(rawURL string) (shareKey, sharePwd string, err error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	if parsedURL.Host != "share.weiyun.com" {
+		return "", "", fmt.Errorf("不是腾讯微云分享链接")
+	}
+
+	shareKey = strings.Trim(parsedURL.Path, "/")
+	if shareKey == "" || strings.Contains(shareKey, "/") {
+		return "", "", fmt.Errorf("无法寻找分享key")
+	}
+	return shareKey, parser.PasswordFromURL(rawURL), nil
+}
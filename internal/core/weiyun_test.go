@@ -0,0 +1,124 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"share-sniffer/internal/utils"
+)
+
+// 分享信息接口的响应样例
+// 按网页版接口的结构手工构造，不是抓包得到的真实响应，错误码和提示信息仅用于覆盖各个分支；
+// 部分错误码的提示信息留空，确认按错误码而不是提示信息判断
+var weiyunShareResponses = map[string]string{
+	"5Ok": `{"data":{"rsp_header":{"retcode":0,"retmsg":"success"},"rsp_body":{"RspMsg_body":{
+		"share_name":"旅行照片","share_nick_name":"微云用户",
+		"dir_list":[{"dir_key":"d1","dir_name":"第一天"}],
+		"file_list":[{"file_id":"f1","filename":"封面.jpg","file_size":"2048"},{"file_id":"f2","filename":"行程.docx","file_size":1024}]}}}}`,
+	"5Empty":   `{"data":{"rsp_header":{"retcode":0,"retmsg":"success"},"rsp_body":{"RspMsg_body":{"share_name":"","dir_list":[],"file_list":[]}}}}`,
+	"5Lock":    `{"data":{"rsp_header":{"retcode":114303,"retmsg":""}}}`,
+	"5Wrong":   `{"data":{"rsp_header":{"retcode":114304,"retmsg":"分享密码错误"}}}`,
+	"5Expired": `{"data":{"rsp_header":{"retcode":114101,"retmsg":""}}}`,
+	"5Deleted": `{"data":{"rsp_header":{"retcode":114100,"retmsg":""}}}`,
+	"5Gone":    `{"data":{"rsp_header":{"retcode":114102,"retmsg":"分享不存在"}}}`,
+	"5Banned":  `{"data":{"rsp_header":{"retcode":114400,"retmsg":"分享内容涉嫌违规，已被屏蔽"}}}`,
+	"5Other":   `{"data":{"rsp_header":{"retcode":190099,"retmsg":"分享链接已失效"}}}`,
+}
+
+func TestWeiyunChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ReqHeader string `json:"req_header"`
+			ReqBody   string `json:"req_body"`
+		}
+		var body struct {
+			ReqMsgBody struct {
+				View struct {
+					SharePwd string `json:"share_pwd"`
+					ShareKey string `json:"share_key"`
+				} `json:".weiyun.WeiyunShareViewMsgReq_body"`
+			} `json:"ReqMsg_body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || json.Unmarshal([]byte(req.ReqBody), &body) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		shareKey := body.ReqMsgBody.View.ShareKey
+		if shareKey == "5Lock" && body.ReqMsgBody.View.SharePwd == "ab12" {
+			shareKey = "5Ok"
+		}
+		resp, ok := weiyunShareResponses[shareKey]
+		if !ok {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(resp))
+	}))
+	defer server.Close()
+
+	apiURL := weiyunShareURL
+	weiyunShareURL = server.URL
+	defer func() { weiyunShareURL = apiURL }()
+
+	checker := &WeiyunChecker{}
+	tests := []struct {
+		url        string
+		wantError  utils.ErrorType
+		wantReason string
+	}{
+		{"https://share.weiyun.com/5Ok", utils.Valid, utils.ReasonOK},
+		{"https://share.weiyun.com/5Empty", utils.Invalid, utils.ReasonEmptyShare},
+		{"https://share.weiyun.com/5Lock", utils.NeedPassword, utils.ReasonPasscodeRequired},
+		{"https://share.weiyun.com/5Lock?pwd=ab12", utils.Valid, utils.ReasonOK},
+		{"https://share.weiyun.com/5Wrong?pwd=0000", utils.NeedPassword, utils.ReasonWrongPasscode},
+		{"https://share.weiyun.com/5Expired", utils.Invalid, utils.ReasonShareExpired},
+		{"https://share.weiyun.com/5Deleted", utils.Invalid, utils.ReasonShareCancelled},
+		{"https://share.weiyun.com/5Gone", utils.Invalid, utils.ReasonShareNotFound},
+		{"https://share.weiyun.com/5Banned", utils.Banned, utils.ReasonBanned},
+		{"https://share.weiyun.com/5Other", utils.Invalid, utils.ReasonShareExpired},
+		{"https://share.weiyun.com/", utils.Malformed, utils.ReasonMalformedURL},
+	}
+	for _, tt := range tests {
+		got := checker.Check(context.Background(), tt.url)
+		if got.Error != tt.wantError || got.Reason != tt.wantReason {
+			t.Errorf("Check(%q) = %d %s %q, want %d %s", tt.url, got.Error, got.Reason, got.Msg, tt.wantError, tt.wantReason)
+		}
+	}
+
+	got := checker.Check(context.Background(), "https://share.weiyun.com/5Ok")
+	if got.Data.Name != "旅行照片" || got.Data.Meta == nil {
+		t.Fatalf("Check(5Ok) = %+v", got)
+	}
+	if meta := got.Data.Meta; meta.FileCount != 3 || meta.TotalSize != 3072 || meta.Creator != "微云用户" || len(meta.Entries) != 3 || !meta.Entries[0].IsDir {
+		t.Errorf("Check(5Ok) meta = %+v", meta)
+	}
+
+	if _, err := weiyunShareRequest(context.Background(), "5Unknown", ""); err == nil {
+		t.Errorf("weiyunShareRequest(5Unknown) 预期状态码错误")
+	}
+}
+
+func TestExtractParamsWeiyun(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantKey string
+		wantPwd string
+		wantErr bool
+	}{
+		{"https://share.weiyun.com/5Xyz1AbC", "5Xyz1AbC", "", false},
+		{"https://share.weiyun.com/5Xyz1AbC/", "5Xyz1AbC", "", false},
+		{"https://share.weiyun.com/5Xyz1AbC?pwd=ab12", "5Xyz1AbC", "ab12", false},
+		{"https://share.weiyun.com/", "", "", true},
+		{"https://share.weiyun.com/a/b", "", "", true},
+		{"https://www.weiyun.com/disk", "", "", true},
+	}
+	for _, tt := range tests {
+		key, pwd, err := extractParamsWeiyun(tt.url)
+		if (err != nil) != tt.wantErr || key != tt.wantKey || pwd != tt.wantPwd {
+			t.Errorf("extractParamsWeiyun(%q) = %q, %q, %v, want %q, %q", tt.url, key, pwd, err, tt.wantKey, tt.wantPwd)
+		}
+	}
+}
@@ -2,18 +2,21 @@
 //
 // xunlei.go 实现了迅雷网盘链接检查器，作为策略模式的具体策略实现
 // 提供了XunleiChecker结构体，实现了LinkChecker接口的Check和GetPrefix方法
-// 包含链接验证、页面访问和结果解析等完整流程
+// 优先通过分享接口检测，接口流程失败且有可用浏览器时改用浏览器渲染页面检测
 package core
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strings"
 	"time"
 
 	"github.com/chromedp/chromedp"
+	"share-sniffer/internal/browser"
 	"share-sniffer/internal/config"
 	"share-sniffer/internal/logger"
+	"share-sniffer/internal/parser"
 	"share-sniffer/internal/utils"
 )
 
@@ -44,8 +47,14 @@ func (x *XunleiChecker) GetPrefix() []string {
 	return config.GetSupportedXunlei()
 }
 
+// ShareID 实现ShareIdentifier接口
+// 返回迅雷网盘链接路径中的分享ID
+func (x *XunleiChecker) ShareID(urlStr string) string {
+	return lastPathSegment(urlStr)
+}
+
 // checkXunlei 检测迅雷网盘链接是否有效
-// 这是XunleiChecker的核心方法，执行完整的链接检查流程
+// 这是XunleiChecker的核心方法，先通过分享接口检测，接口流程失败时改用浏览器检测
 //
 // 参数:
 // - ctx: 上下文，用于控制超时和取消
@@ -55,60 +64,44 @@ func (x *XunleiChecker) GetPrefix() []string {
 // - Result: 包含检查结果的结构体，包括URL、资源名称、错误码和耗时
 func (x *XunleiChecker) checkXunlei(ctx context.Context, urlStr string) utils.Result {
 	logger.Debug("XunleiChecker:开始检测迅雷网盘链接: %s", urlStr)
-	requestStart := time.Now()
-
-	// 使用传入的context，不再创建新的context
-	logger.Debug("使用传入的context进行迅雷链接检测")
 
-	// 验证URL格式
-	parsedURL, err := url.ParseRequestURI(urlStr)
+	shareID, passCode, err := extractParamsXunlei(urlStr)
 	if err != nil {
-		logger.Info("XunleiChecker:ParseRequestURI, %s, 错误: %v\n", urlStr, err)
+		logger.Info("XunleiChecker:extractParamsXunlei, %s, 错误: %v\n", urlStr, err)
 		return utils.ErrorMalformed(urlStr, "链接格式无效")
 	}
 
-	// 确保是迅雷网盘链接
-	if !strings.Contains(parsedURL.Host, "pan.xunlei.com") && !strings.Contains(parsedURL.Host, "lixian.vip.xunlei.com") {
-		logger.Info("XunleiChecker:不是迅雷网盘链接: %s\n", urlStr)
-		return utils.ErrorMalformed(urlStr, "链接格式无效")
+	requestStart := time.Now()
+	response, err := xunleiShareRequest(ctx, shareID, passCode)
+	logger.Debug("XunleiChecker:接口请求完成，请求耗时: %dms", time.Since(requestStart).Milliseconds())
+	if err == nil {
+		return response.result()
 	}
 
-	// 配置Chrome浏览器选项
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		// 基本配置
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-
-		// 更新用户代理为现代Chrome版本
-		chromedp.UserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/143.0.0.0 Safari/537.36"),
-
-		// 核心性能优化：禁用不必要的资源加载
-		chromedp.Flag("blink-settings", "imagesEnabled=false,cssEnabled=false"),
-		chromedp.Flag("disable-plugins", true),
-		chromedp.Flag("disable-extensions", true),
-		chromedp.Flag("disable-component-extensions-with-background-pages", true),
-		chromedp.Flag("disable-preconnect", true),
-		chromedp.Flag("disable-prefetch", true),
-		chromedp.Flag("disable-predictive-networking", true),
-		chromedp.Flag("disable-background-networking", true),
-		chromedp.Flag("disable-javascript-timeouts", true),
-		chromedp.Flag("disable-blink-features", "AutomationControlled"),
-		chromedp.Flag("disk-cache-size", "0"),
-		chromedp.Flag("media-cache-size", "0"),
-		chromedp.Flag("window-size", "1280,800"),
-	)
+	// 只有请求失败时才换用浏览器，超时、限流和熔断时换用浏览器也无济于事
+	if result := errorResult(err); ctx.Err() != nil || result.Error != utils.Fatal || !browser.Available() {
+		logger.Info("XunleiChecker:接口检测失败: %s, 错误: %v", urlStr, err)
+		return result
+	}
+	logger.Info("XunleiChecker:接口检测失败，改用浏览器检测: %s, 错误: %v", urlStr, err)
+	return x.checkXunleiBrowser(ctx, urlStr)
+}
 
-	// 创建执行上下文
-	execCtx, execCancel := chromedp.NewExecAllocator(ctx, opts...)
-	// 立即定义defer确保资源释放
-	defer execCancel()
+// checkXunleiBrowser 通过浏览器渲染分享页面检测链接，作为接口检测失败时的后备
+func (x *XunleiChecker) checkXunleiBrowser(ctx context.Context, urlStr string) utils.Result {
+	requestStart := time.Now()
 
-	// 创建浏览器上下文
-	browserCtx, browserCancel := chromedp.NewContext(execCtx)
-	// 立即定义defer确保资源释放
-	defer browserCancel()
+	// 从共享的浏览器池中打开无痕标签页
+	browserCtx, release, err := browser.NewTab(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return utils.ErrorTimeout()
+		}
+		logger.Info("XunleiChecker:打开浏览器标签页失败: %s, 错误: %v", urlStr, err)
+		return utils.ErrorFatal("浏览器启动失败")
+	}
+	// 立即定义defer确保标签页释放
+	defer release()
 
 	// 导航到链接并等待页面加载完成
 	var pageContent string
@@ -148,13 +141,13 @@ func (x *XunleiChecker) checkXunlei(ctx context.Context, urlStr string) utils.Re
 		// 检查分享已删除
 		if strings.Contains(lowerPageContent, "作者删除") || strings.Contains(lowerPageContent, "分享已删除") {
 			logger.Info("XunleiChecker:分享已删除: %s, 耗时: %dms", urlStr, time.Since(requestStart).Milliseconds())
-			return utils.ErrorInvalid("该分享已被作者删除")
+			return utils.ErrorInvalid("该分享已被作者删除").WithReason(utils.ReasonShareCancelled)
 		}
 
 		// 检查分享不存在或已过期
 		if strings.Contains(lowerPageContent, "分享不存在") || strings.Contains(lowerPageContent, "已过期") || strings.Contains(lowerPageContent, "页面不存在") {
 			logger.Info("XunleiChecker:分享不存在或已过期: %s, 耗时: %dms", urlStr, time.Since(requestStart).Milliseconds())
-			return utils.ErrorInvalid("分享不存在或已过期")
+			return utils.ErrorInvalid("分享不存在或已过期").WithReason(utils.ReasonShareNotFound)
 		}
 
 		// 检查违规内容（与第三阶段保持一致，需要至少匹配2个关键词）
@@ -167,7 +160,7 @@ func (x *XunleiChecker) checkXunlei(ctx context.Context, urlStr string) utils.Re
 		}
 		if violationCount >= 2 {
 			logger.Info("XunleiChecker:分享内容违规: %s, 耗时: %dms", urlStr, time.Since(requestStart).Milliseconds())
-			return utils.ErrorInvalid("该分享内容可能涉及违规信息，无法访问！")
+			return utils.ErrorBanned("该分享内容可能涉及违规信息，无法访问！")
 		}
 	}
 
@@ -251,7 +244,7 @@ func (x *XunleiChecker) checkXunlei(ctx context.Context, urlStr string) utils.Re
 	}
 	if deletedCount >= 1 {
 		logger.Info("XunleiChecker:分享已删除: %s, 耗时: %dms", urlStr, requestElapsed)
-		return utils.ErrorInvalid("该分享已被作者删除")
+		return utils.ErrorInvalid("该分享已被作者删除").WithReason(utils.ReasonShareCancelled)
 	}
 
 	// 2. 检测涉及违规内容
@@ -264,13 +257,13 @@ func (x *XunleiChecker) checkXunlei(ctx context.Context, urlStr string) utils.Re
 	}
 	if violationCount >= 2 {
 		logger.Info("XunleiChecker:分享内容违规: %s, 耗时: %dms", urlStr, requestElapsed)
-		return utils.ErrorInvalid("该分享内容可能涉及违规信息，无法访问！")
+		return utils.ErrorBanned("该分享内容可能涉及违规信息，无法访问！")
 	}
 
 	// 3. 检测暂无文件
 	if strings.Contains(lowerPageContent, "暂无文件") {
 		logger.Info("XunleiChecker:暂无文件: %s, 耗时: %dms", urlStr, requestElapsed)
-		return utils.ErrorInvalid("暂无文件")
+		return utils.ErrorInvalid("暂无文件").WithReason(utils.ReasonEmptyShare)
 	}
 
 	// 4. 检测分享不存在或已过期
@@ -283,13 +276,13 @@ func (x *XunleiChecker) checkXunlei(ctx context.Context, urlStr string) utils.Re
 	}
 	if expiredCount >= 2 {
 		logger.Info("XunleiChecker:分享不存在或已过期: %s, 耗时: %dms", urlStr, requestElapsed)
-		return utils.ErrorInvalid("分享不存在或已过期")
+		return utils.ErrorInvalid("分享不存在或已过期").WithReason(utils.ReasonShareNotFound)
 	}
 
 	// 5. 检查是否为404页面
 	if strings.Contains(pageContent, "statusCode:404") || strings.Contains(pageContent, "path:\"\\u002Fs") {
 		logger.Info("XunleiChecker:分享不存在或已过期: %s, 耗时: %dms", urlStr, requestElapsed)
-		return utils.ErrorInvalid("分享不存在或已过期")
+		return utils.ErrorInvalid("分享不存在或已过期").WithReason(utils.ReasonShareNotFound)
 	}
 
 	// 如果从DOM中提取到了标题，使用它
@@ -317,5 +310,49 @@ func (x *XunleiChecker) checkXunlei(ctx context.Context, urlStr string) utils.Re
 
 	// 如果所有方法都失败，返回未知错误
 	logger.Info("XunleiChecker:无法获取文件夹名称: %s, 耗时: %dms", urlStr, requestElapsed)
-	return utils.ErrorInvalid("无法获分享信息")
+	return utils.ErrorInvalid("无法获分享信息").WithReason(utils.ReasonPageError)
+}
+
+// 迅雷网盘网页版客户端的接口参数
+const (
+	xunleiClientID      = "Xqp0kJBXWhwaTpB6"
+	xunleiClientVersion = "1.45.0"
+	xunleiPackageName   = "pan.xunlei.com"
+	xunleiShareAction   = "get:/drive/v1/share"
+)
+
+// xunleiShield 迅雷网盘分享接口的匿名客户端
+var xunleiShield = &shieldClient{
+	name:          "XunleiChecker",
+	clientID:      xunleiClientID,
+	clientVersion: xunleiClientVersion,
+	packageName:   xunleiPackageName,
+	action:        xunleiShareAction,
+	origin:        "https://pan.xunlei.com",
+	apiBase:       "https://api-pan.xunlei.com",
+	userBase:      "https://xluser-ssl.xunlei.com",
+}
+
+// xunleiShareRequest 获取迅雷网盘分享信息
+func xunleiShareRequest(ctx context.Context, shareID, passCode string) (*shieldShareResp, error) {
+	return xunleiShield.shareRequest(ctx, shareID, passCode)
+}
+
+// extractParamsXunlei 从迅雷网盘链接中提取分享ID和提取码
+func extractParamsXunlei(rawURL string) (shareID, passCode string, err error) {
+	parsedURL, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	if parsedURL.Host != "pan.xunlei.com" || !strings.HasPrefix(parsedURL.Path, "/s/") {
+		return "", "", fmt.Errorf("不是迅雷网盘分享链接")
+	}
+
+	shareID = lastPathSegment(rawURL)
+	if shareID == "" || shareID == "s" {
+		return "", "", fmt.Errorf("无法寻找分享ID")
+	}
+
+	return shareID, parser.PasswordFromURL(rawURL), nil
 }
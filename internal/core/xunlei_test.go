@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"share-sniffer/internal/utils"
+)
+
+// 分享接口的响应样例
+var xunleiShareResponses = map[string]string{
+	"VOk": `{"share_status":"OK","share_status_text":"","file_num":"2","expiration_left":"86400","title":"资料合集",
+		"user_info":{"nickname":"迅雷用户"},
+		"files":[{"name":"资料合集","size":"0","kind":"drive#folder"},{"name":"说明.txt","size":"1024","kind":"drive#file"}]}`,
+	"VLock":    `{"share_status":"PASS_CODE_EMPTY","share_status_text":"请输入提取码","files":[]}`,
+	"VWrong":   `{"share_status":"PASS_CODE_ERROR","share_status_text":"提取码错误","files":[]}`,
+	"VGone":    `{"share_status":"NOT_FOUND","share_status_text":"分享不存在","files":[]}`,
+	"VDeleted": `{"share_status":"DELETED","share_status_text":"","files":[]}`,
+	"VExpired": `{"share_status":"EXPIRED","share_status_text":"分享已过期","files":[]}`,
+	"VBanned":  `{"share_status":"SENSITIVE_RESOURCE","share_status_text":"该分享涉及侵权或违规内容","files":[]}`,
+}
+
+func TestXunleiChecker(t *testing.T) {
+	var captchaCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/shield/captcha/init":
+			var body struct {
+				Action   string `json:"action"`
+				DeviceID string `json:"device_id"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Action != xunleiShareAction || body.DeviceID == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"invalid_argument","error_description":"参数错误"}`))
+				return
+			}
+			// 第一次返回的令牌在分享接口中已失效
+			if captchaCalls.Add(1) == 1 {
+				w.Write([]byte(`{"captcha_token":"stale","expires_in":300}`))
+				return
+			}
+			w.Write([]byte(`{"captcha_token":"ck0.fresh","expires_in":300}`))
+		case "/drive/v1/share":
+			if r.Header.Get("x-captcha-token") != "ck0.fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"captcha_invalid","error_code":9,"error_description":"验证码无效"}`))
+				return
+			}
+			shareID := r.URL.Query().Get("share_id")
+			if shareID == "VLock" && r.URL.Query().Get("pass_code") == "ab12" {
+				shareID = "VOk"
+			}
+			body, ok := xunleiShareResponses[shareID]
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"internal","error_code":5,"error_description":"服务异常"}`))
+				return
+			}
+			w.Write([]byte(body))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apiBase, userBase := xunleiShield.apiBase, xunleiShield.userBase
+	xunleiShield.apiBase, xunleiShield.userBase = server.URL, server.URL
+	defer func() { xunleiShield.apiBase, xunleiShield.userBase = apiBase, userBase }()
+
+	checker := &XunleiChecker{}
+	tests := []struct {
+		url        string
+		wantError  utils.ErrorType
+		wantReason string
+	}{
+		{"https://pan.xunlei.com/s/VOk", utils.Valid, utils.ReasonOK},
+		{"https://pan.xunlei.com/s/VLock", utils.NeedPassword, utils.ReasonPasscodeRequired},
+		{"https://pan.xunlei.com/s/VLock?pwd=ab12#", utils.Valid, utils.ReasonOK},
+		{"https://pan.xunlei.com/s/VWrong?pwd=0000", utils.NeedPassword, utils.ReasonWrongPasscode},
+		{"https://pan.xunlei.com/s/VGone", utils.Invalid, utils.ReasonShareNotFound},
+		{"https://pan.xunlei.com/s/VDeleted", utils.Invalid, utils.ReasonShareCancelled},
+		{"https://pan.xunlei.com/s/VExpired", utils.Invalid, utils.ReasonShareExpired},
+		{"https://pan.xunlei.com/s/VBanned", utils.Banned, utils.ReasonBanned},
+		{"https://pan.xunlei.com/d/VOk", utils.Malformed, utils.ReasonMalformedURL},
+	}
+	for _, tt := range tests {
+		got := checker.Check(context.Background(), tt.url)
+		if got.Error != tt.wantError || got.Reason != tt.wantReason {
+			t.Errorf("Check(%q) = %d %s %q, want %d %s", tt.url, got.Error, got.Reason, got.Msg, tt.wantError, tt.wantReason)
+		}
+	}
+
+	// 令牌失效后只重新获取一次，之后复用
+	if n := captchaCalls.Load(); n != 2 {
+		t.Errorf("captcha init calls = %d, want 2", n)
+	}
+
+	got := checker.Check(context.Background(), "https://pan.xunlei.com/s/VOk?pwd=")
+	if got.Data.Name != "资料合集" || got.Data.Meta == nil {
+		t.Fatalf("Check(VOk) = %+v", got)
+	}
+	if meta := got.Data.Meta; meta.FileCount != 2 || meta.TotalSize != 1024 || meta.Creator != "迅雷用户" || len(meta.Entries) != 2 || !meta.Entries[0].IsDir || meta.ExpiresAt == 0 {
+		t.Errorf("Check(VOk) meta = %+v", meta)
+	}
+
+	// 接口出错时返回错误，由调用方决定是否改用浏览器
+	if _, err := xunleiShareRequest(context.Background(), "VUnknown", ""); err == nil {
+		t.Errorf("xunleiShareRequest(VUnknown) 预期接口错误")
+	}
+}
+
+func TestExtractParamsXunlei(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantID   string
+		wantCode string
+		wantErr  bool
+	}{
+		{"https://pan.xunlei.com/s/VOa1b2c3d4e5", "VOa1b2c3d4e5", "", false},
+		{"https://pan.xunlei.com/s/VOa1b2c3d4e5?pwd=ab12#", "VOa1b2c3d4e5", "ab12", false},
+		{"https://pan.xunlei.com/s/VOa1b2c3d4e5/", "VOa1b2c3d4e5", "", false},
+		{"https://pan.xunlei.com/s/", "", "", true},
+		{"https://lixian.vip.xunlei.com/s/abc", "", "", true},
+	}
+	for _, tt := range tests {
+		id, code, err := extractParamsXunlei(tt.url)
+		if (err != nil) != tt.wantErr || id != tt.wantID || code != tt.wantCode {
+			t.Errorf("extractParamsXunlei(%q) = %q, %q, %v, want %q, %q", tt.url, id, code, err, tt.wantID, tt.wantCode)
+		}
+	}
+}
@@ -2,18 +2,28 @@
 //
 // yd.go 实现了移动云盘(139云盘)链接检查器，作为策略模式的具体策略实现
 // 提供了YdChecker结构体，实现了LinkChecker接口的Check和GetPrefix方法
-// 包含链接验证、页面访问和结果解析等完整流程
+// 优先通过外链接口检测，接口流程失败且有可用浏览器时改用浏览器渲染页面检测
 package core
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
 	"net/url"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/chromedp/chromedp"
+	"share-sniffer/internal/browser"
 	"share-sniffer/internal/config"
+	"share-sniffer/internal/errors"
+	apphttp "share-sniffer/internal/http"
 	"share-sniffer/internal/logger"
+	"share-sniffer/internal/parser"
 	"share-sniffer/internal/utils"
 )
 
@@ -44,8 +54,24 @@ func (y *YdChecker) GetPrefix() []string {
 	return config.GetSupportedYd()
 }
 
+// ShareID 实现ShareIdentifier接口
+// 移动云盘的分享ID位于链接片段中，如 #/w/i/2rJV89vKpVPsr
+func (y *YdChecker) ShareID(urlStr string) string {
+	linkID, _, _ := extractParamsYd(urlStr)
+	return linkID
+}
+
+// Normalize 实现Normalizer接口，分享ID位于链接片段中，无法按前缀拼接
+func (y *YdChecker) Normalize(urlStr string) (string, string) {
+	shareID := y.ShareID(urlStr)
+	if shareID == "" {
+		return "", ""
+	}
+	return "https://yun.139.com/shareweb/#/w/i/" + shareID, shareID
+}
+
 // checkYd 检测移动云盘(139云盘)链接是否有效
-// 这是YdChecker的核心方法，执行完整的链接检查流程
+// 这是YdChecker的核心方法，先通过外链接口检测，接口流程失败时改用浏览器检测
 //
 // 参数:
 // - ctx: 上下文，用于控制超时和取消
@@ -55,77 +81,43 @@ func (y *YdChecker) GetPrefix() []string {
 // - Result: 包含检查结果的结构体，包括URL、资源名称、错误码和耗时
 func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 	logger.Debug("YdChecker:开始检测移动云盘(139云盘)链接: %s", urlStr)
-	requestStart := time.Now()
 
-	// 验证URL格式
-	parsedURL, err := url.ParseRequestURI(urlStr)
+	linkID, passCode, err := extractParamsYd(urlStr)
 	if err != nil {
-		logger.Info("YdChecker:ParseRequestURI, %s, 错误: %v\n", urlStr, err)
+		logger.Info("YdChecker:extractParamsYd, %s, 错误: %v\n", urlStr, err)
 		return utils.ErrorMalformed(urlStr, "链接格式无效")
 	}
 
-	// 确保是移动云盘(139云盘)链接
-	if !strings.Contains(parsedURL.Host, "yun.139.com") {
-		logger.Info("YdChecker:不是移动云盘(139云盘)链接: %s\n", urlStr)
-		return utils.ErrorMalformed(urlStr, "链接格式无效")
+	requestStart := time.Now()
+	response, err := ydOutLinkRequest(ctx, linkID, passCode)
+	logger.Debug("YdChecker:接口请求完成，请求耗时: %dms", time.Since(requestStart).Milliseconds())
+	if err == nil {
+		return response.result()
 	}
 
-	// 配置Chrome浏览器选项
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		// 基本配置
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-
-		// 用户代理设置
-		chromedp.UserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/143.0.0.0 Safari/537.36"),
-
-		// 核心性能优化：禁用不必要的资源加载
-		chromedp.Flag("blink-settings", "imagesEnabled=false,cssEnabled=false"),
-		chromedp.Flag("disable-plugins", true),
-		chromedp.Flag("disable-extensions", true),
-		chromedp.Flag("disable-component-extensions-with-background-pages", true),
-		chromedp.Flag("disable-preconnect", true),
-		chromedp.Flag("disable-prefetch", true),
-		chromedp.Flag("disable-predictive-networking", true),
-		chromedp.Flag("disable-ntp-other-sessions-suggestions", true),
-		chromedp.Flag("disable-background-networking", true),
-		chromedp.Flag("disable-background-timer-throttling", true),
-		chromedp.Flag("disable-backgrounding-occluded-windows", true),
-
-		// 禁用媒体自动播放
-		chromedp.Flag("autoplay-policy", "user-gesture-required"),
-		chromedp.Flag("disable-media-autoplay", true),
-
-		// 禁用JavaScript执行超时检查
-		chromedp.Flag("disable-javascript-timeouts", true),
-
-		// 禁用动画和过渡效果
-		chromedp.Flag("reduced-refresh-rate", true),
-		chromedp.Flag("disable-translate", true),
-
-		// 禁用安全策略和自动化检测
-		chromedp.Flag("disable-blink-features", "AutomationControlled"),
-		chromedp.Flag("disable-web-security", true),
-		chromedp.Flag("allow-running-insecure-content", true),
-
-		// 网络限制和缓存控制
-		chromedp.Flag("disk-cache-size", "0"),
-		chromedp.Flag("media-cache-size", "0"),
-
-		// 窗口和渲染设置
-		chromedp.Flag("window-size", "1280,800"),
-		chromedp.Flag("enable-features", "NetworkService,NetworkServiceInProcess"),
-	)
+	// 只有请求失败时才换用浏览器，超时、限流和熔断时换用浏览器也无济于事
+	if result := errorResult(err); ctx.Err() != nil || result.Error != utils.Fatal || !browser.Available() {
+		logger.Info("YdChecker:接口检测失败: %s, 错误: %v", urlStr, err)
+		return result
+	}
+	logger.Info("YdChecker:接口检测失败，改用浏览器检测: %s, 错误: %v", urlStr, err)
+	return y.checkYdBrowser(ctx, urlStr)
+}
 
-	// 创建执行上下文
-	execCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
-	defer cancel()
+// checkYdBrowser 通过浏览器渲染分享页面检测链接，作为接口检测失败时的后备
+func (y *YdChecker) checkYdBrowser(ctx context.Context, urlStr string) utils.Result {
+	requestStart := time.Now()
 
-	// 创建浏览器上下文
-	browserCtx, cancel := chromedp.NewContext(execCtx)
-	defer cancel()
+	// 从共享的浏览器池中打开无痕标签页
+	browserCtx, release, err := browser.NewTab(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return utils.ErrorTimeout()
+		}
+		logger.Info("YdChecker:打开浏览器标签页失败: %s, 错误: %v", urlStr, err)
+		return utils.ErrorFatal("浏览器启动失败")
+	}
+	defer release()
 
 	// 导航到链接并等待页面加载完成
 	var pageContent string
@@ -149,14 +141,15 @@ func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 		// 即使出错，我们仍然尝试处理已获取的页面内容
 		if pageContent == "" {
 			// 尝试重试
-			logger.Debug("YdChecker:第一阶段超时且未获取到页面内容，尝试创建新上下文重新导航...")
-			retryExecCtx, retryExecCancel := chromedp.NewExecAllocator(ctx, opts...)
-			defer retryExecCancel()
-			retryBrowserCtx, retryBrowserCancel := chromedp.NewContext(retryExecCtx)
-			defer retryBrowserCancel()
+			logger.Debug("YdChecker:第一阶段超时且未获取到页面内容，尝试打开新标签页重新导航...")
+			retryBrowserCtx, retryRelease, retryErr := browser.NewTab(ctx)
+			if retryErr != nil {
+				return utils.ErrorFatal("失败: " + err.Error())
+			}
+			defer retryRelease()
 			retryCtx, retryCancel := context.WithTimeout(retryBrowserCtx, config.GetLongTimeout())
 			defer retryCancel()
-			retryErr := chromedp.Run(retryCtx,
+			retryErr = chromedp.Run(retryCtx,
 				chromedp.Navigate(urlStr),
 				chromedp.WaitVisible("body", chromedp.ByQuery),
 				chromedp.Sleep(1*time.Second),
@@ -185,7 +178,7 @@ func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 		if strings.Contains(lowerPageContent, "share has been canceled") ||
 			strings.Contains(pageContent, "分享已取消") {
 			logger.Info("YdChecker:分享已取消: %s, 耗时: %dms", urlStr, time.Since(requestStart).Milliseconds())
-			return utils.ErrorInvalid("分享已取消，请联系分享者重新分享")
+			return utils.ErrorInvalid("分享已取消，请联系分享者重新分享").WithReason(utils.ReasonShareCancelled)
 		}
 
 		// 检测登录页面
@@ -205,14 +198,14 @@ func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 		}
 		if loginDetected {
 			logger.Info("YdChecker:需要登录: %s, 耗时: %dms", urlStr, time.Since(requestStart).Milliseconds())
-			return utils.ErrorInvalid("需要登录才能访问该分享")
+			return utils.ErrorLoginRequired("需要登录才能访问该分享")
 		}
 
 		// 检测密码错误
 		if strings.Contains(lowerPageContent, "密码错误") || strings.Contains(lowerPageContent, "wrong password") ||
 			strings.Contains(lowerPageContent, "提取码错误") {
 			logger.Info("YdChecker:密码错误: %s, 耗时: %dms", urlStr, time.Since(requestStart).Milliseconds())
-			return utils.ErrorInvalid("密码错误")
+			return utils.ErrorNeedPassword("密码错误").WithReason(utils.ReasonWrongPasscode)
 		}
 
 		// 检测链接无效
@@ -227,7 +220,7 @@ func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 		for _, keyword := range invalidKeywords {
 			if strings.Contains(lowerPageContent, keyword) {
 				logger.Info("YdChecker:分享不存在或已过期: %s, 耗时: %dms", urlStr, time.Since(requestStart).Milliseconds())
-				return utils.ErrorInvalid("分享不存在或已过期")
+				return utils.ErrorInvalid("分享不存在或已过期").WithReason(utils.ReasonShareNotFound)
 			}
 		}
 
@@ -236,7 +229,7 @@ func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 			strings.Contains(lowerPageContent, "enter password")
 		if passwordProtected && strings.Contains(urlStr, "2qidGwZUXqwqo") {
 			logger.Info("YdChecker:需要提取码: %s, 耗时: %dms", urlStr, time.Since(requestStart).Milliseconds())
-			return utils.ErrorInvalid("该分享需要提取码")
+			return utils.ErrorNeedPassword("该分享需要提取码")
 		}
 
 		// 检测404错误
@@ -244,13 +237,13 @@ func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 			(strings.Contains(lowerPageContent, "404") && strings.Contains(lowerPageContent, "not found")) ||
 			strings.Contains(lowerPageContent, "找不到页面") {
 			logger.Info("YdChecker:分享不存在或已过期: %s, 耗时: %dms", urlStr, time.Since(requestStart).Milliseconds())
-			return utils.ErrorInvalid("分享不存在或已过期")
+			return utils.ErrorInvalid("分享不存在或已过期").WithReason(utils.ReasonShareNotFound)
 		}
 
 		// 特殊处理：针对特定的无效链接模式
 		if strings.Contains(urlStr, "2qidGwZUXqddw") {
 			logger.Info("YdChecker:分享已取消: %s, 耗时: %dms", urlStr, time.Since(requestStart).Milliseconds())
-			return utils.ErrorInvalid("分享已取消，请联系分享者重新分享")
+			return utils.ErrorInvalid("分享已取消，请联系分享者重新分享").WithReason(utils.ReasonShareCancelled)
 		}
 	}
 
@@ -403,7 +396,7 @@ func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 		if strings.Contains(lowerPageContent, "share has been canceled") ||
 			strings.Contains(pageContent, "分享已取消") {
 			logger.Info("YdChecker:分享已取消: %s, 耗时: %dms", urlStr, requestElapsed)
-			return utils.ErrorInvalid("分享已取消，请联系分享者重新分享")
+			return utils.ErrorInvalid("分享已取消，请联系分享者重新分享").WithReason(utils.ReasonShareCancelled)
 		}
 
 		// 检测登录页面
@@ -423,14 +416,14 @@ func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 		}
 		if loginDetected && folderName == "" {
 			logger.Info("YdChecker:需要登录: %s, 耗时: %dms", urlStr, requestElapsed)
-			return utils.ErrorInvalid("需要登录才能访问该分享")
+			return utils.ErrorLoginRequired("需要登录才能访问该分享")
 		}
 
 		// 检测密码错误
 		if strings.Contains(lowerPageContent, "密码错误") || strings.Contains(lowerPageContent, "wrong password") ||
 			strings.Contains(lowerPageContent, "提取码错误") {
 			logger.Info("YdChecker:密码错误: %s, 耗时: %dms", urlStr, requestElapsed)
-			return utils.ErrorInvalid("密码错误")
+			return utils.ErrorNeedPassword("密码错误").WithReason(utils.ReasonWrongPasscode)
 		}
 
 		// 检测链接无效
@@ -445,7 +438,7 @@ func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 		for _, keyword := range invalidKeywords {
 			if strings.Contains(lowerPageContent, keyword) {
 				logger.Info("YdChecker:分享不存在或已过期: %s, 耗时: %dms", urlStr, requestElapsed)
-				return utils.ErrorInvalid("分享不存在或已过期")
+				return utils.ErrorInvalid("分享不存在或已过期").WithReason(utils.ReasonShareNotFound)
 			}
 		}
 
@@ -454,7 +447,7 @@ func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 			strings.Contains(lowerPageContent, "enter password")
 		if passwordProtected && strings.Contains(urlStr, "2qidGwZUXqwqo") {
 			logger.Info("YdChecker:需要提取码: %s, 耗时: %dms", urlStr, requestElapsed)
-			return utils.ErrorInvalid("该分享需要提取码")
+			return utils.ErrorNeedPassword("该分享需要提取码")
 		}
 
 		// 检测404错误
@@ -462,13 +455,13 @@ func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 			(strings.Contains(lowerPageContent, "404") && strings.Contains(lowerPageContent, "not found")) ||
 			strings.Contains(lowerPageContent, "找不到页面") {
 			logger.Info("YdChecker:分享不存在或已过期: %s, 耗时: %dms", urlStr, requestElapsed)
-			return utils.ErrorInvalid("分享不存在或已过期")
+			return utils.ErrorInvalid("分享不存在或已过期").WithReason(utils.ReasonShareNotFound)
 		}
 
 		// 特殊处理：针对特定的无效链接模式
 		if strings.Contains(urlStr, "2qidGwZUXqddw") {
 			logger.Info("YdChecker:分享已取消: %s, 耗时: %dms", urlStr, requestElapsed)
-			return utils.ErrorInvalid("分享已取消，请联系分享者重新分享")
+			return utils.ErrorInvalid("分享已取消，请联系分享者重新分享").WithReason(utils.ReasonShareCancelled)
 		}
 	}
 
@@ -529,5 +522,174 @@ func (y *YdChecker) checkYd(ctx context.Context, urlStr string) utils.Result {
 	}
 
 	logger.Info("YdChecker:无法获取文件夹名称: %s, 耗时: %dms", urlStr, requestElapsed)
-	return utils.ErrorInvalid("无法获取分享信息")
+	return utils.ErrorInvalid("无法获取分享信息").WithReason(utils.ReasonPageError)
+}
+
+// ydOutLinkURL 外链信息接口地址
+var ydOutLinkURL = "https://share-kd-njs.yun.139.com/yun-share/richlifeApp/devapp/IOutLink/getOutLinkInfoV6"
+
+// ydOutLinkResp 外链信息接口响应结构
+type ydOutLinkResp struct {
+	Code string `json:"code"`
+	Desc string `json:"desc"`
+	Data struct {
+		LkName string    `json:"lkName"`
+		NodNum flexInt64 `json:"nodNum"`
+		// 分享中的文件夹
+		CaLst []struct {
+			CaName string `json:"caName"`
+		} `json:"caLst"`
+		// 分享中的文件
+		CoLst []struct {
+			CoName string    `json:"coName"`
+			CoSize flexInt64 `json:"coSize"`
+		} `json:"coLst"`
+	} `json:"data"`
+}
+
+// 外链信息接口的业务错误码
+const (
+	ydCodeCancelled     = "9149"       // 分享已取消
+	ydCodeExpired       = "9150"       // 分享已过期
+	ydCodeBanned        = "9154"       // 分享涉嫌违规被屏蔽
+	ydCodeNeedPasscode  = "9188"       // 需要提取码
+	ydCodeWrongPasscode = "9189"       // 提取码错误
+	ydCodeNotFound      = "200000727"  // 外链不存在
+	ydCodeLogin         = "1809010032" // 需要登录才能访问
+)
+
+// result 根据接口响应创建检测结果，失败时按错误码区分取消、过期、需要提取码和需要登录，
+// 未知的错误码再根据提示信息判断
+func (r *ydOutLinkResp) result() utils.Result {
+	if r.Code != "0" {
+		logger.Debug("YdChecker:接口返回业务错误: code=%s, desc=%s", r.Code, r.Desc)
+		switch r.Code {
+		case ydCodeCancelled:
+			return utils.ErrorInvalid("分享已取消").WithReason(utils.ReasonShareCancelled)
+		case ydCodeExpired:
+			return utils.ErrorInvalid("分享已过期").WithReason(utils.ReasonShareExpired)
+		case ydCodeNotFound:
+			return utils.ErrorInvalid("分享不存在").WithReason(utils.ReasonShareNotFound)
+		case ydCodeBanned:
+			return utils.ErrorBanned("分享涉嫌违规，已被屏蔽")
+		case ydCodeNeedPasscode:
+			return utils.ErrorNeedPassword("需要提取码")
+		case ydCodeWrongPasscode:
+			return utils.ErrorNeedPassword("提取码错误").WithReason(utils.ReasonWrongPasscode)
+		case ydCodeLogin:
+			return utils.ErrorLoginRequired("需要登录才能访问")
+		}
+		return messageResult(r.Desc, "分享不存在或已过期")
+	}
+
+	meta := r.meta()
+	name := r.Data.LkName
+	if name == "" && len(meta.Entries) > 0 {
+		name = meta.Entries[0].Name
+	}
+	if meta.FileCount == 0 {
+		return utils.ErrorInvalid("暂无文件").WithReason(utils.ReasonEmptyShare)
+	}
+	return utils.ErrorValid(name).WithMeta(meta)
+}
+
+// meta 生成分享内容元数据
+func (r *ydOutLinkResp) meta() *utils.Metadata {
+	meta := &utils.Metadata{FileCount: len(r.Data.CaLst) + len(r.Data.CoLst)}
+	for _, ca := range r.Data.CaLst {
+		meta.AddEntry(ca.CaName, 0, true)
+	}
+	for _, co := range r.Data.CoLst {
+		meta.TotalSize += int64(co.CoSize)
+		meta.AddEntry(co.CoName, int64(co.CoSize), false)
+	}
+	if int(r.Data.NodNum) > meta.FileCount {
+		meta.FileCount = int(r.Data.NodNum)
+	}
+	return meta
+}
+
+// ydOutLinkRequest 获取移动云盘外链的分享信息和根目录内容
+func ydOutLinkRequest(ctx context.Context, linkID, passCode string) (*ydOutLinkResp, error) {
+	requestBody := map[string]interface{}{
+		"getOutLinkInfoReq": map[string]interface{}{
+			"account": "",
+			"linkID":  linkID,
+			"passwd":  passCode,
+			"caSrt":   1,
+			"coSrt":   1,
+			"srtDr":   0,
+			"bNum":    1,
+			"pCaID":   "root",
+			"eNum":    200,
+		},
+		"commonAccountInfo": map[string]interface{}{
+			"account":     "",
+			"accountType": 1,
+		},
+	}
+	jsonBody, _ := json.Marshal(requestBody)
+
+	req, err := apphttp.NewRequestWithContext(ctx, "POST", ydOutLinkURL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("content-type", "application/json;charset=UTF-8")
+	req.Header.Set("origin", "https://yun.139.com")
+	req.Header.Set("referer", "https://yun.139.com/")
+	req.Header.Set("hcy-cool-flag", "1")
+
+	resp, err := apphttp.DoWithRetry(ctx, req, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.NewStatusCodeError(fmt.Sprintf("HTTP状态码: %d", resp.StatusCode))
+	}
+
+	var response ydOutLinkResp
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, errors.NewParseError("解析JSON失败", err)
+	}
+	if response.Code == "" {
+		return nil, errors.NewParseError("接口响应缺少状态码", nil)
+	}
+
+	return &response, nil
+}
+
+// extractParamsYd 从移动云盘链接中提取外链ID和提取码
+func extractParamsYd(rawURL string) (linkID, passCode string, err error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	if parsedURL.Host != "yun.139.com" {
+		return "", "", fmt.Errorf("不是移动云盘分享链接")
+	}
+
+	// 分享ID位于链接片段中，片段中可能带有查询参数，如 #/w/i/2rJV89vKpVPsr?pwd=ab12
+	fragment, fragmentQuery, _ := strings.Cut(parsedURL.Fragment, "?")
+	if !strings.Contains(fragment, "/i/") {
+		return "", "", fmt.Errorf("无法寻找外链ID")
+	}
+	linkID = path.Base(strings.TrimRight(fragment, "/"))
+	if linkID == "" || linkID == "i" {
+		return "", "", fmt.Errorf("无法寻找外链ID")
+	}
+
+	passCode = parser.PasswordFromURL(rawURL)
+	if passCode == "" && fragmentQuery != "" {
+		passCode = parser.PasswordFromURL("https://yun.139.com/?" + fragmentQuery)
+	}
+	return linkID, passCode, nil
 }
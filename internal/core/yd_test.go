@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"share-sniffer/internal/utils"
+)
+
+// 外链信息接口的响应样例
+var ydOutLinkResponses = map[string]string{
+	"2rJVok": `{"code":"0","desc":"","data":{"lkName":"学习资料","nodNum":"3",
+		"caLst":[{"caName":"课程视频"}],
+		"coLst":[{"coName":"目录.pdf","coSize":"2048"},{"coName":"说明.txt","coSize":512}]}}`,
+	"2rJVempty":  `{"code":"0","desc":"","data":{"lkName":"","caLst":[],"coLst":[]}}`,
+	"2rJVcancel": `{"code":"9149","desc":"分享已取消，请联系分享者重新分享"}`,
+	"2rJVgone":   `{"code":"200000727","desc":"外链不存在"}`,
+	"2rJVexpire": `{"code":"9150","desc":"分享已过期"}`,
+	"2rJVlock":   `{"code":"9188","desc":"请输入提取码"}`,
+	"2rJVwrong":  `{"code":"9189","desc":"提取码错误"}`,
+	"2rJVlogin":  `{"code":"1809010032","desc":"该分享必须登录才能访问"}`,
+	"2rJVbanned": `{"code":"9154","desc":"该分享涉嫌违规，已被屏蔽"}`,
+	// 提示信息无法识别时按错误码判断，未知的错误码再根据提示信息判断
+	"2rJVcancel0": `{"code":"9149","desc":"操作失败"}`,
+	"2rJVwrong0":  `{"code":"9189","desc":"参数校验失败"}`,
+	"2rJVlogin0":  `{"code":"1809010032","desc":""}`,
+	"2rJVother":   `{"code":"9999","desc":"链接已失效"}`,
+}
+
+func TestYdChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Req struct {
+				LinkID string `json:"linkID"`
+				Passwd string `json:"passwd"`
+			} `json:"getOutLinkInfoReq"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		linkID := body.Req.LinkID
+		if linkID == "2rJVlock" && body.Req.Passwd == "ab12" {
+			linkID = "2rJVok"
+		}
+		resp, ok := ydOutLinkResponses[linkID]
+		if !ok {
+			w.Write([]byte(`<html>error</html>`))
+			return
+		}
+		w.Write([]byte(resp))
+	}))
+	defer server.Close()
+
+	apiURL := ydOutLinkURL
+	ydOutLinkURL = server.URL
+	defer func() { ydOutLinkURL = apiURL }()
+
+	checker := &YdChecker{}
+	tests := []struct {
+		url        string
+		wantError  utils.ErrorType
+		wantReason string
+	}{
+		{"https://yun.139.com/shareweb/#/w/i/2rJVok", utils.Valid, utils.ReasonOK},
+		{"https://yun.139.com/shareweb/#/w/i/2rJVempty", utils.Invalid, utils.ReasonEmptyShare},
+		{"https://yun.139.com/shareweb/#/w/i/2rJVcancel", utils.Invalid, utils.ReasonShareCancelled},
+		{"https://yun.139.com/shareweb/#/w/i/2rJVgone", utils.Invalid, utils.ReasonShareNotFound},
+		{"https://yun.139.com/shareweb/#/w/i/2rJVexpire", utils.Invalid, utils.ReasonShareExpired},
+		{"https://yun.139.com/shareweb/#/w/i/2rJVlock", utils.NeedPassword, utils.ReasonPasscodeRequired},
+		{"https://yun.139.com/shareweb/?pwd=ab12#/w/i/2rJVlock", utils.Valid, utils.ReasonOK},
+		{"https://yun.139.com/shareweb/#/w/i/2rJVwrong", utils.NeedPassword, utils.ReasonWrongPasscode},
+		{"https://yun.139.com/shareweb/#/w/i/2rJVlogin", utils.LoginRequired, utils.ReasonLoginRequired},
+		{"https://yun.139.com/shareweb/#/w/i/2rJVbanned", utils.Banned, utils.ReasonBanned},
+		{"https://yun.139.com/shareweb/#/w/i/2rJVcancel0", utils.Invalid, utils.ReasonShareCancelled},
+		{"https://yun.139.com/shareweb/#/w/i/2rJVwrong0", utils.NeedPassword, utils.ReasonWrongPasscode},
+		{"https://yun.139.com/shareweb/#/w/i/2rJVlogin0", utils.LoginRequired, utils.ReasonLoginRequired},
+		{"https://yun.139.com/shareweb/#/w/i/2rJVother", utils.Invalid, utils.ReasonShareExpired},
+		{"https://yun.139.com/shareweb/", utils.Malformed, utils.ReasonMalformedURL},
+	}
+	for _, tt := range tests {
+		got := checker.Check(context.Background(), tt.url)
+		if got.Error != tt.wantError || got.Reason != tt.wantReason {
+			t.Errorf("Check(%q) = %d %s %q, want %d %s", tt.url, got.Error, got.Reason, got.Msg, tt.wantError, tt.wantReason)
+		}
+	}
+
+	got := checker.Check(context.Background(), "https://yun.139.com/shareweb/#/w/i/2rJVok")
+	if got.Data.Name != "学习资料" || got.Data.Meta == nil {
+		t.Fatalf("Check(2rJVok) = %+v", got)
+	}
+	if meta := got.Data.Meta; meta.FileCount != 3 || meta.TotalSize != 2560 || len(meta.Entries) != 3 || !meta.Entries[0].IsDir {
+		t.Errorf("Check(2rJVok) meta = %+v", meta)
+	}
+
+	// 接口响应无法解析时返回错误，由调用方决定是否改用浏览器
+	if _, err := ydOutLinkRequest(context.Background(), "2rJVunknown", ""); err == nil {
+		t.Errorf("ydOutLinkRequest(2rJVunknown) 预期解析错误")
+	}
+}
+
+func TestExtractParamsYd(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantID   string
+		wantCode string
+		wantErr  bool
+	}{
+		{"https://yun.139.com/shareweb/#/w/i/2rJV89vKpVPsr", "2rJV89vKpVPsr", "", false},
+		{"https://yun.139.com/shareweb/#/w/i/2rJV89vKpVPsr/", "2rJV89vKpVPsr", "", false},
+		{"https://yun.139.com/shareweb/#/w/i/2rJV89vKpVPsr?pwd=ab12", "2rJV89vKpVPsr", "ab12", false},
+		{"https://yun.139.com/shareweb/?pwd=ab12#/w/i/2rJV89vKpVPsr", "2rJV89vKpVPsr", "ab12", false},
+		{"https://yun.139.com/shareweb/", "", "", true},
+		{"https://caiyun.139.com/w/i/2rJV89vKpVPsr", "", "", true},
+	}
+	for _, tt := range tests {
+		id, code, err := extractParamsYd(tt.url)
+		if (err != nil) != tt.wantErr || id != tt.wantID || code != tt.wantCode {
+			t.Errorf("extractParamsYd(%q) = %q, %q, %v, want %q, %q", tt.url, id, code, err, tt.wantID, tt.wantCode)
+		}
+	}
+}
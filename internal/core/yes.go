@@ -31,6 +31,12 @@ func (y *YesChecker) GetPrefix() []string {
 	return config.GetSupportedYes()
 }
 
+// ShareID 实现ShareIdentifier接口
+func (y *YesChecker) ShareID(urlStr string) string {
+	resourceID, _, _ := extractParamsYes(urlStr)
+	return resourceID
+}
+
 func (y *YesChecker) checkYes(ctx context.Context, urlStr string) utils.Result {
 	logger.Debug("YesChecker:开始检测123网盘链接: %s", urlStr)
 
@@ -41,14 +47,11 @@ func (y *YesChecker) checkYes(ctx context.Context, urlStr string) utils.Result {
 
 	response, err := yesRequest(ctx, urlStr, resourceID, passCode)
 	if err != nil {
-		if errors.IsTimeoutError(err) {
-			return utils.ErrorTimeout()
-		}
-		return utils.ErrorFatal("检测失败")
+		return errorResult(err)
 	}
 
 	if response.Info.Code != 0 {
-		return utils.ErrorInvalid("分享链接失效")
+		return messageResult(response.Info.Message, "分享链接失效")
 	}
 
 	return utils.ErrorValid(response.Info.Data.ShareName)
@@ -103,6 +106,109 @@ func yesRequest(ctx context.Context, originalURL string, resourceID string, pass
 	return &response, nil
 }
 
+// yesListPageSize 列举目录时每页的条目数
+const yesListPageSize = 100
+
+// yesFileTypeDir 文件列表中文件夹的类型值
+const yesFileTypeDir = 1
+
+// yesListResp 123网盘分享文件列表接口响应结构
+type yesListResp struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Next     string `json:"Next"`
+		InfoList []struct {
+			FileID   flexInt64 `json:"FileId"`
+			FileName string    `json:"FileName"`
+			Type     int       `json:"Type"`
+			Size     flexInt64 `json:"Size"`
+		} `json:"InfoList"`
+	} `json:"data"`
+}
+
+// List 实现Lister接口，通过分享文件列表接口的ParentFileId参数逐层分页列举
+func (y *YesChecker) List(ctx context.Context, urlStr string, opts ListOptions) ([]*ShareNode, bool, error) {
+	resourceID, passCode, err := extractParamsYes(urlStr)
+	if err != nil {
+		return nil, false, resultError(utils.ErrorMalformed(urlStr, "链接格式无效"))
+	}
+
+	cookie, err := getCookieFromOriginalURL(ctx, urlStr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return walkTree(ctx, func(ctx context.Context, dirID string, page int) ([]listEntry, bool, error) {
+		if dirID == "" {
+			dirID = "0"
+		}
+		response, err := yesListRequest(ctx, urlStr, cookie, resourceID, passCode, dirID, page)
+		if err != nil {
+			return nil, false, err
+		}
+		if response.Code != 0 {
+			return nil, false, resultError(messageResult(response.Message, "分享链接失效"))
+		}
+
+		entries := make([]listEntry, 0, len(response.Data.InfoList))
+		for _, f := range response.Data.InfoList {
+			entries = append(entries, listEntry{
+				id:    fmt.Sprintf("%d", int64(f.FileID)),
+				name:  f.FileName,
+				size:  int64(f.Size),
+				isDir: f.Type == yesFileTypeDir,
+			})
+		}
+		// Next为-1表示没有下一页
+		return entries, response.Data.Next != "" && response.Data.Next != "-1", nil
+	}, opts)
+}
+
+// yesListRequest 按页获取123网盘分享中指定目录的文件列表
+func yesListRequest(ctx context.Context, originalURL, cookie, resourceID, passCode, dirID string, page int) (*yesListResp, error) {
+	params := url.Values{}
+	params.Set("limit", fmt.Sprintf("%d", yesListPageSize))
+	params.Set("next", "0")
+	params.Set("orderBy", "file_name")
+	params.Set("orderDirection", "asc")
+	params.Set("shareKey", resourceID)
+	params.Set("SharePwd", passCode)
+	params.Set("ParentFileId", dirID)
+	params.Set("Page", fmt.Sprintf("%d", page))
+	apiURL := "https://www.123684.com/b/api/share/get?" + params.Encode()
+
+	req, err := apphttp.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Referer", originalURL)
+	req.Header.Set("Cookie", cookie)
+
+	resp, err := apphttp.DoWithRetry(ctx, req, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer apphttp.CloseResponse(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP状态码: %d", resp.StatusCode)
+	}
+
+	var response yesListResp
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, errors.NewParseError("解析JSON失败", err)
+	}
+
+	return &response, nil
+}
+
 func getCookieFromOriginalURL(ctx context.Context, originalURL string) (string, error) {
 	req, err := apphttp.NewRequestWithContext(ctx, "GET", originalURL, nil)
 	if err != nil {
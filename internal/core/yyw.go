@@ -30,6 +30,12 @@ func (q *YywChecker) GetPrefix() []string {
 	return config.GetSupportedYyw()
 }
 
+// ShareID 实现ShareIdentifier接口
+func (q *YywChecker) ShareID(urlStr string) string {
+	shareCode, _, _ := extractParamsYyw(urlStr)
+	return shareCode
+}
+
 func (q *YywChecker) checkYyw(ctx context.Context, urlStr string) utils.Result {
 	logger.Debug("YywChecker:开始检测115网盘链接: %s", urlStr)
 
@@ -38,16 +44,17 @@ func (q *YywChecker) checkYyw(ctx context.Context, urlStr string) utils.Result {
 		return utils.ErrorMalformed(urlStr, "链接格式无效")
 	}
 
-	response, err := yywRequest(ctx, shareCode, receiveCode)
+	response, err := yywRequest(ctx, shareCode, receiveCode, "", 0, yywSnapLimit)
 	if err != nil {
-		if errors.IsTimeoutError(err) {
-			return utils.ErrorTimeout()
-		}
-		return utils.ErrorFatal("检测失败")
+		return errorResult(err)
 	}
 
 	if !(response.State && response.Errno == 0) {
-		return utils.ErrorInvalid("分享链接失效")
+		// 缺少或填错访问码时115返回对应的提示信息
+		if receiveCode == "" && response.Errno == yywErrnoNeedReceiveCode {
+			return utils.ErrorNeedPassword("需要访问码")
+		}
+		return messageResult(response.Error, "分享链接失效")
 	}
 
 	name := response.Data.Shareinfo.ShareTitle
@@ -55,7 +62,7 @@ func (q *YywChecker) checkYyw(ctx context.Context, urlStr string) utils.Result {
 		name = response.Data.List[0].N
 	}
 
-	return utils.ErrorValid(unicodeToChinese(name))
+	return utils.ErrorValid(unicodeToChinese(name)).WithMeta(response.meta())
 }
 
 func extractParamsYyw(urlStr string) (shareCode, receiveCode string, err error) {
@@ -77,21 +84,87 @@ func extractParamsYyw(urlStr string) (shareCode, receiveCode string, err error)
 	return
 }
 
+// yywErrnoNeedReceiveCode 115分享需要访问码时返回的错误码
+const yywErrnoNeedReceiveCode = 4100012
+
+// yywSnapLimit 获取分享文件列表的条目数
+const yywSnapLimit = utils.MetaMaxEntries
+
 type yywResp struct {
-	State bool `json:"state"`
-	Errno int  `json:"errno"`
+	State bool   `json:"state"`
+	Errno int    `json:"errno"`
+	Error string `json:"error"`
 	Data  struct {
+		Count     int `json:"count"`
 		Shareinfo struct {
-			ShareTitle string `json:"share_title"`
+			ShareTitle string    `json:"share_title"`
+			FileSize   flexInt64 `json:"file_size"`
+			ExpireTime flexInt64 `json:"expire_time"`
 		} `json:"shareinfo"`
+		Userinfo struct {
+			UserName string `json:"user_name"`
+		} `json:"userinfo"`
 		List []struct {
-			N string `json:"n"`
+			N   string    `json:"n"`
+			S   flexInt64 `json:"s"`
+			Fid string    `json:"fid"`
+			Cid string    `json:"cid"`
 		} `json:"list"`
 	} `json:"data"`
 }
 
-func yywRequest(ctx context.Context, shareCode, receiveCode string) (*yywResp, error) {
-	apiURL := fmt.Sprintf("https://115cdn.com/webapi/share/snap?share_code=%s&receive_code=%s&offset=0&limit=1", shareCode, receiveCode)
+// meta 根据分享信息和文件列表生成分享内容元数据，文件夹没有fid
+func (r *yywResp) meta() *utils.Metadata {
+	meta := &utils.Metadata{
+		FileCount: r.Data.Count,
+		TotalSize: int64(r.Data.Shareinfo.FileSize),
+		ExpiresAt: expiryMillis(int64(r.Data.Shareinfo.ExpireTime)),
+		Creator:   r.Data.Userinfo.UserName,
+	}
+	for _, f := range r.Data.List {
+		meta.AddEntry(unicodeToChinese(f.N), int64(f.S), f.Fid == "")
+	}
+	return meta
+}
+
+// yywListPageSize 列举目录时每页的条目数
+const yywListPageSize = 100
+
+// List 实现Lister接口，通过snap接口的cid和offset/limit参数逐层分页列举
+func (q *YywChecker) List(ctx context.Context, urlStr string, opts ListOptions) ([]*ShareNode, bool, error) {
+	shareCode, receiveCode, err := extractParamsYyw(urlStr)
+	if err != nil || shareCode == "" {
+		return nil, false, resultError(utils.ErrorMalformed(urlStr, "链接格式无效"))
+	}
+
+	return walkTree(ctx, func(ctx context.Context, cid string, page int) ([]listEntry, bool, error) {
+		offset := (page - 1) * yywListPageSize
+		response, err := yywRequest(ctx, shareCode, receiveCode, cid, offset, yywListPageSize)
+		if err != nil {
+			return nil, false, err
+		}
+		if !(response.State && response.Errno == 0) {
+			if receiveCode == "" && response.Errno == yywErrnoNeedReceiveCode {
+				return nil, false, resultError(utils.ErrorNeedPassword("需要访问码"))
+			}
+			return nil, false, resultError(messageResult(response.Error, "分享链接失效"))
+		}
+
+		entries := make([]listEntry, 0, len(response.Data.List))
+		for _, f := range response.Data.List {
+			// 文件夹没有fid，使用cid请求子目录
+			entries = append(entries, listEntry{id: f.Cid, name: unicodeToChinese(f.N), size: int64(f.S), isDir: f.Fid == ""})
+		}
+		return entries, offset+len(entries) < response.Data.Count, nil
+	}, opts)
+}
+
+// yywRequest 获取115分享信息和文件列表，cid为空时获取顶层目录
+func yywRequest(ctx context.Context, shareCode, receiveCode, cid string, offset, limit int) (*yywResp, error) {
+	apiURL := fmt.Sprintf("https://115cdn.com/webapi/share/snap?share_code=%s&receive_code=%s&offset=%d&limit=%d", shareCode, receiveCode, offset, limit)
+	if cid != "" {
+		apiURL += "&cid=" + url.QueryEscape(cid)
+	}
 
 	req, err := apphttp.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
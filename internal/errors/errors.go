@@ -1,21 +1,24 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"strings"
 )
 
 // 错误类型常量
 const (
-	ErrTypeRequest    = "REQUEST_ERROR"    // 请求错误
-	ErrTypeResponse   = "RESPONSE_ERROR"   // 响应错误
-	ErrTypeParse      = "PARSE_ERROR"      // 解析错误
-	ErrTypeValidation = "VALIDATION_ERROR" // 验证错误
-	ErrTypeTimeout    = "TIMEOUT_ERROR"    // 超时错误
-	ErrTypeInternal   = "INTERNAL_ERROR"   // 内部错误
-	ErrTypeNetwork    = "NETWORK_ERROR"    // 网络错误
-	ErrTypeAPI        = "API_ERROR"
-	ErrTypeStatusCode = "STATUS_CODE_ERROR" // 状态码错误
+	ErrTypeRequest     = "REQUEST_ERROR"    // 请求错误
+	ErrTypeResponse    = "RESPONSE_ERROR"   // 响应错误
+	ErrTypeParse       = "PARSE_ERROR"      // 解析错误
+	ErrTypeValidation  = "VALIDATION_ERROR" // 验证错误
+	ErrTypeTimeout     = "TIMEOUT_ERROR"    // 超时错误
+	ErrTypeInternal    = "INTERNAL_ERROR"   // 内部错误
+	ErrTypeNetwork     = "NETWORK_ERROR"    // 网络错误
+	ErrTypeAPI         = "API_ERROR"
+	ErrTypeStatusCode  = "STATUS_CODE_ERROR" // 状态码错误
+	ErrTypeRateLimit   = "RATE_LIMIT_ERROR"  // 请求频率受限
+	ErrTypeUnavailable = "UNAVAILABLE_ERROR" // 上游熔断，暂不可用
 )
 
 // AppError 自定义应用错误类型
@@ -144,6 +147,24 @@ func NewStatusCodeError(message string) *AppError {
 	}
 }
 
+// NewRateLimitError 创建请求频率受限错误
+func NewRateLimitError(message string, statusCode int) *AppError {
+	return &AppError{
+		Type:       ErrTypeRateLimit,
+		Message:    message,
+		Err:        nil,
+		StatusCode: statusCode,
+	}
+}
+
+// NewUnavailableError 创建上游暂不可用错误（熔断器打开）
+func NewUnavailableError(message string) *AppError {
+	return &AppError{
+		Type:    ErrTypeUnavailable,
+		Message: message,
+	}
+}
+
 // IsTimeoutError 检查是否为超时错误
 func IsTimeoutError(err error) bool {
 	if appErr, ok := err.(*AppError); ok {
@@ -167,3 +188,43 @@ func IsStatusCodeError(err error) bool {
 	}
 	return false
 }
+
+// IsRateLimitError 检查是否为请求频率受限错误
+func IsRateLimitError(err error) bool {
+	if appErr, ok := err.(*AppError); ok {
+		return appErr.Type == ErrTypeRateLimit
+	}
+	return false
+}
+
+// IsUnavailableError 检查是否为上游暂不可用错误
+func IsUnavailableError(err error) bool {
+	if appErr, ok := err.(*AppError); ok {
+		return appErr.Type == ErrTypeUnavailable
+	}
+	return false
+}
+
+// IsParseError 检查是否为解析错误
+func IsParseError(err error) bool {
+	var appErr *AppError
+	if stderrors.As(err, &appErr) {
+		return appErr.Type == ErrTypeParse
+	}
+	return false
+}
+
+// IsServerError 检查错误链中是否包含上游5xx响应
+func IsServerError(err error) bool {
+	for err != nil {
+		var appErr *AppError
+		if !stderrors.As(err, &appErr) {
+			return false
+		}
+		if appErr.StatusCode >= 500 && appErr.StatusCode < 600 {
+			return true
+		}
+		err = appErr.Err
+	}
+	return false
+}
@@ -0,0 +1,272 @@
+package http
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/logger"
+)
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	// BreakerClosed 关闭状态，请求正常通过并统计失败比例
+	BreakerClosed BreakerState = iota
+	// BreakerOpen 打开状态，请求直接失败，等待 OpenDuration 后进入半开状态
+	BreakerOpen
+	// BreakerHalfOpen 半开状态，只允许少量试探请求
+	BreakerHalfOpen
+)
+
+// String 返回状态名称
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// BreakerStat 熔断器统计
+type BreakerStat struct {
+	Name     string       `json:"name"`     // 网盘标识或主域名
+	State    BreakerState `json:"-"`        // 当前状态
+	StateTxt string       `json:"state"`    // 当前状态名称
+	Requests int64        `json:"requests"` // 放行的请求次数
+	Failures int64        `json:"failures"` // 失败次数
+	Rejected int64        `json:"rejected"` // 熔断期间被拒绝的请求次数
+	Trips    int64        `json:"trips"`    // 熔断次数
+}
+
+// Breaker 熔断器
+// 统计窗口内请求数达到 MinRequests 且失败比例达到 FailureRatio 时打开，
+// 打开 OpenDuration 后进入半开状态，试探请求全部成功后关闭，任一失败则重新打开
+type Breaker struct {
+	mu       sync.Mutex
+	name     string
+	cfg      config.CircuitBreaker
+	state    BreakerState
+	since    time.Time // 当前状态或统计窗口的开始时间
+	requests int       // 窗口内请求数
+	failures int       // 窗口内失败数
+	probes   int       // 半开状态已放行的试探请求数
+	passed   int       // 半开状态已成功的试探请求数
+	stat     BreakerStat
+
+	// OnStateChange 状态变化时调用，在持有锁时调用，不能再访问熔断器
+	OnStateChange func(name string, from, to BreakerState)
+}
+
+// NewBreaker 创建关闭状态的熔断器
+func NewBreaker(name string, cfg config.CircuitBreaker) *Breaker {
+	if cfg.MinRequests < 1 {
+		cfg.MinRequests = 1
+	}
+	if cfg.HalfOpenRequests < 1 {
+		cfg.HalfOpenRequests = 1
+	}
+	return &Breaker{
+		name:  name,
+		cfg:   cfg,
+		since: time.Now(),
+		stat:  BreakerStat{Name: name},
+	}
+}
+
+// Allow 判断是否放行请求，放行后必须调用 Record 记录结果
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.since) < b.cfg.OpenDuration {
+			b.stat.Rejected++
+			return false
+		}
+		b.setState(BreakerHalfOpen, now)
+	case BreakerClosed:
+		if b.cfg.Window > 0 && now.Sub(b.since) >= b.cfg.Window {
+			b.since, b.requests, b.failures = now, 0, 0
+		}
+	}
+
+	if b.state == BreakerHalfOpen {
+		if b.probes >= b.cfg.HalfOpenRequests {
+			b.stat.Rejected++
+			return false
+		}
+		b.probes++
+	}
+	b.stat.Requests++
+	return true
+}
+
+// Record 记录放行请求的结果
+func (b *Breaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if !success {
+		b.stat.Failures++
+	}
+
+	switch b.state {
+	case BreakerHalfOpen:
+		if !success {
+			b.setState(BreakerOpen, now)
+			return
+		}
+		b.passed++
+		if b.passed >= b.cfg.HalfOpenRequests {
+			b.setState(BreakerClosed, now)
+		}
+	case BreakerClosed:
+		b.requests++
+		if !success {
+			b.failures++
+		}
+		if b.requests >= b.cfg.MinRequests && float64(b.failures) >= b.cfg.FailureRatio*float64(b.requests) {
+			b.setState(BreakerOpen, now)
+		}
+	}
+	// 打开状态下到达的结果来自熔断前放行的请求，忽略
+}
+
+// skip 放弃已放行但未完成的请求，不计入统计，半开状态下归还试探名额
+func (b *Breaker) skip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stat.Requests--
+	if b.state == BreakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// Open 判断熔断器是否处于打开状态且尚未到达半开时间，不影响统计
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state == BreakerOpen && time.Since(b.since) < b.cfg.OpenDuration
+}
+
+// State 返回当前状态
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Stat 返回熔断器统计
+func (b *Breaker) Stat() BreakerStat {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stat := b.stat
+	stat.State = b.state
+	stat.StateTxt = b.state.String()
+	return stat
+}
+
+// ResetStat 清空统计，状态保持不变
+func (b *Breaker) ResetStat() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stat = BreakerStat{Name: b.name}
+}
+
+// setState 切换状态并清零计数
+func (b *Breaker) setState(to BreakerState, now time.Time) {
+	from := b.state
+	b.state = to
+	b.since = now
+	b.requests, b.failures, b.probes, b.passed = 0, 0, 0, 0
+	if to == BreakerOpen {
+		b.stat.Trips++
+	}
+
+	logger.Warn("熔断器状态变化 %s: %s -> %s", b.name, from, to)
+	if b.OnStateChange != nil {
+		b.OnStateChange(b.name, from, to)
+	}
+}
+
+var (
+	// breakers 按网盘划分的熔断器
+	breakers   = make(map[string]*Breaker)
+	breakersMu sync.Mutex
+)
+
+// providerKey 上下文中网盘标识的键
+type providerKey struct{}
+
+// WithProvider 返回携带网盘标识的上下文，请求按该标识使用熔断器
+// 未携带网盘标识的请求按主域名使用熔断器
+func WithProvider(ctx context.Context, provider string) context.Context {
+	return context.WithValue(ctx, providerKey{}, provider)
+}
+
+// breakerName 获取请求使用的熔断器名称
+func breakerName(ctx context.Context, host string) string {
+	if provider, ok := ctx.Value(providerKey{}).(string); ok && provider != "" {
+		return provider
+	}
+	return baseDomain(host)
+}
+
+// getBreaker 获取名称对应的熔断器，不存在时按配置创建
+func getBreaker(name string) *Breaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	b, ok := breakers[name]
+	if !ok {
+		b = NewBreaker(name, config.GetCircuitBreaker())
+		breakers[name] = b
+	}
+	return b
+}
+
+// ProviderUnavailable 判断网盘的熔断器是否处于打开状态，熔断关闭时始终返回false
+func ProviderUnavailable(provider string) bool {
+	if !config.CircuitBreakerEnabled() {
+		return false
+	}
+	breakersMu.Lock()
+	b, ok := breakers[provider]
+	breakersMu.Unlock()
+	return ok && b.Open()
+}
+
+// BreakerStats 获取各熔断器的统计，按名称排序
+func BreakerStats() []BreakerStat {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	stats := make([]BreakerStat, 0, len(breakers))
+	for _, b := range breakers {
+		stats = append(stats, b.Stat())
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// ResetBreakerStats 清空熔断器统计，熔断器状态保持不变
+func ResetBreakerStats() {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	for _, b := range breakers {
+		b.ResetStat()
+	}
+}
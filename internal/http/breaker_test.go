@@ -0,0 +1,81 @@
+package http
+
+import (
+	"testing"
+	"time"
+
+	"share-sniffer/internal/config"
+)
+
+func TestBreakerTransitions(t *testing.T) {
+	b := NewBreaker("yes", config.CircuitBreaker{
+		Window:           time.Minute,
+		MinRequests:      4,
+		FailureRatio:     0.5,
+		OpenDuration:     50 * time.Millisecond,
+		HalfOpenRequests: 1,
+	})
+	var transitions []string
+	b.OnStateChange = func(name string, from, to BreakerState) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	}
+
+	// 请求数不足时不熔断
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Fatalf("Allow() #%d = false, want true", i)
+		}
+		b.Record(false)
+	}
+	if b.State() != BreakerClosed {
+		t.Fatalf("state = %s, want closed", b.State())
+	}
+
+	// 达到最小请求数且失败比例超过阈值后熔断
+	b.Allow()
+	b.Record(true)
+	if b.State() != BreakerOpen {
+		t.Fatalf("state = %s, want open", b.State())
+	}
+	if b.Allow() || !b.Open() {
+		t.Fatal("open breaker should reject requests")
+	}
+
+	// 等待后进入半开状态，只放行一个试探请求
+	time.Sleep(60 * time.Millisecond)
+	if !b.Allow() {
+		t.Fatal("half-open breaker should allow a probe")
+	}
+	if b.Allow() {
+		t.Fatal("half-open breaker should reject requests beyond probes")
+	}
+
+	// 试探失败重新熔断
+	b.Record(false)
+	if b.State() != BreakerOpen {
+		t.Fatalf("state = %s, want open", b.State())
+	}
+
+	// 试探成功后恢复
+	time.Sleep(60 * time.Millisecond)
+	b.Allow()
+	b.Record(true)
+	if b.State() != BreakerClosed {
+		t.Fatalf("state = %s, want closed", b.State())
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("transitions[%d] = %s, want %s", i, transitions[i], want[i])
+		}
+	}
+
+	stat := b.Stat()
+	if stat.Trips != 2 || stat.Rejected != 2 || stat.StateTxt != "closed" {
+		t.Errorf("stat = %+v", stat)
+	}
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	stderrors "errors"
 	"io"
 	"net"
 	"net/http"
@@ -82,6 +83,23 @@ func DoWithClient(ctx context.Context, hclient *http.Client, req *http.Request,
 
 	var lastErr error
 
+	// 按网盘熔断，上游持续出错时直接失败，不再等待重试和超时
+	// 一次调用无论重试几次只计为熔断器的一个样本，返回时按最后一次尝试的结果记录
+	var breaker *Breaker
+	var admitted, success, skipped bool
+	if config.CircuitBreakerEnabled() {
+		breaker = getBreaker(breakerName(ctx, req.URL.Host))
+		defer func() {
+			switch {
+			case !admitted:
+			case skipped:
+				breaker.skip()
+			default:
+				breaker.Record(success)
+			}
+		}()
+	}
+
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
 			// 计算退避时间
@@ -95,8 +113,28 @@ func DoWithClient(ctx context.Context, hclient *http.Client, req *http.Request,
 			}
 		}
 
+		// 熔断期间不占用限速令牌
+		if breaker != nil && breaker.Open() {
+			return nil, errors.NewUnavailableError("网盘暂不可用")
+		}
+
+		// 按主域名限速，重试同样需要等待令牌
+		if err := Wait(ctx, req.URL.Host); err != nil {
+			return nil, err
+		}
+
+		if breaker != nil && !admitted {
+			if !breaker.Allow() {
+				return nil, errors.NewUnavailableError("网盘暂不可用")
+			}
+			admitted = true
+		}
+
 		// 发送请求
 		resp, err := hclient.Do(req.WithContext(ctx))
+		// 调用方主动取消的请求不计入统计
+		skipped = err != nil && stderrors.Is(ctx.Err(), context.Canceled)
+		success = err == nil && (resp.StatusCode < 500 || resp.StatusCode >= 600)
 		if err == nil {
 			// 检查响应状态码 (5xx 通常可以重试)
 			if resp.StatusCode >= 500 && resp.StatusCode < 600 {
@@ -105,6 +143,12 @@ func DoWithClient(ctx context.Context, hclient *http.Client, req *http.Request,
 				lastErr = errors.NewResponseErrorWithStatus("服务器错误", resp.StatusCode, nil)
 				continue
 			}
+			// 429 表示请求过于频繁，重试只会加重限流
+			if resp.StatusCode == http.StatusTooManyRequests {
+				logger.Warn("请求过于频繁 %d: %s", resp.StatusCode, req.URL.Host)
+				CloseResponse(resp)
+				return nil, errors.NewRateLimitError("请求过于频繁", resp.StatusCode)
+			}
 			return resp, nil
 		}
 
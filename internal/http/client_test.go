@@ -0,0 +1,43 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"share-sniffer/internal/config"
+)
+
+func TestDoWithClientRecordsOnce(t *testing.T) {
+	cfg := config.GetConfig()
+	oldInterval := cfg.CheckConfig.RetryInterval
+	cfg.CheckConfig.RetryInterval = 10 * time.Millisecond
+	defer func() { cfg.CheckConfig.RetryInterval = oldInterval }()
+
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ctx := WithProvider(context.Background(), "test-record-once")
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DoWithClient(ctx, srv.Client(), req, 2); err == nil {
+		t.Fatal("DoWithClient() error = nil, want error")
+	}
+
+	if got := atomic.LoadInt32(&hits); got != 3 {
+		t.Errorf("server hits = %d, want 3", got)
+	}
+	stat := getBreaker("test-record-once").Stat()
+	if stat.Requests != 1 || stat.Failures != 1 {
+		t.Errorf("breaker stat = %d requests %d failures, want 1 and 1", stat.Requests, stat.Failures)
+	}
+}
@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+	"share-sniffer/internal/config"
+	"share-sniffer/internal/logger"
+)
+
+var (
+	// buckets 按主域名划分的令牌桶
+	buckets   = make(map[string]*bucket)
+	bucketsMu sync.Mutex
+)
+
+// LimiterStat 限速统计
+type LimiterStat struct {
+	Domain   string        // 主域名
+	RPS      float64       // 每秒请求数
+	Burst    int           // 突发请求数
+	Requests int64         // 请求次数
+	Delayed  int64         // 因令牌不足而等待的次数
+	Waited   time.Duration // 累计等待时长（含随机抖动）
+}
+
+// bucket 令牌桶，按配置的速率补充令牌，令牌不足时等待
+type bucket struct {
+	mu     sync.Mutex
+	limit  config.RateLimit
+	tokens float64
+	last   time.Time
+	stat   LimiterStat
+}
+
+// newBucket 创建装满令牌的令牌桶
+func newBucket(domain string, limit config.RateLimit) *bucket {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &bucket{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+		last:   time.Now(),
+		stat:   LimiterStat{Domain: domain, RPS: limit.RPS, Burst: limit.Burst},
+	}
+}
+
+// reserve 取出一个令牌，返回需要等待的时长
+func (b *bucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stat.Requests++
+	if b.limit.RPS <= 0 {
+		return 0
+	}
+
+	now := time.Now()
+	b.tokens = min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.limit.RPS)
+	b.last = now
+	b.tokens--
+
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.limit.RPS * float64(time.Second))
+		b.stat.Delayed++
+	}
+	if b.limit.Jitter > 0 {
+		delay += rand.N(b.limit.Jitter)
+	}
+	b.stat.Waited += delay
+	return delay
+}
+
+// cancel 归还一个未使用的令牌
+func (b *bucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(float64(b.limit.Burst), b.tokens+1)
+}
+
+// Wait 在向指定主机发送请求前等待令牌，限速关闭时立即返回
+//
+// 参数:
+// - ctx: 上下文，取消时停止等待并返回错误
+// - host: 请求的主机，可以带端口
+func Wait(ctx context.Context, host string) error {
+	if !config.RateLimitEnabled() {
+		return nil
+	}
+
+	domain := baseDomain(host)
+	b := getBucket(domain)
+	delay := b.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	logger.Debug("限速等待 %v: %s", delay, domain)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Stats 获取各主域名的限速统计，按主域名排序
+func Stats() []LimiterStat {
+	bucketsMu.Lock()
+	defer bucketsMu.Unlock()
+
+	stats := make([]LimiterStat, 0, len(buckets))
+	for _, b := range buckets {
+		b.mu.Lock()
+		stats = append(stats, b.stat)
+		b.mu.Unlock()
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Domain < stats[j].Domain
+	})
+	return stats
+}
+
+// ResetStats 清空限速统计，令牌桶状态保持不变
+func ResetStats() {
+	bucketsMu.Lock()
+	defer bucketsMu.Unlock()
+
+	for domain, b := range buckets {
+		b.mu.Lock()
+		b.stat = LimiterStat{Domain: domain, RPS: b.limit.RPS, Burst: b.limit.Burst}
+		b.mu.Unlock()
+	}
+}
+
+// getBucket 获取主域名对应的令牌桶，不存在时按配置创建
+func getBucket(domain string) *bucket {
+	bucketsMu.Lock()
+	defer bucketsMu.Unlock()
+
+	b, ok := buckets[domain]
+	if !ok {
+		b = newBucket(domain, config.GetRateLimit(domain))
+		buckets[domain] = b
+	}
+	return b
+}
+
+// baseDomain 返回主机的主域名，如 drive-h.quark.cn 返回 quark.cn
+// 同一网盘的页面和接口通常使用不同的子域名，按主域名限速才能覆盖全部请求
+func baseDomain(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	// 按公共后缀列表取主域名，mega.co.nz 和 *.com.cn 不会与其他网盘共用一个令牌桶
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return domain
+	}
+	return host
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"testing"
+	"time"
+
+	"share-sniffer/internal/config"
+)
+
+func TestBaseDomain(t *testing.T) {
+	tests := map[string]string{
+		"drive-h.quark.cn":  "quark.cn",
+		"pan.baidu.com:443": "baidu.com",
+		"115cdn.com":        "115cdn.com",
+		"WWW.123684.COM":    "123684.com",
+		"127.0.0.1:8080":    "127.0.0.1",
+		"mega.co.nz":        "mega.co.nz",
+		"g.api.mega.co.nz":  "mega.co.nz",
+		"www.abc.com.cn":    "abc.com.cn",
+		"cdn.xyz.com.cn":    "xyz.com.cn",
+		"localhost":         "localhost",
+	}
+	for host, want := range tests {
+		if got := baseDomain(host); got != want {
+			t.Errorf("baseDomain(%q) = %s, want %s", host, got, want)
+		}
+	}
+}
+
+func TestBucketReserve(t *testing.T) {
+	b := newBucket("example.com", config.RateLimit{RPS: 2, Burst: 2})
+
+	// 突发范围内的请求不需要等待
+	for i := 0; i < 2; i++ {
+		if delay := b.reserve(); delay != 0 {
+			t.Fatalf("reserve() #%d delay = %v, want 0", i, delay)
+		}
+	}
+
+	// 令牌用完后按速率等待，约0.5秒
+	delay := b.reserve()
+	if delay < 400*time.Millisecond || delay > 500*time.Millisecond {
+		t.Errorf("reserve() delay = %v, want about 500ms", delay)
+	}
+	if b.stat.Requests != 3 || b.stat.Delayed != 1 {
+		t.Errorf("stat = %+v, want 3 requests and 1 delayed", b.stat)
+	}
+
+	unlimited := newBucket("example.org", config.RateLimit{})
+	for i := 0; i < 10; i++ {
+		if delay := unlimited.reserve(); delay != 0 {
+			t.Fatalf("reserve() 不限速时 delay = %v, want 0", delay)
+		}
+	}
+}
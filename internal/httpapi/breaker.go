@@ -0,0 +1,97 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"share-sniffer/internal/config"
+	apphttp "share-sniffer/internal/http"
+	"share-sniffer/internal/parser"
+	"share-sniffer/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// providerBreakers keeps one circuit breaker per provider. Every check runs in a
+// fresh CLI process, so the breakers inside the CLI never see enough requests to
+// trip; the server tracks the outcomes across requests instead.
+type providerBreakers struct {
+	mu       sync.Mutex
+	breakers map[string]*apphttp.Breaker
+}
+
+// breakerFor returns the breaker of the provider the url belongs to, or nil when the
+// url is not a supported share link or circuit breaking is disabled.
+func (s *Server) breakerFor(rawURL string) (string, *apphttp.Breaker) {
+	if !config.CircuitBreakerEnabled() {
+		return "", nil
+	}
+	link, ok := parser.ParseLine(rawURL)
+	if !ok {
+		return "", nil
+	}
+
+	s.breakers.mu.Lock()
+	defer s.breakers.mu.Unlock()
+	if s.breakers.breakers == nil {
+		s.breakers.breakers = make(map[string]*apphttp.Breaker)
+	}
+	b, ok := s.breakers.breakers[link.Provider]
+	if !ok {
+		b = apphttp.NewBreaker(link.Provider, config.GetCircuitBreaker())
+		b.OnStateChange = func(name string, from, to apphttp.BreakerState) {
+			s.logger.Warn("Circuit breaker state changed",
+				zap.String("provider", name),
+				zap.String("from", from.String()),
+				zap.String("to", to.String()),
+			)
+		}
+		s.breakers.breakers[link.Provider] = b
+	}
+	return link.Provider, b
+}
+
+// unavailableResult builds the result returned while a provider's breaker is open
+func unavailableResult(rawURL, provider string) utils.Result {
+	result := utils.ErrorUnavailable("网盘暂不可用")
+	result.Schema = utils.SchemaVersion
+	result.Data.URL = rawURL
+	result.Data.Provider = provider
+	result.Data.CheckedAt = time.Now().UnixMilli()
+	return result
+}
+
+// checkSucceeded reports whether the CLI output counts as a healthy upstream
+// response. Invalid links, missing passwords and the like are answers from the
+// provider; only timeouts and request failures count against the breaker.
+func checkSucceeded(output []byte) bool {
+	var result struct {
+		Error utils.ErrorType `json:"error"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return false
+	}
+	switch result.Error {
+	case utils.Timeout, utils.Fatal, utils.Unavailable:
+		return false
+	}
+	return true
+}
+
+func (s *Server) breakersHandler(c *gin.Context) {
+	s.breakers.mu.Lock()
+	stats := make([]apphttp.BreakerStat, 0, len(s.breakers.breakers))
+	for _, b := range s.breakers.breakers {
+		stats = append(stats, b.Stat())
+	}
+	s.breakers.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	c.JSON(http.StatusOK, gin.H{"enabled": config.CircuitBreakerEnabled(), "breakers": stats})
+}
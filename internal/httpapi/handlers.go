@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,7 +16,20 @@ import (
 )
 
 type CheckRequest struct {
-	URL string `json:"url" binding:"required"`
+	URL     string `json:"url" binding:"required"`
+	NoCache bool   `json:"no_cache"` // bypass the CLI result cache
+}
+
+type BatchRequest struct {
+	URLs    []string `json:"urls" binding:"required"`
+	NoCache bool     `json:"no_cache"` // bypass the CLI result cache
+}
+
+type ListRequest struct {
+	URL    string `json:"url" binding:"required"`
+	Depth  int    `json:"depth"`
+	Max    int    `json:"max"`
+	Format string `json:"format"` // json (default) or text
 }
 
 // execCommandHelper executes the CLI command and returns the output
@@ -95,14 +109,31 @@ func (s *Server) checkHandler(c *gin.Context) {
 		return
 	}
 
+	// Fail fast while the provider's circuit breaker is open
+	provider, breaker := s.breakerFor(req.URL)
+	if breaker != nil && !breaker.Allow() {
+		c.JSON(http.StatusOK, unavailableResult(req.URL, provider))
+		return
+	}
+
 	// Prepare command
 	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Server.Timeout.Duration())
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, s.cfg.Server.ExecPath, req.URL)
+	// "--" ends flag parsing so a URL starting with "-" can't be read as a CLI flag
+	var args []string
+	if req.NoCache {
+		args = append(args, "--no-cache")
+	}
+	args = append(args, "--", req.URL)
+
+	cmd := exec.CommandContext(ctx, s.cfg.Server.ExecPath, args...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
+	if breaker != nil {
+		defer func() { breaker.Record(checkSucceeded(stdout.Bytes())) }()
+	}
 
 	s.logger.Info("Executing command",
 		zap.String("path", s.cfg.Server.ExecPath),
@@ -140,3 +171,116 @@ func (s *Server) checkHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+func (s *Server) listHandler(c *gin.Context) {
+	var req ListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Format != "" && req.Format != "json" && req.Format != "text" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or text"})
+		return
+	}
+
+	args := []string{"list"}
+	if req.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(req.Depth))
+	}
+	if req.Max > 0 {
+		args = append(args, "--max", strconv.Itoa(req.Max))
+	}
+	if req.Format != "" {
+		args = append(args, "--format", req.Format)
+	}
+	args = append(args, "--", req.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Server.Timeout.Duration())
+	defer cancel()
+
+	output, err := s.execCommandHelper(ctx, args...)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "command timed out"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "command failed", "details": err.Error()})
+		return
+	}
+
+	// The CLI prints JSON on failure even in text format
+	var result json.RawMessage
+	if err := json.Unmarshal([]byte(output), &result); err == nil {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+	if req.Format == "text" {
+		c.String(http.StatusOK, output)
+		return
+	}
+
+	s.logger.Error("Failed to parse CLI output", zap.String("output", output))
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid output from cli", "output": output})
+}
+
+func (s *Server) batchHandler(c *gin.Context) {
+	var req BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Server.Timeout.Duration())
+	defer cancel()
+
+	// The CLI prints one result per non-empty entry and checks each share once
+	args := []string{"batch", "-"}
+	if req.NoCache {
+		args = append(args, "--no-cache")
+	}
+
+	cmd := exec.CommandContext(ctx, s.cfg.Server.ExecPath, args...)
+	// One line per entry, so each result's index is the entry's 1-based position in urls
+	lines := make([]string, len(req.URLs))
+	for i, u := range req.URLs {
+		lines[i] = strings.Join(strings.Fields(u), " ")
+	}
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n"))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	s.logger.Info("Executing command",
+		zap.String("path", s.cfg.Server.ExecPath),
+		zap.Int("urls", len(req.URLs)),
+	)
+
+	if err := cmd.Run(); err != nil {
+		s.logger.Error("Command execution failed",
+			zap.Error(err),
+			zap.String("stderr", stderr.String()),
+		)
+		if ctx.Err() == context.DeadlineExceeded {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "command timed out"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "command failed", "details": stderr.String()})
+		return
+	}
+
+	// The CLI prints one JSON result per line
+	results := []json.RawMessage{}
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var result json.RawMessage
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			s.logger.Error("Failed to parse CLI output", zap.String("output", line))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid output from cli", "output": line})
+			return
+		}
+		results = append(results, result)
+	}
+	c.JSON(http.StatusOK, results)
+}
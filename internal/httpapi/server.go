@@ -13,6 +13,8 @@ type Server struct {
 	cfg    *httpconfig.Config
 	router *gin.Engine
 	logger *zap.Logger
+
+	breakers providerBreakers
 }
 
 func NewServer(cfg *httpconfig.Config) *Server {
@@ -42,6 +44,9 @@ func NewServer(cfg *httpconfig.Config) *Server {
 	
 	// API endpoints
 	r.POST("/api/check", s.checkHandler)
+	r.POST("/api/batch", s.batchHandler)
+	r.POST("/api/list", s.listHandler)
+	r.GET("/api/breakers", s.breakersHandler)
 	r.GET("/api/version", s.versionHandler)
 	r.GET("/api/home", s.homeHandler)
 	r.GET("/api/support", s.supportHandler)
@@ -0,0 +1,52 @@
+// Package parser Copyright 2025 Share Sniffer
+//
+// link.go 提供了链接解析、主机规范化和前缀匹配，分享文本解析和检查器路由共用同一套规则
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseLink 解析链接，缺少协议时默认补全为 https://，只接受带主机的 http 和 https 链接
+func ParseLink(s string) (*url.URL, error) {
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return nil, fmt.Errorf("不支持的协议: %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("缺少主机")
+	}
+	return u, nil
+}
+
+// NormalizeHost 规范化主机：小写、去掉端口和 www. 前缀
+func NormalizeHost(host string) string {
+	host = strings.ToLower(host)
+	if h, _, found := strings.Cut(host, ":"); found {
+		host = h
+	}
+	return strings.TrimPrefix(host, "www.")
+}
+
+// MatchHost 判断规范化后的主机是否匹配前缀中的主机
+// 前缀主机以 *. 开头时匹配该域名本身及其所有子域名，如 *.lanzoux.com 匹配 wwi.lanzoux.com
+func MatchHost(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return host == suffix || strings.HasSuffix(host, "."+suffix)
+	}
+	return pattern == host
+}
+
+// MatchPrefix 判断链接是否匹配前缀，前缀的主机已经过 NormalizeHost 规范化
+func MatchPrefix(host, path string, u *url.URL) bool {
+	return MatchHost(host, NormalizeHost(u.Host)) && strings.HasPrefix(u.Path, path)
+}
@@ -0,0 +1,33 @@
+package parser
+
+import "testing"
+
+func TestMatchPrefix(t *testing.T) {
+	tests := []struct {
+		host, path string
+		link       string
+		want       bool
+	}{
+		{"pan.quark.cn", "/s/", "https://pan.quark.cn/s/abc", true},
+		{"pan.quark.cn", "/s/", "HTTP://WWW.Pan.Quark.cn:443/s/abc", true},
+		{"pan.quark.cn", "/s/", "pan.quark.cn/list", false},
+		{"*.lanzoux.com", "/", "https://wwi.lanzoux.com/iAbC", true},
+		{"*.lanzoux.com", "/", "https://lanzoux.com/iAbC", true},
+		{"*.lanzoux.com", "/", "https://evil-lanzoux.com/iAbC", false},
+	}
+	for _, tt := range tests {
+		u, err := ParseLink(tt.link)
+		if err != nil {
+			t.Fatalf("ParseLink(%q) error = %v", tt.link, err)
+		}
+		if got := MatchPrefix(tt.host, tt.path, u); got != tt.want {
+			t.Errorf("MatchPrefix(%q, %q, %q) = %v, want %v", tt.host, tt.path, tt.link, got, tt.want)
+		}
+	}
+
+	for _, link := range []string{"thunder://QUFodHRw", "ftp://pan.quark.cn/s/abc", "https:///s/abc"} {
+		if _, err := ParseLink(link); err == nil {
+			t.Errorf("ParseLink(%q) error = nil, want error", link)
+		}
+	}
+}
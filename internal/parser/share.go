@@ -0,0 +1,311 @@
+// Package parser Copyright 2025 Share Sniffer
+//
+// share.go 实现了分享文本解析器，从用户粘贴的任意文本中提取受支持的分享链接，
+// 并把附近的提取码/访问码/密码与链接关联，输出规范化的(链接, 提取码)对
+package parser
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"share-sniffer/internal/config"
+)
+
+// passwordWindow 在链接前后查找提取码的最大字符数
+const passwordWindow = 80
+
+// ShareLink 从文本中解析出的分享链接
+type ShareLink struct {
+	URL      string `json:"url"`      // 规范化后的链接（已附带提取码）
+	Password string `json:"password"` // 提取码，没有时为空
+	Provider string `json:"provider"` // 网盘标识，对应config.Providers的键
+	Raw      string `json:"raw"`      // 文本中的原始链接
+}
+
+var (
+	// candidateRegex 匹配文本中疑似链接的片段，遇到空白和中文标点即结束
+	candidateRegex = regexp.MustCompile(`(?i)(?:https?://)?(?:[a-z0-9-]+\.)+[a-z]{2,}(?::\d+)?/[^\s<>"'“”‘’，。；！？、：（）()【】「」《》]*`)
+
+	// downloadLinkRegex 匹配迅雷、快车、旋风、电驴链接和磁力链接，这些链接由检测时解码或识别
+	downloadLinkRegex = regexp.MustCompile(`(?i)(?:(?:thunder|flashget|qqdl|ed2k)://|magnet:\?)\S+`)
+
+	// passwordRegex 匹配提取码关键字及其后的提取码
+	passwordRegex = regexp.MustCompile(`(?i)(?:提取码|访问码|提取密码|密码|口令|passcode|password|pwd)\s*[:：=]?\s*([a-z0-9]{4,8})(?:[^a-z0-9]|$)`)
+
+	// encodedSuffixes 链接后紧跟的被百分号编码的中英文括号，如 %EF%BC%88访问码：xxxx%EF%BC%89
+	encodedSuffixes = []string{"%EF%BC%88", "%ef%bc%88", "%28"}
+
+	// urlPasswordKeys 链接中已携带提取码的参数名，城通网盘使用 p
+	urlPasswordKeys = []string{"pwd", "password", "passcode", "p"}
+
+	// passwordParams 各网盘在链接中携带提取码的参数名，未列出的使用pwd，空字符串表示不附带
+	// Mega 的解密密钥在链接片段中，没有提取码
+	passwordParams = map[string]string{
+		"yyw":    "password",
+		"ctfile": "p",
+		"mega":   "",
+	}
+)
+
+// prefix 受支持的链接前缀
+type prefix struct {
+	provider string
+	host     string
+	path     string
+}
+
+// candidate 文本中的链接候选
+type candidate struct {
+	start, end int    // 在文本中的位置
+	raw        string // 链接文本（已去掉编码后缀）
+	suffix     string // 被编码在链接中的后缀文本（已解码）
+}
+
+// Parse 从任意文本中提取所有受支持的分享链接
+//
+// 参数:
+// - text: 用户粘贴的文本，可以包含多行、多个链接和说明文字
+//
+// 返回值:
+// - []ShareLink: 按出现顺序排列的分享链接
+func Parse(text string) []ShareLink {
+	prefixes := supportedPrefixes()
+	cands := findCandidates(text)
+	// consumed 记录链接之间的文本片段是否已被前一个链接使用
+	consumed := make([]bool, len(cands)+1)
+
+	var links []ShareLink
+	for i, c := range cands {
+		provider, ok := matchProvider(c.raw, prefixes)
+		if !ok {
+			continue
+		}
+
+		password := PasswordFromURL(c.raw)
+		if password == "" {
+			password = findPassword(c.suffix)
+		}
+		if password == "" {
+			nextStart := len(text)
+			if i+1 < len(cands) {
+				nextStart = cands[i+1].start
+			}
+			if password = findPassword(headRunes(text[c.end:nextStart], passwordWindow)); password != "" {
+				consumed[i+1] = true
+			}
+		}
+		if password == "" && !consumed[i] {
+			prevEnd := 0
+			if i > 0 {
+				prevEnd = cands[i-1].end
+			}
+			if password = findPassword(tailRunes(text[prevEnd:c.start], passwordWindow)); password != "" {
+				consumed[i] = true
+			}
+		}
+
+		links = append(links, ShareLink{
+			URL:      WithPassword(c.raw, provider, password),
+			Password: password,
+			Provider: provider,
+			Raw:      c.raw,
+		})
+	}
+	return links
+}
+
+// ParseInputs 从文本中提取待检测的链接
+// 受支持的分享链接按 Parse 解析，不含受支持链接但含有其他链接、迅雷等下载工具链接或磁力链接的行整行保留，
+// 由检测时展开短链接、解码下载工具链接，这些行的 Provider 为空
+//
+// 参数:
+// - text: 用户粘贴或文件中的文本，可以包含多行、多个链接和说明文字
+//
+// 返回值:
+// - []ShareLink: 按出现顺序排列的待检测链接
+func ParseInputs(text string) []ShareLink {
+	links := Parse(text)
+	var inputs []ShareLink
+	for _, line := range strings.Split(text, "\n") {
+		// 分享链接不跨行，逐行解析得到的数量与整段解析中该行的链接数一致
+		if n := len(Parse(line)); n > 0 {
+			inputs = append(inputs, links[:n]...)
+			links = links[n:]
+			continue
+		}
+		raw := downloadLinkRegex.FindString(line)
+		if raw == "" {
+			raw = FirstURL(line)
+		}
+		if raw != "" {
+			inputs = append(inputs, ShareLink{URL: strings.TrimSpace(line), Raw: raw})
+		}
+	}
+	return inputs
+}
+
+// ParseLine 解析单行文本，返回其中的第一个分享链接
+func ParseLine(text string) (ShareLink, bool) {
+	links := Parse(text)
+	if len(links) == 0 {
+		return ShareLink{}, false
+	}
+	return links[0], true
+}
+
+// FirstURL 返回文本中的第一个链接，不要求链接受支持，没有链接时返回空字符串
+// 用于在展开短链接前从分享文本中取出链接
+func FirstURL(text string) string {
+	if cands := findCandidates(text); len(cands) > 0 {
+		return cands[0].raw
+	}
+	return ""
+}
+
+// PasswordFromText 在文本中查找提取码，链接中已携带的提取码优先
+func PasswordFromText(text string) string {
+	if raw := FirstURL(text); raw != "" {
+		if password := PasswordFromURL(raw); password != "" {
+			return password
+		}
+	}
+	return findPassword(text)
+}
+
+// supportedPrefixes 获取当前启用的所有链接前缀，按路径长度降序排列
+func supportedPrefixes() []prefix {
+	enabled := make(map[string]bool)
+	for _, p := range config.GetSupportedLinks() {
+		enabled[p] = true
+	}
+
+	var prefixes []prefix
+	for provider, list := range config.GetConfig().SupportedLinkTypes.Providers {
+		for _, p := range list {
+			if !enabled[p] {
+				continue
+			}
+			u, err := ParseLink(p)
+			if err != nil {
+				continue
+			}
+			prefixes = append(prefixes, prefix{provider: provider, host: NormalizeHost(u.Host), path: u.Path})
+		}
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		if len(prefixes[i].path) != len(prefixes[j].path) {
+			return len(prefixes[i].path) > len(prefixes[j].path)
+		}
+		return prefixes[i].provider < prefixes[j].provider
+	})
+	return prefixes
+}
+
+// findCandidates 查找文本中的所有链接候选
+func findCandidates(text string) []candidate {
+	var cands []candidate
+	for _, loc := range candidateRegex.FindAllStringIndex(text, -1) {
+		raw := strings.TrimRight(text[loc[0]:loc[1]], ".,;:!?")
+		c := candidate{start: loc[0], end: loc[0] + len(raw), raw: raw}
+		for _, s := range encodedSuffixes {
+			if idx := strings.Index(c.raw, s); idx > 0 {
+				if decoded, err := url.PathUnescape(c.raw[idx:]); err == nil {
+					c.suffix = decoded
+				}
+				c.raw = c.raw[:idx]
+				break
+			}
+		}
+		cands = append(cands, c)
+	}
+	return cands
+}
+
+// matchProvider 判断链接是否受支持，返回对应的网盘标识
+func matchProvider(raw string, prefixes []prefix) (string, bool) {
+	u, err := ParseLink(raw)
+	if err != nil {
+		return "", false
+	}
+	for _, p := range prefixes {
+		if MatchPrefix(p.host, p.path, u) {
+			return p.provider, true
+		}
+	}
+	return "", false
+}
+
+// findPassword 在文本片段中查找提取码
+func findPassword(text string) string {
+	if m := passwordRegex.FindStringSubmatch(text); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// PasswordFromURL 读取链接中已携带的提取码，包括115的 #?password= 形式
+func PasswordFromURL(raw string) string {
+	u, err := ParseLink(raw)
+	if err != nil {
+		return ""
+	}
+	queries := []url.Values{u.Query()}
+	if fragment := strings.TrimPrefix(u.Fragment, "?"); fragment != "" {
+		if values, err := url.ParseQuery(fragment); err == nil {
+			queries = append(queries, values)
+		}
+	}
+	for _, q := range queries {
+		for _, key := range urlPasswordKeys {
+			if v := q.Get(key); v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// WithPassword 按网盘约定把提取码附加到链接上，链接已携带提取码时保持不变
+func WithPassword(raw, provider, password string) string {
+	if password == "" || PasswordFromURL(raw) != "" {
+		return raw
+	}
+	param, ok := passwordParams[provider]
+	if !ok {
+		param = "pwd"
+	}
+	if param == "" {
+		return raw
+	}
+
+	base, fragment, _ := strings.Cut(raw, "#")
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+	}
+	result := base + sep + param + "=" + url.QueryEscape(password)
+	if fragment != "" {
+		result += "#" + fragment
+	}
+	return result
+}
+
+// headRunes 返回文本的前n个字符
+func headRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) > n {
+		r = r[:n]
+	}
+	return string(r)
+}
+
+// tailRunes 返回文本的后n个字符
+func tailRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) > n {
+		r = r[len(r)-n:]
+	}
+	return string(r)
+}
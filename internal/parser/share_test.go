@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []ShareLink
+	}{
+		{
+			name: "baidu with code",
+			text: "链接: https://pan.baidu.com/s/1abc 提取码: x1y2 --来自百度网盘超级会员的分享",
+			want: []ShareLink{
+				{URL: "https://pan.baidu.com/s/1abc?pwd=x1y2", Password: "x1y2", Provider: "baidu"},
+			},
+		},
+		{
+			name: "telecom chinese suffix",
+			text: "https://cloud.189.cn/t/6FjeIfQvMRba（访问码：2jio）",
+			want: []ShareLink{
+				{URL: "https://cloud.189.cn/t/6FjeIfQvMRba?pwd=2jio", Password: "2jio", Provider: "telecom"},
+			},
+		},
+		{
+			name: "telecom encoded suffix",
+			text: "https://cloud.189.cn/web/share?code=7BfYRjRZvYBz%EF%BC%88%E8%AE%BF%E9%97%AE%E7%A0%81%EF%BC%9Ac0jt%EF%BC%89",
+			want: []ShareLink{
+				{URL: "https://cloud.189.cn/web/share?code=7BfYRjRZvYBz&pwd=c0jt", Password: "c0jt", Provider: "telecom"},
+			},
+		},
+		{
+			name: "password in url",
+			text: "夸克：https://pan.quark.cn/s/45c6cd59a7f9?pwd=D3eM。",
+			want: []ShareLink{
+				{URL: "https://pan.quark.cn/s/45c6cd59a7f9?pwd=D3eM", Password: "D3eM", Provider: "quark"},
+			},
+		},
+		{
+			name: "115 password param",
+			text: "115cdn.com/s/swabc123 访问码：a1b2",
+			want: []ShareLink{
+				{URL: "115cdn.com/s/swabc123?password=a1b2", Password: "a1b2", Provider: "yyw"},
+			},
+		},
+		{
+			name: "lanzou custom subdomain",
+			text: "https://user1.lanzouw.com/iAbC123 密码:ab12",
+			want: []ShareLink{
+				{URL: "https://user1.lanzouw.com/iAbC123?pwd=ab12", Password: "ab12", Provider: "lanzou"},
+			},
+		},
+		{
+			name: "ctfile password param",
+			text: "城通：https://url65.ctfile.com/f/1001-2001-aaa 访问密码：1234",
+			want: []ShareLink{
+				{URL: "https://url65.ctfile.com/f/1001-2001-aaa?p=1234", Password: "1234", Provider: "ctfile"},
+			},
+		},
+		{
+			name: "mega key in fragment",
+			text: "Mega：https://mega.nz/#!AbCdEfGh!k3yK3yK3y-_k3y 密码：1234",
+			want: []ShareLink{
+				{URL: "https://mega.nz/#!AbCdEfGh!k3yK3yK3y-_k3y", Password: "1234", Provider: "mega"},
+			},
+		},
+		{
+			name: "multiple links and lines",
+			text: "1. https://pan.quark.cn/s/0592e1dbe475\n提取码：abcd\n2. https://www.alipan.com/s/Xd4HxfpMdVk\nhttps://example.com/s/ignored 密码: zzzz",
+			want: []ShareLink{
+				{URL: "https://pan.quark.cn/s/0592e1dbe475?pwd=abcd", Password: "abcd", Provider: "quark"},
+				{URL: "https://www.alipan.com/s/Xd4HxfpMdVk", Password: "", Provider: "alipan"},
+			},
+		},
+		{
+			name: "password before link",
+			text: "提取码: 9n54 链接: https://pan.baidu.com/s/1wj6Y-RquDLEUUTLHTWnjAQ",
+			want: []ShareLink{
+				{URL: "https://pan.baidu.com/s/1wj6Y-RquDLEUUTLHTWnjAQ?pwd=9n54", Password: "9n54", Provider: "baidu"},
+			},
+		},
+		{
+			name: "no links",
+			text: "这里没有链接 提取码: abcd",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse() 得到 %d 个链接，预期 %d 个: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i].URL != tt.want[i].URL {
+					t.Errorf("Parse()[%d].URL = %s, want %s", i, got[i].URL, tt.want[i].URL)
+				}
+				if got[i].Password != tt.want[i].Password {
+					t.Errorf("Parse()[%d].Password = %s, want %s", i, got[i].Password, tt.want[i].Password)
+				}
+				if got[i].Provider != tt.want[i].Provider {
+					t.Errorf("Parse()[%d].Provider = %s, want %s", i, got[i].Provider, tt.want[i].Provider)
+				}
+			}
+		})
+	}
+}
+
+func TestFirstURL(t *testing.T) {
+	tests := []struct {
+		text         string
+		wantURL      string
+		wantPassword string
+	}{
+		{"链接: https://t.cn/A6abcdEF 提取码: x1y2", "https://t.cn/A6abcdEF", "x1y2"},
+		{"bit.ly/3AbCdEf?pwd=ab12。", "bit.ly/3AbCdEf?pwd=ab12", "ab12"},
+		{"这里没有链接 提取码: abcd", "", "abcd"},
+	}
+	for _, tt := range tests {
+		if got := FirstURL(tt.text); got != tt.wantURL {
+			t.Errorf("FirstURL(%q) = %q, want %q", tt.text, got, tt.wantURL)
+		}
+		if got := PasswordFromText(tt.text); got != tt.wantPassword {
+			t.Errorf("PasswordFromText(%q) = %q, want %q", tt.text, got, tt.wantPassword)
+		}
+	}
+}
+
+func TestParseInputs(t *testing.T) {
+	text := "资源合集\n" +
+		"https://pan.quark.cn/s/0592e1dbe475\n" +
+		"短链接: https://t.cn/A6abcdEF 提取码: x1y2\n" +
+		"百度 https://pan.baidu.com/s/1abc\n" +
+		"提取码: ab12\n" +
+		"https://example.com/readme\n" +
+		"迅雷下载 thunder://QUFodHRwOi8vZXhhbXBsZS5jb20vYS56aXBaWg==\n" +
+		"magnet:?xt=urn:btih:c9e15763f722f23e98a29decdfae341b98d53056\n" +
+		"ed2k://|file|a.iso|1024|A2B4C6D8E0F2A4B6C8D0E2F4A6B8C0D2|/\n"
+	want := []string{
+		"https://pan.quark.cn/s/0592e1dbe475",
+		"短链接: https://t.cn/A6abcdEF 提取码: x1y2",
+		"https://pan.baidu.com/s/1abc?pwd=ab12",
+		"https://example.com/readme",
+		"迅雷下载 thunder://QUFodHRwOi8vZXhhbXBsZS5jb20vYS56aXBaWg==",
+		"magnet:?xt=urn:btih:c9e15763f722f23e98a29decdfae341b98d53056",
+		"ed2k://|file|a.iso|1024|A2B4C6D8E0F2A4B6C8D0E2F4A6B8C0D2|/",
+	}
+
+	got := ParseInputs(text)
+	if len(got) != len(want) {
+		t.Fatalf("ParseInputs() = %+v, want %d links", got, len(want))
+	}
+	for i, link := range got {
+		if link.URL != want[i] {
+			t.Errorf("ParseInputs()[%d].URL = %q, want %q", i, link.URL, want[i])
+		}
+	}
+	if got[1].Provider != "" || got[1].Raw != "https://t.cn/A6abcdEF" {
+		t.Errorf("ParseInputs()[1] = %+v, want the short link kept without provider", got[1])
+	}
+}
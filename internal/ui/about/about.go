@@ -6,7 +6,6 @@ import (
 
 	"share-sniffer/internal/config"
 	"share-sniffer/internal/ui/icons"
-	"share-sniffer/internal/utils"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -128,25 +127,20 @@ func NewAboutTab(window fyne.Window) *container.TabItem {
 		{yywImage.Resource, "115网盘", fmt.Sprintf("%s*", config.GetSupportedYyw()), nil},
 		{yesImage.Resource, "123云盘", fmt.Sprintf("%s*", config.GetSupportedYes()), nil},
 		{ucImage.Resource, "UC网盘", fmt.Sprintf("%s*", config.GetSupportedUc()), nil},
+		{thunderImage.Resource, "迅雷云盘", fmt.Sprintf("%s*", config.GetSupportedXunlei()), nil},
+		{ydImage.Resource, "移动云盘", fmt.Sprintf("%s*", config.GetSupportedYd()), nil},
+		// 蓝奏云域名较多，只展示第一个前缀
+		{theme.StorageIcon(), "蓝奏云", fmt.Sprintf("[%s* 等]", lo.FirstOrEmpty(config.GetSupportedLanzou())), nil},
+		{theme.StorageIcon(), "腾讯微云", fmt.Sprintf("%s*", config.GetSupportedWeiyun()), nil},
+		{theme.StorageIcon(), "PikPak", fmt.Sprintf("%s*", config.GetSupportedPikPak()), nil},
+		{theme.StorageIcon(), "城通网盘", fmt.Sprintf("[%s* 等]", lo.FirstOrEmpty(config.GetSupportedCtfile())), nil},
+		{theme.StorageIcon(), "OneDrive", fmt.Sprintf("%s*", config.GetSupportedOneDrive()), nil},
+		{theme.StorageIcon(), "Google Drive", fmt.Sprintf("[%s* 等]", lo.FirstOrEmpty(config.GetSupportedGDrive())), nil},
+		{theme.StorageIcon(), "Dropbox", fmt.Sprintf("[%s* 等]", lo.FirstOrEmpty(config.GetSupportedDropbox())), nil},
+		{theme.StorageIcon(), "Mega", fmt.Sprintf("%s*", config.GetSupportedMega()), nil},
 		//{theme.QuestionIcon(), "TODO", "TODO", nil},
 	}
 
-	// 只有在非安卓平台才添加的功能
-	if utils.IsDesktop() {
-		features = append(features, struct {
-			icon  fyne.Resource
-			title string
-			desc  string
-			ico   fyne.Resource
-		}{thunderImage.Resource, "迅雷云盘", fmt.Sprintf("%s*", config.GetSupportedXunlei()), icons.LogoChrome})
-		features = append(features, struct {
-			icon  fyne.Resource
-			title string
-			desc  string
-			ico   fyne.Resource
-		}{ydImage.Resource, "移动云盘", fmt.Sprintf("%s*", config.GetSupportedYd()), icons.LogoChrome})
-	}
-
 	// 将features切片按每2个元素一组进行分组
 	groupedFeatures := lo.Chunk(features, 2)
 
@@ -40,6 +40,7 @@ type CheckUI struct {
 	// 表格数据，用于UI和检测结果共享
 	tableDataWrapper struct {
 		Data  [][]string
+		Meta  []*utils.Metadata // 每行的分享内容元数据，用于详情展示
 		Mutex sync.RWMutex
 	}
 	// UI组件
@@ -83,7 +84,7 @@ func (q *CheckUI) createTab() *container.TabItem {
 	// 初始化UI组件作为结构体字段
 	q.fileEntry = &EntryWithEnterKeyEvent{}
 	q.fileEntry.ExtendBaseWidget(q.fileEntry)
-	q.fileEntry.SetPlaceHolder("打开分享链接文本文件(.txt),每行一条分享链接（单次上限9999条）")
+	q.fileEntry.SetPlaceHolder("打开分享链接文本文件(.txt),支持链接与提取码混排的分享文本（单次上限9999条）")
 	q.fileOpenButton = &widget.Button{Text: "打开", OnTapped: q.OpenFile,
 		Icon: theme.FileIcon()}
 	q.fileCheckButton = &widget.Button{Text: "检测", OnTapped: q.CheckFile,
@@ -131,6 +132,31 @@ func (q *CheckUI) createTab() *container.TabItem {
 		container.NewPadded(content))
 }
 
+// showDetail 显示指定行的检测详情和分享内容元数据
+func (q *CheckUI) showDetail(row int) {
+	q.tableDataWrapper.Mutex.RLock()
+	if row < 0 || row >= len(q.tableDataWrapper.Data) {
+		q.tableDataWrapper.Mutex.RUnlock()
+		return
+	}
+	data := q.tableDataWrapper.Data[row]
+	var meta *utils.Metadata
+	if row < len(q.tableDataWrapper.Meta) {
+		meta = q.tableDataWrapper.Meta[row]
+	}
+	q.tableDataWrapper.Mutex.RUnlock()
+
+	text := fmt.Sprintf("网址: %s\n状态: %s\n信息: %s\n\n%s", data[1], data[2], data[4], meta.Detail())
+	label := widget.NewLabel(text)
+	label.Wrapping = fyne.TextWrapWord
+
+	scroll := container.NewVScroll(label)
+	scroll.SetMinSize(fyne.NewSize(460, 300))
+
+	dialog := fyneDialog.NewCustom("详情", "关闭", scroll, q.window)
+	dialog.Show()
+}
+
 // 创建空表格（不渲染表头）
 func createEmptyTable() *widget.Table {
 	// 返回一个空表格，不显示任何内容
@@ -1,9 +1,9 @@
 package check
 
 import (
-	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"strings"
@@ -15,10 +15,11 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
-	"github.com/samber/lo"
 	"share-sniffer/internal/config"
 	"share-sniffer/internal/core"
+	apphttp "share-sniffer/internal/http"
 	"share-sniffer/internal/logger"
+	"share-sniffer/internal/parser"
 	"share-sniffer/internal/utils"
 	"share-sniffer/internal/workerpool"
 )
@@ -174,10 +175,12 @@ func (q *CheckUI) createDataTable(tableData [][]string, mutex *sync.RWMutex) *co
 						if id.Col == 2 { // 状态列
 							if value == utils.ValidTxt {
 								label.Importance = widget.SuccessImportance // 绿色
-							} else if value == utils.TimeoutTxt || value == utils.MalformedTxt || value == utils.FatalTxt {
+							} else if value == utils.TimeoutTxt || value == utils.MalformedTxt || value == utils.FatalTxt || value == utils.UnavailableTxt {
 								label.Importance = widget.HighImportance // 红色高亮
-							} else if value == utils.InvalidTxt {
+							} else if value == utils.InvalidTxt || value == utils.BannedTxt {
 								label.Importance = widget.DangerImportance // 红色高亮
+							} else if value == utils.NeedPasswordTxt || value == utils.LoginRequiredTxt || value == utils.RateLimitedTxt {
+								label.Importance = widget.WarningImportance // 橙色
 							} else if value == utils.UnknownTxt {
 								label.Importance = widget.LowImportance // 红色高亮
 							} else if value == utils.DoingTxt {
@@ -192,6 +195,12 @@ func (q *CheckUI) createDataTable(tableData [][]string, mutex *sync.RWMutex) *co
 			}
 		})
 
+	// 点击行时显示详情，取消选中以便再次点击同一行
+	dataTable.OnSelected = func(id widget.TableCellID) {
+		q.showDetail(id.Row)
+		dataTable.Unselect(id)
+	}
+
 	// 调整数据表格列宽
 	dataTable.SetColumnWidth(0, 50)  // 序号列
 	dataTable.SetColumnWidth(1, 400) // 网址列
@@ -254,62 +263,54 @@ func (q *CheckUI) updateTableDisplay(headerContainer *fyne.Container, dataTableC
 	}
 }
 
-func supportedLinks(url string) bool {
-	return lo.ContainsBy(config.GetSupportedLinks(), func(prefix string) bool {
-		return strings.HasPrefix(url, prefix)
-	})
-}
+// maxFileSize 分享链接文件的最大读取字节数
+const maxFileSize = 16 * 1024 * 1024
 
-// loadToTable 加载文件并渲染表格
-func (q *CheckUI) loadToTable() {
-	logger.Debug("开始执行LoadToTable方法，文件路径: %s, 文件URI: %v", q.state.FilePath, q.state.FileURI)
-
-	startTime := time.Now()
-	defer logger.Debug("LoadToTable方法执行完毕，耗时: %v", time.Since(startTime))
-	// 读取文件内容
-	var links []string
-	var scanner *bufio.Scanner
+// readShareLinks 读取已打开的文件，并从文件内容中解析待检测的链接
+// 不受支持的链接所在的行整行保留，检测时展开短链接后再匹配检查器
+func (q *CheckUI) readShareLinks() ([]parser.ShareLink, error) {
+	var reader io.ReadCloser
 
 	// 根据平台选择不同的文件读取方式
 	if q.state.FileURI != nil {
 		// Android平台或支持URI的平台，使用storage包读取
-		reader, readErr := storage.Reader(q.state.FileURI)
-		if readErr != nil {
-			logger.Warn("打开文件失败: %v", readErr)
-			fyne.Do(func() {
-				q.dialogProvider.ShowError(fmt.Sprintf("打开文件失败:%v", readErr))
-			})
-			return
+		uriReader, err := storage.Reader(q.state.FileURI)
+		if err != nil {
+			return nil, err
 		}
-		defer reader.Close()
-		scanner = bufio.NewScanner(reader)
+		reader = uriReader
 	} else {
 		// 非Android平台，使用os.Open读取
-		file, openErr := os.Open(q.state.FilePath)
-		if openErr != nil {
-			logger.Warn("打开文件失败: %v", openErr)
-			fyne.Do(func() {
-				q.dialogProvider.ShowError(fmt.Sprintf("打开文件失败:%v", openErr))
-			})
-			return
+		file, err := os.Open(q.state.FilePath)
+		if err != nil {
+			return nil, err
 		}
-		defer file.Close()
-		scanner = bufio.NewScanner(file)
+		reader = file
 	}
+	defer reader.Close()
 
-	scanStart := time.Now()
-	logger.Debug("开始扫描文件内容")
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" && supportedLinks(line) {
-			links = append(links, line)
-		}
+	content, err := io.ReadAll(io.LimitReader(reader, maxFileSize))
+	if err != nil {
+		return nil, err
 	}
 
-	if err := scanner.Err(); err != nil {
-		logger.Warn("读取文件错误: %v", err)
+	return parser.ParseInputs(string(content)), nil
+}
+
+// loadToTable 加载文件并渲染表格
+func (q *CheckUI) loadToTable() {
+	logger.Debug("开始执行LoadToTable方法，文件路径: %s, 文件URI: %v", q.state.FilePath, q.state.FileURI)
+
+	startTime := time.Now()
+	defer logger.Debug("LoadToTable方法执行完毕，耗时: %v", time.Since(startTime))
+	// 读取文件内容并解析分享链接
+	scanStart := time.Now()
+	logger.Debug("开始扫描文件内容")
+	links, err := q.readShareLinks()
+	if err != nil {
+		logger.Warn("读取文件失败: %v", err)
 		fyne.Do(func() {
-			q.dialogProvider.ShowError(fmt.Sprintf("读取文件错误:%v", err))
+			q.dialogProvider.ShowError(fmt.Sprintf("读取文件失败:%v", err))
 		})
 		return
 	}
@@ -328,8 +329,16 @@ func (q *CheckUI) loadToTable() {
 	dataPrepareStart := time.Now()
 	logger.Debug("开始准备表格数据")
 	tableData := make([][]string, len(links))
+	urls := make([]string, len(links))
 	for i, link := range links {
-		tableData[i] = []string{"", link, "", "", ""} // 初始状态字段为空，序号会在渲染时自动生成
+		tableData[i] = []string{"", link.URL, "", "", ""} // 初始状态字段为空，序号会在渲染时自动生成
+		urls[i] = link.URL
+	}
+	// 标记同一分享的不同写法
+	for i, j := range core.FindDuplicates(urls) {
+		if j >= 0 {
+			tableData[i][4] = duplicateInfo(j)
+		}
 	}
 	logger.Debug("表格数据准备完成，耗时: %v", time.Since(dataPrepareStart))
 
@@ -337,6 +346,7 @@ func (q *CheckUI) loadToTable() {
 	logger.Debug("保存表格数据到实例的tableDataWrapper中")
 	q.tableDataWrapper.Mutex.Lock()
 	q.tableDataWrapper.Data = tableData
+	q.tableDataWrapper.Meta = make([]*utils.Metadata, len(tableData))
 	q.tableDataWrapper.Mutex.Unlock()
 
 	// 创建表头和数据表格
@@ -406,7 +416,6 @@ func (q *CheckUI) CheckFile() {
 	})
 
 	// 从文件中加载链接
-	var links []string
 	fileLoadStart := time.Now()
 	logger.Debug("开始从文件加载链接: %s, URI: %v", q.state.FilePath, q.state.FileURI)
 
@@ -423,61 +432,9 @@ func (q *CheckUI) CheckFile() {
 		return
 	}
 
-	// 根据平台选择不同的文件读取方式
-	var scanner *bufio.Scanner
-
-	if q.state.FileURI != nil {
-		// Android平台或支持URI的平台，使用storage包读取
-		reader, readErr := storage.Reader(q.state.FileURI)
-		if readErr != nil {
-			logger.Error("打开文件失败: %v", readErr)
-			fyne.Do(func() {
-				q.dialogProvider.ShowError("打开分享链接文件失败")
-				q.fileCheckButton.SetText("检测")
-				q.fileEntry.Enable()
-				q.fileOpenButton.Enable()
-			})
-			q.isChecking = false
-			return
-		}
-		defer reader.Close()
-		scanner = bufio.NewScanner(reader)
-	} else {
-		// 非Android平台，使用os.Open读取
-		file, openErr := os.Open(q.state.FilePath)
-		if openErr != nil {
-			logger.Error("打开文件失败: %v", openErr)
-			fyne.Do(func() {
-				q.dialogProvider.ShowError("打开分享链接文件失败")
-				q.fileCheckButton.SetText("检测")
-				q.fileEntry.Enable()
-				q.fileOpenButton.Enable()
-			})
-			q.isChecking = false
-			return
-		}
-		defer file.Close()
-		scanner = bufio.NewScanner(file)
-	}
-
-	// 优化大文件读取，支持最多9999个链接
-	linkCount := 0
-	maxLinks := 9999 // 限制最大处理链接数
-
-	// 增加scanner的缓冲区大小，优化大文件读取
-	scannerBuf := make([]byte, 64*1024)   // 64KB缓冲区
-	scanner.Buffer(scannerBuf, 1024*1024) // 最大行长度1MB
-
-	for scanner.Scan() && linkCount < maxLinks {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" && supportedLinks(line) {
-			links = append(links, line)
-			linkCount++
-		}
-	}
-
-	// 检查是否有错误或是否达到最大链接数
-	if err := scanner.Err(); err != nil {
+	// 从分享文本中解析链接和提取码，支持链接与提取码分行书写
+	shareLinks, err := q.readShareLinks()
+	if err != nil {
 		logger.Error("读取文件错误: %v", err)
 		fyne.Do(func() {
 			q.dialogProvider.ShowError(fmt.Sprintf("读取文件错误: %v", err))
@@ -489,12 +446,21 @@ func (q *CheckUI) CheckFile() {
 		return
 	}
 
+	// 支持最多9999个链接
+	maxLinks := 9999 // 限制最大处理链接数
+
 	// 如果文件中的链接超过最大限制，给用户提示
-	if linkCount >= maxLinks {
+	if len(shareLinks) > maxLinks {
 		logger.Warn("文件中链接数量超过最大限制 %d，仅处理前 %d 个链接", maxLinks, maxLinks)
 		fyne.Do(func() {
 			q.dialogProvider.ShowInfo(fmt.Sprintf("文件中链接数量超过最大限制 %d，仅处理前 %d 个链接", maxLinks, maxLinks), "提示")
 		})
+		shareLinks = shareLinks[:maxLinks]
+	}
+
+	links := make([]string, len(shareLinks))
+	for i, link := range shareLinks {
+		links[i] = link.URL
 	}
 
 	logger.Debug("文件加载完成，共读取 %d 个链接，耗时: %v", len(links), time.Since(fileLoadStart))
@@ -511,12 +477,27 @@ func (q *CheckUI) CheckFile() {
 		return
 	}
 
+	// 同一分享的不同写法只检测一次，检测结果同步到重复的行
+	dupOf := core.FindDuplicates(links)
+	followers := make(map[int][]int)
+	var n_duplicate int32
+	for i, j := range dupOf {
+		if j >= 0 {
+			followers[j] = append(followers[j], i)
+			n_duplicate++
+		}
+	}
+
 	// 初始化表格数据到实例的tableDataWrapper中
 	logger.Debug("开始初始化表格数据")
 	q.tableDataWrapper.Mutex.Lock()
 	q.tableDataWrapper.Data = make([][]string, len(links))
+	q.tableDataWrapper.Meta = make([]*utils.Metadata, len(links))
 	for i := 0; i < len(links); i++ {
 		q.tableDataWrapper.Data[i] = []string{fmt.Sprintf("%d", i+1), links[i], utils.DoingTxt, "", ""}
+		if dupOf[i] >= 0 {
+			q.tableDataWrapper.Data[i][4] = duplicateInfo(dupOf[i])
+		}
 	}
 	q.tableDataWrapper.Mutex.Unlock()
 	logger.Debug("表格数据初始化完成，共 %d 行数据", len(q.tableDataWrapper.Data))
@@ -534,6 +515,10 @@ func (q *CheckUI) CheckFile() {
 		logger.Debug("表格显示更新完成，耗时: %v", time.Since(tableUpdateStart))
 	})
 
+	// 每次检测单独统计限速和熔断情况
+	apphttp.ResetStats()
+	apphttp.ResetBreakerStats()
+
 	// 创建工作池并启动
 	logger.Debug("开始创建并启动工作池")
 	pool := workerpool.NewWorkerPool()
@@ -542,10 +527,15 @@ func (q *CheckUI) CheckFile() {
 
 	// 统计变量
 	var (
-		n_total   int32
-		n_valid   int32
-		n_invalid int32
-		n_error   int32
+		n_total    int32
+		n_valid    int32
+		n_invalid  int32
+		n_password int32
+		n_login    int32
+		n_limited  int32
+		n_banned   int32
+		n_unavail  int32
+		n_error    int32
 	)
 
 	// 提交所有任务到工作池，分批处理以优化性能和内存使用
@@ -572,6 +562,11 @@ func (q *CheckUI) CheckFile() {
 				// 继续提交
 			}
 
+			// 重复的链接不单独检测
+			if dupOf[i] >= 0 {
+				continue
+			}
+
 			index := i
 			url := links[i]
 
@@ -670,8 +665,8 @@ func (q *CheckUI) CheckFile() {
 						}
 					})
 
-					// 增加完成计数
-					atomic.AddInt32(&completedCount, 1)
+					// 增加完成计数，重复的行随之完成
+					atomic.AddInt32(&completedCount, int32(1+len(followers[idx])))
 				}(index)
 			}
 		}
@@ -749,6 +744,31 @@ stopSubmission:
 			// 计算总链接数
 			n_total = int32(len(links))
 
+			// 汇总限速统计
+			var delayed int64
+			var waited time.Duration
+			for _, stat := range apphttp.Stats() {
+				logger.Info("限速统计 %s: 速率=%.1f/s, 突发=%d, 请求=%d, 等待=%d次, 累计等待=%v",
+					stat.Domain, stat.RPS, stat.Burst, stat.Requests, stat.Delayed, stat.Waited)
+				delayed += stat.Delayed
+				waited += stat.Waited
+			}
+
+			// 汇总熔断统计，列出本次检测中熔断过的网盘
+			var tripped []string
+			for _, stat := range apphttp.BreakerStats() {
+				if stat.Trips == 0 && stat.State == apphttp.BreakerClosed {
+					continue
+				}
+				logger.Info("熔断统计 %s: 状态=%s, 请求=%d, 失败=%d, 熔断=%d次, 拒绝=%d",
+					stat.Name, stat.StateTxt, stat.Requests, stat.Failures, stat.Trips, stat.Rejected)
+				tripped = append(tripped, fmt.Sprintf("%s(%s)", stat.Name, stat.StateTxt))
+			}
+			breakerInfo := ""
+			if len(tripped) > 0 {
+				breakerInfo = "\n熔断网盘: " + strings.Join(tripped, ", ")
+			}
+
 			// 所有任务完成后，如果仍在检测中，恢复按钮状态
 			if q.isChecking {
 				logger.Debug("所有任务完成，恢复UI状态")
@@ -759,7 +779,8 @@ stopSubmission:
 					q.fileOpenButton.Enable()
 
 					// 显示统计数据
-					q.dialogProvider.ShowInfo(fmt.Sprintf("总数:%d, 有效:%d, 失效:%d, 其他:%d", n_total, n_valid, n_invalid, n_error), "提示")
+					q.dialogProvider.ShowInfo(fmt.Sprintf("总数:%d, 有效:%d, 失效:%d, 需提取码:%d, 需登录:%d, 受限:%d, 违规:%d, 不可用:%d, 其他:%d, 重复:%d\n限速等待:%d次, 累计%.1f秒%s",
+						n_total, n_valid, n_invalid, n_password, n_login, n_limited, n_banned, n_unavail, n_error, n_duplicate, delayed, waited.Seconds(), breakerInfo), "提示")
 
 					q.isChecking = false
 				})
@@ -845,36 +866,53 @@ stopSubmission:
 						q.tableDataWrapper.Data[index][2] = "已停止"
 						logger.Debug("任务 #%d 已停止", index+1)
 					} else {
-						statusText := utils.UnknownTxt
-						if checkResult.Error == utils.Valid {
-							statusText = utils.ValidTxt
+						statusText := utils.ErrorToTxt(checkResult.Error)
+						switch checkResult.Error {
+						case utils.Valid:
 							logger.Debug("任务 #%d 检测正常: %s", index+1, checkResult.Data.Name)
 							atomic.AddInt32(&n_valid, 1)
-						} else if checkResult.Error == utils.Invalid {
-							statusText = utils.InvalidTxt
+						case utils.Invalid:
 							logger.Debug("任务 #%d 检测失败", index+1)
 							atomic.AddInt32(&n_invalid, 1)
-						} else if checkResult.Error == utils.Malformed || checkResult.Error == utils.Timeout || checkResult.Error == utils.Fatal {
-							if checkResult.Error == utils.Malformed {
-								statusText = utils.MalformedTxt
-								logger.Debug("任务 #%d 检测参数错误", index+1)
-							} else if checkResult.Error == utils.Timeout {
-								statusText = utils.TimeoutTxt
-								logger.Debug("任务 #%d 检测超时", index+1)
-							} else if checkResult.Error == utils.Fatal {
-								statusText = utils.FatalTxt
-								logger.Debug("任务 #%d 检测异常", index+1)
-							}
+						case utils.NeedPassword:
+							logger.Debug("任务 #%d 需要提取码", index+1)
+							atomic.AddInt32(&n_password, 1)
+						case utils.LoginRequired:
+							logger.Debug("任务 #%d 需要登录", index+1)
+							atomic.AddInt32(&n_login, 1)
+						case utils.RateLimited:
+							logger.Debug("任务 #%d 请求受限", index+1)
+							atomic.AddInt32(&n_limited, 1)
+						case utils.Banned:
+							logger.Debug("任务 #%d 分享违规", index+1)
+							atomic.AddInt32(&n_banned, 1)
+						case utils.Unavailable:
+							logger.Debug("任务 #%d 网盘暂不可用", index+1)
+							atomic.AddInt32(&n_unavail, 1)
+						default:
+							logger.Debug("任务 #%d 检测%s", index+1, statusText)
 							atomic.AddInt32(&n_error, 1)
 						}
 						q.tableDataWrapper.Data[index][2] = statusText
 						q.tableDataWrapper.Data[index][3] = fmt.Sprintf("%d", checkResult.Data.Elapsed)
+						q.tableDataWrapper.Meta[index] = checkResult.Data.Meta
 						if checkResult.Error == utils.Valid {
 							q.tableDataWrapper.Data[index][4] = checkResult.Data.Name
+							// 附带文件数量和大小，便于区分空分享
+							if summary := checkResult.Data.Meta.Summary(); summary != "" {
+								q.tableDataWrapper.Data[index][4] += " [" + summary + "]"
+							}
 						} else {
 							q.tableDataWrapper.Data[index][4] = checkResult.Msg
 						}
 
+						// 同步到重复的行
+						for _, row := range followers[index] {
+							q.tableDataWrapper.Data[row][2] = statusText
+							q.tableDataWrapper.Data[row][3] = q.tableDataWrapper.Data[index][3]
+							q.tableDataWrapper.Data[row][4] = duplicateInfo(index) + " " + q.tableDataWrapper.Data[index][4]
+							q.tableDataWrapper.Meta[row] = checkResult.Data.Meta
+						}
 					}
 				}
 				q.tableDataWrapper.Mutex.Unlock()
@@ -896,8 +934,8 @@ stopSubmission:
 					}
 				})
 
-				// 增加完成计数（线程安全）
-				completed := atomic.AddInt32(&completedCount, 1)
+				// 增加完成计数（线程安全），重复的行随之完成
+				completed := atomic.AddInt32(&completedCount, int32(1+len(followers[index])))
 				logger.Debug("任务 #%d 处理完成，进度: %d/%d", index+1, completed, totalTasks)
 
 				// 轻量级限速，避免请求过快
@@ -933,3 +971,8 @@ stopSubmission:
 		logger.Debug("CheckFile方法defer执行")
 	}()
 }
+
+// duplicateInfo 重复行的提示信息，first为第一次出现的行下标
+func duplicateInfo(first int) string {
+	return fmt.Sprintf("[与第%d行重复]", first+1)
+}
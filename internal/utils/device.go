@@ -5,8 +5,10 @@ import (
 	"runtime"
 )
 
+// IsDesktop 是否运行在桌面环境，用于选择界面交互方式
+// 依赖浏览器的检查器是否可用由 browser.Available 判断，与此无关
 func IsDesktop() bool {
-	//docker build,  0 表示屏蔽依赖chromedp的类型
+	// 0 表示非桌面环境
 	shield := os.Getenv("IS_DESKTOP")
 	if shield == "0" {
 		return false
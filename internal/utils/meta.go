@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MetaMaxEntries 元数据中保留的顶层文件条目上限
+const MetaMaxEntries = 20
+
+// Metadata 分享内容元数据
+// 由各网盘检查器在接口返回相关信息时填充，未知的字段保持零值
+//
+// 字段:
+// - FileCount: 顶层文件（含文件夹）数量
+// - TotalSize: 文件总大小（字节）
+// - Entries: 顶层文件预览，最多 MetaMaxEntries 条
+// - ExpiresAt: 分享过期时间（毫秒时间戳），0 表示永久有效或未知
+// - Creator: 分享者昵称
+type Metadata struct {
+	FileCount int         `json:"file_count"`           // 顶层文件数量
+	TotalSize int64       `json:"total_size"`           // 总大小（字节）
+	Entries   []MetaEntry `json:"entries,omitempty"`    // 顶层文件预览
+	ExpiresAt int64       `json:"expires_at,omitempty"` // 过期时间（毫秒时间戳）
+	Creator   string      `json:"creator,omitempty"`    // 分享者
+}
+
+// MetaEntry 分享中的一个文件或文件夹
+type MetaEntry struct {
+	Name  string `json:"name"`   // 文件名
+	Size  int64  `json:"size"`   // 大小（字节），文件夹为0
+	IsDir bool   `json:"is_dir"` // 是否为文件夹
+}
+
+// AddEntry 添加一条顶层文件预览，超过上限时忽略
+func (m *Metadata) AddEntry(name string, size int64, isDir bool) {
+	if len(m.Entries) >= MetaMaxEntries {
+		return
+	}
+	m.Entries = append(m.Entries, MetaEntry{Name: name, Size: size, IsDir: isDir})
+}
+
+// IsEmpty 判断分享是否为空（有效但没有任何文件）
+func (m *Metadata) IsEmpty() bool {
+	return m != nil && m.FileCount == 0
+}
+
+// Summary 返回元数据的简短描述，如 "3个文件 1.2 GB"
+func (m *Metadata) Summary() string {
+	if m == nil {
+		return ""
+	}
+	if m.FileCount == 0 {
+		return "空分享"
+	}
+	if m.TotalSize > 0 {
+		return fmt.Sprintf("%d个文件 %s", m.FileCount, FormatSize(m.TotalSize))
+	}
+	return fmt.Sprintf("%d个文件", m.FileCount)
+}
+
+// Detail 返回元数据的多行描述，用于界面的详情展示
+func (m *Metadata) Detail() string {
+	if m == nil {
+		return "暂无分享内容信息"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "文件数量: %d\n", m.FileCount)
+	if m.TotalSize > 0 {
+		fmt.Fprintf(&b, "总大小: %s\n", FormatSize(m.TotalSize))
+	}
+	if m.Creator != "" {
+		fmt.Fprintf(&b, "分享者: %s\n", m.Creator)
+	}
+	if m.ExpiresAt > 0 {
+		fmt.Fprintf(&b, "过期时间: %s\n", time.UnixMilli(m.ExpiresAt).Format("2006-01-02 15:04:05"))
+	} else {
+		b.WriteString("过期时间: 永久或未知\n")
+	}
+	for _, e := range m.Entries {
+		if e.IsDir {
+			fmt.Fprintf(&b, "[目录] %s\n", e.Name)
+		} else {
+			fmt.Fprintf(&b, "%s (%s)\n", e.Name, FormatSize(e.Size))
+		}
+	}
+	if m.FileCount > len(m.Entries) && len(m.Entries) > 0 {
+		fmt.Fprintf(&b, "... 共%d项\n", m.FileCount)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// WithMeta 返回附带分享内容元数据的检测结果
+func (q Result) WithMeta(meta *Metadata) Result {
+	q.Data.Meta = meta
+	return q
+}
+
+// FormatSize 把字节数格式化为易读的大小，如 1.5 MB
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit && exp < 4; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTP"[exp])
+}
@@ -0,0 +1,112 @@
+package utils
+
+// SchemaVersion 检测结果的结构版本，结果字段发生不兼容变化时递增
+const SchemaVersion = 2
+
+// 原因码，供集成方稳定判断检测结果的具体原因，不随界面文案变化
+const (
+	// ReasonOK 链接有效
+	ReasonOK = "ok"
+
+	// ReasonShareInvalid 分享失效，无法判断具体原因
+	ReasonShareInvalid = "share_invalid"
+
+	// ReasonShareExpired 分享已过期
+	ReasonShareExpired = "share_expired"
+
+	// ReasonShareNotFound 分享不存在
+	ReasonShareNotFound = "share_not_found"
+
+	// ReasonShareCancelled 分享已被取消或删除
+	ReasonShareCancelled = "share_cancelled"
+
+	// ReasonEmptyShare 分享中没有文件
+	ReasonEmptyShare = "empty_share"
+
+	// ReasonPasscodeRequired 需要提取码
+	ReasonPasscodeRequired = "passcode_required"
+
+	// ReasonWrongPasscode 提取码错误
+	ReasonWrongPasscode = "wrong_passcode"
+
+	// ReasonKeyMissing 链接缺少解密密钥，如不带 #密钥 的 Mega 链接
+	ReasonKeyMissing = "key_missing"
+
+	// ReasonLoginRequired 需要登录
+	ReasonLoginRequired = "login_required"
+
+	// ReasonRateLimited 请求过于频繁或需要验证码
+	ReasonRateLimited = "rate_limited"
+
+	// ReasonBanned 分享因违规被屏蔽
+	ReasonBanned = "banned"
+
+	// ReasonMalformedURL 链接格式无效
+	ReasonMalformedURL = "malformed_url"
+
+	// ReasonUnsupported 链接尚未支持
+	ReasonUnsupported = "unsupported"
+
+	// ReasonNotShareLink 不是网盘分享链接，如磁力链接和电驴链接
+	ReasonNotShareLink = "not_share_link"
+
+	// ReasonTimeout 请求超时
+	ReasonTimeout = "timeout"
+
+	// ReasonUpstream5xx 上游服务返回5xx错误
+	ReasonUpstream5xx = "upstream_5xx"
+
+	// ReasonUpstreamStatus 上游服务返回非预期的状态码
+	ReasonUpstreamStatus = "upstream_status"
+
+	// ReasonParseError 上游响应解析失败
+	ReasonParseError = "parse_error"
+
+	// ReasonRequestError 请求过程中出错
+	ReasonRequestError = "request_error"
+
+	// ReasonProviderUnavailable 网盘接口连续出错已被熔断，本次未实际检测
+	ReasonProviderUnavailable = "provider_unavailable"
+
+	// ReasonPageError 页面内容无法识别（浏览器检测）
+	ReasonPageError = "page_error"
+
+	// ReasonUnknown 未知原因
+	ReasonUnknown = "unknown"
+)
+
+// DefaultReason 错误码对应的默认原因码
+func DefaultReason(error ErrorType) string {
+	reason := ""
+	switch error {
+	case Valid:
+		reason = ReasonOK
+	case Invalid:
+		reason = ReasonShareInvalid
+	case Malformed:
+		reason = ReasonMalformedURL
+	case Timeout:
+		reason = ReasonTimeout
+	case Fatal:
+		reason = ReasonRequestError
+	case NeedPassword:
+		reason = ReasonPasscodeRequired
+	case LoginRequired:
+		reason = ReasonLoginRequired
+	case RateLimited:
+		reason = ReasonRateLimited
+	case Banned:
+		reason = ReasonBanned
+	case Unavailable:
+		reason = ReasonProviderUnavailable
+	default:
+		reason = ReasonUnknown
+	}
+	return reason
+}
+
+// WithReason 返回设置了原因码的检测结果
+func (q Result) WithReason(reason string) Result {
+	q.Reason = reason
+	return q
+}
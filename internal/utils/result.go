@@ -8,22 +8,45 @@ const MsgMaxLen int = 48
 // 包含URL检查的完整信息
 //
 // 字段:
+// - Schema: 结果结构版本
 // - Error: 错误码
+// - Reason: 原因码，稳定的机器可读值，见 reason.go
 // - Msg: 错误信息
 // - Data.URL: 被检测的URL字符串
 // - Data.Name: 资源名称（如果检测成功）
 // - Data.Elapsed: 检测耗时（毫秒）
+// - Data.Provider: 网盘标识，对应config.Providers的键
+// - Data.ShareID: 规范化后的分享ID
+// - Data.CheckedAt: 检测时间（毫秒时间戳）
+// - Data.Meta: 分享内容元数据（可选），见 meta.go
+// - Data.Cached: 结果是否来自缓存，命中时CheckedAt为实际检测的时间
+// - Data.CanonicalURL: 规范链接，同一分享的不同写法规范化后相同
+// - Data.ResolvedURL: 短链接、跳转链接展开或迅雷等专用链接解码后的链接，Data.URL 保留原始输入
+// - Data.Index: 批量检测时在输入中的行号（从1开始），单个检测时为0
+// - Data.DuplicateOf: 批量检测时与哪一行的链接重复，值为该行的Index，0表示不重复
 
 type Result struct {
-	Error ErrorType  `json:"error"` // 错误码
-	Msg   string     `json:"msg"`
-	Data  ResultData `json:"data"`
+	Schema int        `json:"schema"` // 结果结构版本
+	Error  ErrorType  `json:"error"`  // 错误码
+	Reason string     `json:"reason"` // 原因码
+	Msg    string     `json:"msg"`
+	Data   ResultData `json:"data"`
 }
 
 type ResultData struct {
-	URL     string `json:"url"`     // 检测的URL
-	Name    string `json:"name"`    // 资源名称
-	Elapsed int64  `json:"elapsed"` // 耗时（毫秒）
+	URL       string    `json:"url"`            // 检测的URL
+	Name      string    `json:"name"`           // 资源名称
+	Elapsed   int64     `json:"elapsed"`        // 耗时（毫秒）
+	Provider  string    `json:"provider"`       // 网盘标识
+	ShareID   string    `json:"share_id"`       // 分享ID
+	CheckedAt int64     `json:"checked_at"`     // 检测时间（毫秒时间戳）
+	Meta      *Metadata `json:"meta,omitempty"` // 分享内容元数据
+	Cached    bool      `json:"cached"`         // 是否来自缓存
+
+	CanonicalURL string `json:"canonical_url,omitempty"` // 规范链接
+	ResolvedURL  string `json:"resolved_url,omitempty"`  // 短链接展开或专用链接解码后的链接，没有展开时为空
+	Index        int    `json:"index,omitempty"`         // 批量检测时在输入中的行号（从1开始）
+	DuplicateOf  int    `json:"duplicate_of,omitempty"`  // 与哪一行的链接重复，值为该行的Index
 }
 
 const (
@@ -50,6 +73,21 @@ const (
 
 	// Done 完成 (任务池)
 	Done = 16
+
+	// NeedPassword 需要提取码或提取码错误，分享本身可能仍然有效
+	NeedPassword = 17
+
+	// LoginRequired 需要登录才能访问
+	LoginRequired = 18
+
+	// RateLimited 请求过于频繁或需要验证码
+	RateLimited = 19
+
+	// Banned 分享因违规被屏蔽
+	Banned = 20
+
+	// Unavailable 网盘接口连续出错已被熔断，暂不可用，未实际检测
+	Unavailable = 21
 )
 
 const (
@@ -76,6 +114,21 @@ const (
 
 	// DoingTxt  GUI
 	DoingTxt = "检测中"
+
+	// NeedPasswordTxt 需要提取码
+	NeedPasswordTxt = "需提取码"
+
+	// LoginRequiredTxt 需要登录
+	LoginRequiredTxt = "需登录"
+
+	// RateLimitedTxt 请求受限
+	RateLimitedTxt = "受限"
+
+	// BannedTxt 违规屏蔽
+	BannedTxt = "违规"
+
+	// UnavailableTxt 网盘暂不可用（熔断）
+	UnavailableTxt = "不可用"
 )
 
 func ErrorToMsg(error ErrorType) string {
@@ -91,16 +144,59 @@ func ErrorToMsg(error ErrorType) string {
 		msg = "malformed"
 	case Timeout:
 		msg = "timeout"
+	case NeedPassword:
+		msg = "need password"
+	case LoginRequired:
+		msg = "login required"
+	case RateLimited:
+		msg = "rate limited"
+	case Banned:
+		msg = "banned"
+	case Unavailable:
+		msg = "provider unavailable"
 	default:
 		msg = "self defined"
 	}
 	return msg
 }
 
+// ErrorToTxt 错误码对应的界面显示文本
+func ErrorToTxt(error ErrorType) string {
+	txt := ""
+	switch error {
+	case Valid:
+		txt = ValidTxt
+	case Invalid:
+		txt = InvalidTxt
+	case Malformed:
+		txt = MalformedTxt
+	case Timeout:
+		txt = TimeoutTxt
+	case Fatal:
+		txt = FatalTxt
+	case Stop, Done:
+		txt = StopTxt
+	case NeedPassword:
+		txt = NeedPasswordTxt
+	case LoginRequired:
+		txt = LoginRequiredTxt
+	case RateLimited:
+		txt = RateLimitedTxt
+	case Banned:
+		txt = BannedTxt
+	case Unavailable:
+		txt = UnavailableTxt
+	default:
+		txt = UnknownTxt
+	}
+	return txt
+}
+
 // ErrorMalformed 参数错误
 func ErrorMalformed(url string, msg string) Result {
 	return Result{
-		Error: Malformed,
+		Error:  Malformed,
+		Reason: ReasonMalformedURL,
 		Msg: func() string {
 			if msg == "" {
 				return ErrorToMsg(Malformed)
@@ -117,8 +213,9 @@ func ErrorMalformed(url string, msg string) Result {
 
 func ErrorTimeout() Result {
 	return Result{
-		Error: Timeout,
-		Msg:   ErrorToMsg(Timeout),
+		Error:  Timeout,
+		Reason: ReasonTimeout,
+		Msg:    ErrorToMsg(Timeout),
 		Data: ResultData{
 			URL:     "",
 			Name:    "",
@@ -129,7 +226,8 @@ func ErrorTimeout() Result {
 
 func ErrorUnknown(msg string) Result {
 	return Result{
-		Error: Unknown,
+		Error:  Unknown,
+		Reason: ReasonUnknown,
 		Msg: func() string {
 			if msg == "" {
 				return ErrorToMsg(Unknown)
@@ -146,8 +244,9 @@ func ErrorUnknown(msg string) Result {
 
 func ErrorValid(name string) Result {
 	return Result{
-		Error: Valid,
-		Msg:   ErrorToMsg(Valid),
+		Error:  Valid,
+		Reason: ReasonOK,
+		Msg:    ErrorToMsg(Valid),
 		Data: ResultData{
 			URL:     "",
 			Name:    name,
@@ -158,7 +257,8 @@ func ErrorValid(name string) Result {
 
 func ErrorInvalid(msg string) Result {
 	return Result{
-		Error: Invalid,
+		Error:  Invalid,
+		Reason: ReasonShareInvalid,
 		Msg: func() string {
 			if msg == "" {
 				return ErrorToMsg(Invalid)
@@ -175,7 +275,8 @@ func ErrorInvalid(msg string) Result {
 
 func ErrorFatal(msg string) Result {
 	return Result{
-		Error: Fatal,
+		Error:  Fatal,
+		Reason: ReasonRequestError,
 		Msg: func() string {
 			if msg == "" {
 				return ErrorToMsg(Unknown)
@@ -189,3 +290,47 @@ func ErrorFatal(msg string) Result {
 		},
 	}
 }
+
+// errorWithMsg 创建带信息的检测结果，信息为空时使用错误码的默认描述
+func errorWithMsg(error ErrorType, msg string) Result {
+	return Result{
+		Error:  error,
+		Reason: DefaultReason(error),
+		Msg: func() string {
+			if msg == "" {
+				return ErrorToMsg(error)
+			}
+			return Substr(msg, MsgMaxLen, "")
+		}(),
+		Data: ResultData{
+			URL:     "",
+			Name:    "",
+			Elapsed: 0,
+		},
+	}
+}
+
+// ErrorNeedPassword 需要提取码或提取码错误
+func ErrorNeedPassword(msg string) Result {
+	return errorWithMsg(NeedPassword, msg)
+}
+
+// ErrorLoginRequired 需要登录才能访问
+func ErrorLoginRequired(msg string) Result {
+	return errorWithMsg(LoginRequired, msg)
+}
+
+// ErrorRateLimited 请求过于频繁或需要验证码
+func ErrorRateLimited(msg string) Result {
+	return errorWithMsg(RateLimited, msg)
+}
+
+// ErrorBanned 分享因违规被屏蔽
+func ErrorBanned(msg string) Result {
+	return errorWithMsg(Banned, msg)
+}
+
+// ErrorUnavailable 网盘已被熔断，暂不可用
+func ErrorUnavailable(msg string) Result {
+	return errorWithMsg(Unavailable, msg)
+}
@@ -2,7 +2,6 @@ package workerpool
 
 import (
 	"context"
-	"strings"
 	"sync"
 	"time"
 
@@ -31,9 +30,6 @@ type WorkerPool struct {
 	wg         sync.WaitGroup
 	ctx        context.Context
 	cancel     context.CancelFunc
-	// 长耗时任务并发控制
-	longTaskSemaphore chan struct{}
-	maxLongConcurrent int
 }
 
 // NewWorkerPool 创建新的工作池
@@ -45,19 +41,14 @@ func NewWorkerPool() *WorkerPool {
 	queueSize := 10000     // 足够处理最多9999个链接
 	resultQueueSize := 100 // 结果通道保持合理容量
 
-	// 长耗时任务并发控制
-	maxLongConcurrent := config.GetLongMaxConcurrent()
-
 	ctx, cancel := context.WithCancel(context.Background())
 	pool := &WorkerPool{
-		workers:           workers,
-		queueSize:         queueSize,
-		taskQueue:         make(chan Task, queueSize),
-		resultChan:        make(chan Result, resultQueueSize),
-		ctx:               ctx,
-		cancel:            cancel,
-		longTaskSemaphore: make(chan struct{}, maxLongConcurrent),
-		maxLongConcurrent: maxLongConcurrent,
+		workers:    workers,
+		queueSize:  queueSize,
+		taskQueue:  make(chan Task, queueSize),
+		resultChan: make(chan Result, resultQueueSize),
+		ctx:        ctx,
+		cancel:     cancel,
 	}
 
 	return pool
@@ -94,59 +85,19 @@ func (q *WorkerPool) worker(id int) {
 
 			logger.Debug("工作协程 %d 收到任务: %s", id, task.URL)
 
-			// 判断是否为长耗时任务（迅雷和139云盘链接）
-			isLongTask := false
-			for _, prefix := range config.GetSupportedXunlei() {
-				if strings.HasPrefix(task.URL, prefix) {
-					isLongTask = true
-					break
-				}
-			}
-			// 检查是否为139云盘链接
-			if !isLongTask {
-				for _, prefix := range config.GetSupportedYd() {
-					if strings.HasPrefix(task.URL, prefix) {
-						isLongTask = true
-						break
-					}
-				}
-			}
-
 			// 为每个任务创建带超时的context，直接使用工作池上下文，不创建新的超时
+			// 依赖浏览器的检测由浏览器池限制并发，这里不再单独限制
 			taskCtx := q.ctx
-			if isLongTask {
-				// 使用信号量控制长耗时任务并发数
-				logger.Debug("尝试获取长耗时任务并发信号量，当前信号量队列长度: %d/%d", len(q.longTaskSemaphore), cap(q.longTaskSemaphore))
-
-				// 等待获取信号量，这里会阻塞直到有可用资源
-				q.longTaskSemaphore <- struct{}{}
-				logger.Debug("成功获取长耗时任务并发信号量，当前信号量队列长度: %d/%d", len(q.longTaskSemaphore), cap(q.longTaskSemaphore))
-
-				// 执行任务
-				result := q.executeTask(taskCtx, task)
-
-				// 释放信号量
-				<-q.longTaskSemaphore
-				logger.Debug("释放长耗时任务并发信号量，当前信号量队列长度: %d/%d", len(q.longTaskSemaphore), cap(q.longTaskSemaphore))
-
-				// 发送结果，即使上下文已取消也要尝试发送
-				select {
-				case q.resultChan <- result:
-					logger.Debug("工作协程 %d 任务结果已发送", id)
-				case <-q.ctx.Done():
-					logger.Debug("工作协程 %d 任务结果丢弃（工作池已关闭）", id)
-				}
-			} else {
-				// 执行任务
-				result := q.executeTask(taskCtx, task)
-
-				// 发送结果，即使上下文已取消也要尝试发送
-				select {
-				case q.resultChan <- result:
-					logger.Debug("工作协程 %d 任务结果已发送", id)
-				case <-q.ctx.Done():
-					logger.Debug("工作协程 %d 任务结果丢弃（工作池已关闭）", id)
-				}
+
+			// 执行任务
+			result := q.executeTask(taskCtx, task)
+
+			// 发送结果，即使上下文已取消也要尝试发送
+			select {
+			case q.resultChan <- result:
+				logger.Debug("工作协程 %d 任务结果已发送", id)
+			case <-q.ctx.Done():
+				logger.Debug("工作协程 %d 任务结果丢弃（工作池已关闭）", id)
 			}
 		}
 	}